@@ -0,0 +1,98 @@
+package auctioneer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	NetworkTCP  = "tcp"
+	NetworkUnix = "unix"
+
+	// SchemeUnix prefixes listen addresses and auctioneer URLs that refer to
+	// a unix socket, as in unix:///var/vcap/data/auctioneer/auctioneer.sock.
+	SchemeUnix = "unix"
+
+	// unixSocketHost is the host put in the URL of requests sent over a unix
+	// socket. A server certificate used on a unix socket must be valid for
+	// it.
+	unixSocketHost = "localhost"
+)
+
+// ListenAddress is where the auctioneer serves its API: either a TCP host
+// and port or the path of a unix socket.
+type ListenAddress struct {
+	Network string
+	Address string
+	Host    string
+	Port    int
+}
+
+// ParseListenAddress parses host:port addresses, including bracketed IPv6
+// literals such as [::1]:9016, and unix:///path/to/socket addresses.
+func ParseListenAddress(address string) (ListenAddress, error) {
+	if path, ok := unixSocketPath(address); ok {
+		if path == "" {
+			return ListenAddress{}, errors.New("unix socket path cannot be blank")
+		}
+		return ListenAddress{Network: NetworkUnix, Address: path}, nil
+	}
+
+	host, portString, err := net.SplitHostPort(address)
+	if err != nil {
+		return ListenAddress{}, err
+	}
+
+	port, err := strconv.Atoi(portString)
+	if err != nil || port < 0 || port > 65535 {
+		return ListenAddress{}, fmt.Errorf("invalid port %q", portString)
+	}
+
+	return ListenAddress{Network: NetworkTCP, Address: address, Host: host, Port: port}, nil
+}
+
+func (a ListenAddress) IsUnix() bool {
+	return a.Network == NetworkUnix
+}
+
+// URL returns the address clients on the given host should use to reach an
+// auctioneer listening on this address with the given scheme. Clients of a
+// unix socket are always on the same host, so host is ignored for them.
+func (a ListenAddress) URL(scheme, host string) string {
+	if a.IsUnix() {
+		return SchemeUnix + "://" + a.Address
+	}
+	return scheme + "://" + net.JoinHostPort(host, strconv.Itoa(a.Port))
+}
+
+func unixSocketPath(address string) (string, bool) {
+	prefix := SchemeUnix + "://"
+	if !strings.HasPrefix(address, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(address, prefix), true
+}
+
+// clientURL returns the base URL requests to the auctioneer at auctioneerURL
+// are made against. If auctioneerURL refers to a unix socket, the client's
+// transport is changed to connect to it.
+func clientURL(client *http.Client, auctioneerURL, scheme string) string {
+	path, ok := unixSocketPath(auctioneerURL)
+	if !ok {
+		return auctioneerURL
+	}
+
+	if transport, ok := client.Transport.(*http.Transport); ok {
+		dialer := &net.Dialer{}
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, NetworkUnix, path)
+		}
+	}
+
+	return scheme + "://" + unixSocketHost
+}
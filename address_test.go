@@ -0,0 +1,75 @@
+package auctioneer_test
+
+import (
+	"code.cloudfoundry.org/auctioneer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ListenAddress", func() {
+	Describe("ParseListenAddress", func() {
+		It("parses an IPv4 host and port", func() {
+			address, err := auctioneer.ParseListenAddress("0.0.0.0:9016")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(address).To(Equal(auctioneer.ListenAddress{Network: "tcp", Address: "0.0.0.0:9016", Host: "0.0.0.0", Port: 9016}))
+			Expect(address.IsUnix()).To(BeFalse())
+		})
+
+		It("parses an IPv6 literal and port", func() {
+			address, err := auctioneer.ParseListenAddress("[::1]:9016")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(address).To(Equal(auctioneer.ListenAddress{Network: "tcp", Address: "[::1]:9016", Host: "::1", Port: 9016}))
+		})
+
+		It("parses a unix socket address", func() {
+			address, err := auctioneer.ParseListenAddress("unix:///var/vcap/data/auctioneer/auctioneer.sock")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(address).To(Equal(auctioneer.ListenAddress{Network: "unix", Address: "/var/vcap/data/auctioneer/auctioneer.sock"}))
+			Expect(address.IsUnix()).To(BeTrue())
+		})
+
+		It("rejects a unix socket address without a path", func() {
+			_, err := auctioneer.ParseListenAddress("unix://")
+			Expect(err).To(MatchError(ContainSubstring("unix socket path")))
+		})
+
+		It("rejects an address without a port", func() {
+			_, err := auctioneer.ParseListenAddress("0.0.0.0")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("rejects an unbracketed IPv6 literal", func() {
+			_, err := auctioneer.ParseListenAddress("::1:9016")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("rejects an invalid port", func() {
+			_, err := auctioneer.ParseListenAddress("0.0.0.0:http")
+			Expect(err).To(MatchError(ContainSubstring("invalid port")))
+
+			_, err = auctioneer.ParseListenAddress("0.0.0.0:65536")
+			Expect(err).To(MatchError(ContainSubstring("invalid port")))
+		})
+	})
+
+	Describe("URL", func() {
+		It("joins the host and port", func() {
+			address, err := auctioneer.ParseListenAddress("0.0.0.0:9016")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(address.URL("https", "10.0.0.1")).To(Equal("https://10.0.0.1:9016"))
+		})
+
+		It("brackets IPv6 hosts", func() {
+			address, err := auctioneer.ParseListenAddress("[::]:9016")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(address.URL("http", "fd00::1")).To(Equal("http://[fd00::1]:9016"))
+		})
+
+		It("returns the socket address for a unix socket", func() {
+			address, err := auctioneer.ParseListenAddress("unix:///tmp/auctioneer.sock")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(address.URL("https", "10.0.0.1")).To(Equal("unix:///tmp/auctioneer.sock"))
+		})
+	})
+})
@@ -1,10 +1,14 @@
 package auctionmetricemitterdelegate
 
 import (
+	"net/url"
+	"sort"
+	"strings"
 	"time"
 
 	"code.cloudfoundry.org/auction/auctiontypes"
-	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	"code.cloudfoundry.org/auctioneer/metrics"
+	"code.cloudfoundry.org/rep"
 )
 
 const (
@@ -14,30 +18,130 @@ const (
 	TaskAuctionsFailedCounter     = "AuctioneerTaskAuctionsFailed"
 	FetchStatesDuration           = "AuctioneerFetchStatesDuration"
 	FailedCellStateRequestCounter = "AuctioneerFailedCellStateRequests"
+
+	LRPAuctionsStartedByPlacementCounter  = "AuctioneerLRPAuctionsStartedByPlacement"
+	LRPAuctionsFailedByPlacementCounter   = "AuctioneerLRPAuctionsFailedByPlacement"
+	TaskAuctionsStartedByPlacementCounter = "AuctioneerTaskAuctionsStartedByPlacement"
+	TaskAuctionsFailedByPlacementCounter  = "AuctioneerTaskAuctionsFailedByPlacement"
+)
+
+const (
+	DomainTag         = "domain"
+	PlacementTagsTag  = "placement_tags"
+	RootFSTag         = "rootfs"
+	PlacementErrorTag = "placement_error"
 )
 
 type auctionMetricEmitterDelegate struct {
-	metronClient loggingclient.IngressClient
+	sink metrics.Sink
 }
 
-func New(metronClient loggingclient.IngressClient) auctionMetricEmitterDelegate {
+func New(sink metrics.Sink) auctionMetricEmitterDelegate {
 	return auctionMetricEmitterDelegate{
-		metronClient: metronClient,
+		sink: sink,
 	}
 }
 
 func (d auctionMetricEmitterDelegate) FetchStatesCompleted(fetchStatesDuration time.Duration) error {
-	return d.metronClient.SendDuration(FetchStatesDuration, fetchStatesDuration)
+	return d.sink.SendDuration(FetchStatesDuration, fetchStatesDuration, nil)
 }
 
 func (d auctionMetricEmitterDelegate) FailedCellStateRequest() {
-	d.metronClient.IncrementCounter(FailedCellStateRequestCounter)
+	d.sink.IncrementCounter(FailedCellStateRequestCounter, 1, nil)
 }
 
 func (d auctionMetricEmitterDelegate) AuctionCompleted(results auctiontypes.AuctionResults) {
-	d.metronClient.IncrementCounterWithDelta(LRPAuctionsStartedCounter, uint64(len(results.SuccessfulLRPs)))
-	d.metronClient.IncrementCounterWithDelta(TaskAuctionStartedCounter, uint64(len(results.SuccessfulTasks)))
+	d.sink.IncrementCounter(LRPAuctionsStartedCounter, uint64(len(results.SuccessfulLRPs)), nil)
+	d.sink.IncrementCounter(TaskAuctionStartedCounter, uint64(len(results.SuccessfulTasks)), nil)
+
+	d.sink.IncrementCounter(LRPAuctionsFailedCounter, uint64(len(results.FailedLRPs)), nil)
+	d.sink.IncrementCounter(TaskAuctionsFailedCounter, uint64(len(results.FailedTasks)), nil)
+
+	started := newTagCounts()
+	for i := range results.SuccessfulLRPs {
+		lrp := &results.SuccessfulLRPs[i]
+		started.add(placementTags(lrp.Domain, lrp.PlacementConstraint))
+	}
+	started.emit(d.sink, LRPAuctionsStartedByPlacementCounter)
+
+	failed := newTagCounts()
+	for i := range results.FailedLRPs {
+		lrp := &results.FailedLRPs[i]
+		failed.add(failureTags(lrp.Domain, lrp.PlacementConstraint, lrp.PlacementError))
+	}
+	failed.emit(d.sink, LRPAuctionsFailedByPlacementCounter)
+
+	started = newTagCounts()
+	for i := range results.SuccessfulTasks {
+		task := &results.SuccessfulTasks[i]
+		started.add(placementTags(task.Domain, task.PlacementConstraint))
+	}
+	started.emit(d.sink, TaskAuctionsStartedByPlacementCounter)
+
+	failed = newTagCounts()
+	for i := range results.FailedTasks {
+		task := &results.FailedTasks[i]
+		failed.add(failureTags(task.Domain, task.PlacementConstraint, task.PlacementError))
+	}
+	failed.emit(d.sink, TaskAuctionsFailedByPlacementCounter)
+}
+
+func placementTags(domain string, pc rep.PlacementConstraint) map[string]string {
+	placementTags := append([]string{}, pc.PlacementTags...)
+	sort.Strings(placementTags)
+
+	return map[string]string{
+		DomainTag:        domain,
+		PlacementTagsTag: strings.Join(placementTags, ","),
+		RootFSTag:        rootFSTag(pc.RootFs),
+	}
+}
+
+func failureTags(domain string, pc rep.PlacementConstraint, placementError string) map[string]string {
+	tags := placementTags(domain, pc)
+	tags[PlacementErrorTag] = placementError
+	return tags
+}
+
+// rootFSTag keeps preloaded rootfses as they are but reduces every other
+// rootfs, such as docker images, to its scheme to keep the number of distinct
+// tag values small.
+func rootFSTag(rootFS string) string {
+	rootFSURL, err := url.Parse(rootFS)
+	if err != nil || rootFSURL.Scheme == "" {
+		return rootFS
+	}
 
-	d.metronClient.IncrementCounterWithDelta(LRPAuctionsFailedCounter, uint64(len(results.FailedLRPs)))
-	d.metronClient.IncrementCounterWithDelta(TaskAuctionsFailedCounter, uint64(len(results.FailedTasks)))
+	if rootFSURL.Scheme == "preloaded" {
+		return rootFS
+	}
+	return rootFSURL.Scheme
+}
+
+type tagCounts struct {
+	keys   []string
+	tags   map[string]map[string]string
+	counts map[string]uint64
+}
+
+func newTagCounts() *tagCounts {
+	return &tagCounts{
+		tags:   map[string]map[string]string{},
+		counts: map[string]uint64{},
+	}
+}
+
+func (t *tagCounts) add(tags map[string]string) {
+	key := tags[DomainTag] + "\x00" + tags[PlacementTagsTag] + "\x00" + tags[RootFSTag] + "\x00" + tags[PlacementErrorTag]
+	if _, ok := t.counts[key]; !ok {
+		t.keys = append(t.keys, key)
+		t.tags[key] = tags
+	}
+	t.counts[key]++
+}
+
+func (t *tagCounts) emit(sink metrics.Sink, name string) {
+	for _, key := range t.keys {
+		sink.IncrementCounter(name, t.counts[key], t.tags[key])
+	}
 }
@@ -5,8 +5,8 @@ import (
 
 	"code.cloudfoundry.org/auction/auctiontypes"
 	"code.cloudfoundry.org/auctioneer/auctionmetricemitterdelegate"
+	"code.cloudfoundry.org/auctioneer/metrics/metricsfakes"
 	"code.cloudfoundry.org/bbs/models"
-	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
 	"code.cloudfoundry.org/rep"
 
 	. "github.com/onsi/ginkgo"
@@ -15,12 +15,12 @@ import (
 
 var _ = Describe("Auction Metric Emitter Delegate", func() {
 	var delegate auctiontypes.AuctionMetricEmitterDelegate
-	var fakeMetronClient *mfakes.FakeIngressClient
+	var fakeSink *metricsfakes.FakeSink
 
 	BeforeEach(func() {
-		fakeMetronClient = &mfakes.FakeIngressClient{}
+		fakeSink = &metricsfakes.FakeSink{}
 
-		delegate = auctionmetricemitterdelegate.New(fakeMetronClient)
+		delegate = auctionmetricemitterdelegate.New(fakeSink)
 	})
 
 	Describe("AuctionCompleted", func() {
@@ -56,24 +56,73 @@ var _ = Describe("Auction Metric Emitter Delegate", func() {
 				},
 			})
 
-			Expect(fakeMetronClient.IncrementCounterWithDeltaCallCount()).To(Equal(4))
-
-			name, value := fakeMetronClient.IncrementCounterWithDeltaArgsForCall(0)
+			name, value, tags := fakeSink.IncrementCounterArgsForCall(0)
 			Expect(name).To(Equal("AuctioneerLRPAuctionsStarted"))
 			Expect(value).To(BeEquivalentTo(1))
+			Expect(tags).To(BeNil())
 
-			name, value = fakeMetronClient.IncrementCounterWithDeltaArgsForCall(1)
+			name, value, _ = fakeSink.IncrementCounterArgsForCall(1)
 			Expect(name).To(Equal("AuctioneerTaskAuctionsStarted"))
 			Expect(value).To(BeEquivalentTo(1))
 
-			name, value = fakeMetronClient.IncrementCounterWithDeltaArgsForCall(2)
+			name, value, _ = fakeSink.IncrementCounterArgsForCall(2)
 			Expect(name).To(Equal("AuctioneerLRPAuctionsFailed"))
 			Expect(value).To(BeEquivalentTo(2))
 
-			name, value = fakeMetronClient.IncrementCounterWithDeltaArgsForCall(3)
+			name, value, _ = fakeSink.IncrementCounterArgsForCall(3)
 			Expect(name).To(Equal("AuctioneerTaskAuctionsFailed"))
 			Expect(value).To(BeEquivalentTo(1))
 		})
+
+		It("breaks the counters down by domain, placement tags, rootfs and placement error", func() {
+			resource := rep.NewResource(10, 10, 10)
+			linux := rep.NewPlacementConstraint("preloaded:cflinuxfs3", []string{"segment-b", "segment-a"}, []string{})
+			docker := rep.NewPlacementConstraint("docker:///busybox", []string{}, []string{})
+			delegate.AuctionCompleted(auctiontypes.AuctionResults{
+				SuccessfulLRPs: []auctiontypes.LRPAuction{
+					{LRP: rep.NewLRP("", models.NewActualLRPKey("lrp-a", 0, "cf-apps"), resource, linux)},
+					{LRP: rep.NewLRP("", models.NewActualLRPKey("lrp-a", 1, "cf-apps"), resource, linux)},
+					{LRP: rep.NewLRP("", models.NewActualLRPKey("lrp-b", 0, "cf-apps"), resource, docker)},
+				},
+				FailedTasks: []auctiontypes.TaskAuction{
+					{
+						Task:          rep.NewTask("failed-task", "cf-tasks", resource, linux),
+						AuctionRecord: auctiontypes.AuctionRecord{PlacementError: "insufficient resources"},
+					},
+				},
+			})
+
+			Expect(fakeSink.IncrementCounterCallCount()).To(Equal(7))
+
+			calls := []taggedCall{}
+			for i := 4; i < fakeSink.IncrementCounterCallCount(); i++ {
+				name, value, tags := fakeSink.IncrementCounterArgsForCall(i)
+				calls = append(calls, taggedCall{name: name, value: value, tags: tags})
+			}
+
+			Expect(calls).To(Equal([]taggedCall{
+				{
+					name:  "AuctioneerLRPAuctionsStartedByPlacement",
+					value: 2,
+					tags:  map[string]string{"domain": "cf-apps", "placement_tags": "segment-a,segment-b", "rootfs": "preloaded:cflinuxfs3"},
+				},
+				{
+					name:  "AuctioneerLRPAuctionsStartedByPlacement",
+					value: 1,
+					tags:  map[string]string{"domain": "cf-apps", "placement_tags": "", "rootfs": "docker"},
+				},
+				{
+					name:  "AuctioneerTaskAuctionsFailedByPlacement",
+					value: 1,
+					tags: map[string]string{
+						"domain":          "cf-tasks",
+						"placement_tags":  "segment-a,segment-b",
+						"rootfs":          "preloaded:cflinuxfs3",
+						"placement_error": "insufficient resources",
+					},
+				},
+			}))
+		})
 	})
 
 	Describe("FetchStatesCompleted", func() {
@@ -81,8 +130,8 @@ var _ = Describe("Auction Metric Emitter Delegate", func() {
 			err := delegate.FetchStatesCompleted(1 * time.Second)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(fakeMetronClient.SendDurationCallCount()).To(Equal(1))
-			name, value, _ := fakeMetronClient.SendDurationArgsForCall(0)
+			Expect(fakeSink.SendDurationCallCount()).To(Equal(1))
+			name, value, _ := fakeSink.SendDurationArgsForCall(0)
 			Expect(name).To(Equal("AuctioneerFetchStatesDuration"))
 			Expect(value).To(Equal(1 * time.Second))
 		})
@@ -92,9 +141,16 @@ var _ = Describe("Auction Metric Emitter Delegate", func() {
 		It("should adjust the metric counters", func() {
 			delegate.FailedCellStateRequest()
 
-			Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
-			name := fakeMetronClient.IncrementCounterArgsForCall(0)
+			Expect(fakeSink.IncrementCounterCallCount()).To(Equal(1))
+			name, delta, _ := fakeSink.IncrementCounterArgsForCall(0)
 			Expect(name).To(Equal("AuctioneerFailedCellStateRequests"))
+			Expect(delta).To(BeEquivalentTo(1))
 		})
 	})
 })
+
+type taggedCall struct {
+	name  string
+	value uint64
+	tags  map[string]string
+}
@@ -1,70 +1,351 @@
 package auctionrunnerdelegate
 
 import (
-	"code.cloudfoundry.org/bbs"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/metrics"
+	"code.cloudfoundry.org/auctioneer/tracing"
+	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/workpool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"code.cloudfoundry.org/auction/auctiontypes"
 	"code.cloudfoundry.org/lager"
 )
 
+const (
+	AuctionCompletedDuration = "AuctioneerAuctionCompletedDuration"
+
+	DefaultMaxBBSUpdateWorkers = 25
+)
+
+var errPerformRejected = errors.New("cell rejected the work")
+
+// WorkTraces returns the trace context a work item was submitted with, keyed
+// by task guid or LRP instance identifier. Spans for the rep and BBS calls
+// made on behalf of a work item are created as children of that context.
+//
+// The trace context is not propagated to the rep or the BBS: their clients
+// take no context, and a single rep request carries work from many traces,
+// so the spans end at the auctioneer.
+type WorkTraces interface {
+	SpanContext(identifier string) trace.SpanContext
+}
+
+// AuctionObserver is notified of every completed auction once the BBS has
+// been updated with its results.
+type AuctionObserver interface {
+	AuctionCompleted(auction CompletedAuction)
+}
+
+// CompletedAuction describes a single run of the auction: its results, the
+// number of cells that were candidates for placement, the states those cells
+// reported at the start of the auction and when it ran. CellIDs lists every
+// cell registered with the BBS at the start of the auction, including cells
+// that were not candidates or did not report a state.
+type CompletedAuction struct {
+	Results     auctiontypes.AuctionResults
+	CellCount   int
+	CellIDs     []string
+	CellStates  map[string]rep.CellState
+	StartedAt   time.Time
+	CompletedAt time.Time
+}
+
 type AuctionRunnerDelegate struct {
-	repClientFactory rep.ClientFactory
-	bbsClient        bbs.InternalClient
-	logger           lager.Logger
+	repClientFactory    rep.ClientFactory
+	bbsClient           BBSClient
+	sink                metrics.Sink
+	maxBBSUpdateWorkers int
+	traces              WorkTraces
+	clock               clock.Clock
+	logger              lager.Logger
+	observers           []AuctionObserver
+
+	auctionLock       sync.Mutex
+	auctionCellCount  int
+	auctionCellIDs    []string
+	auctionCellStates map[string]rep.CellState
+	auctionStartedAt  time.Time
+
+	// cellStates holds the states of the most recently completed auction.
+	// It is replaced as a whole when an auction completes, so readers never
+	// see the partial states of an auction in progress.
+	cellStates map[string]rep.CellState
 }
 
 func New(
 	repClientFactory rep.ClientFactory,
-	bbsClient bbs.InternalClient,
+	bbsClient BBSClient,
+	sink metrics.Sink,
+	maxBBSUpdateWorkers int,
+	traces WorkTraces,
+	clock clock.Clock,
 	logger lager.Logger,
+	observers ...AuctionObserver,
 ) *AuctionRunnerDelegate {
 	return &AuctionRunnerDelegate{
-		repClientFactory: repClientFactory,
-		bbsClient:        bbsClient,
-		logger:           logger,
+		repClientFactory:    repClientFactory,
+		bbsClient:           bbsClient,
+		sink:                sink,
+		maxBBSUpdateWorkers: maxBBSUpdateWorkers,
+		traces:              traces,
+		clock:               clock,
+		logger:              logger,
+		observers:           observers,
 	}
 }
 
 func (a *AuctionRunnerDelegate) FetchCellReps() (map[string]rep.Client, error) {
+	startedAt := a.clock.Now()
 	cells, err := a.bbsClient.Cells(a.logger)
 	cellReps := map[string]rep.Client{}
 	if err != nil {
 		return cellReps, err
 	}
 
+	cellIDs := make([]string, 0, len(cells))
 	for _, cell := range cells {
+		cellIDs = append(cellIDs, cell.CellId)
 		client, err := a.repClientFactory.CreateClient(cell.RepAddress, cell.RepUrl)
 		if err != nil {
 			a.logger.Error("create-rep-client-failed", err)
 			continue
 		}
-		cellReps[cell.CellId] = client
+		cellReps[cell.CellId] = &cellClient{Client: client, cellID: cell.CellId, delegate: a}
 	}
 
+	a.auctionLock.Lock()
+	a.auctionCellCount = len(cellReps)
+	a.auctionCellIDs = cellIDs
+	a.auctionCellStates = map[string]rep.CellState{}
+	a.auctionStartedAt = startedAt
+	a.auctionLock.Unlock()
+
 	return cellReps, nil
 }
 
+// AuctionCompleted updates the BBS with the failures of the auction and
+// notifies the observers. AuctionCompletedDuration, the time taken to update
+// the BBS, is sent for every auction, and is near zero for auctions without
+// failures.
 func (a *AuctionRunnerDelegate) AuctionCompleted(results auctiontypes.AuctionResults) {
-	for i := range results.FailedTasks {
-		task := &results.FailedTasks[i]
-		err := a.bbsClient.RejectTask(a.logger, task.TaskGuid, task.PlacementError)
-		if err != nil {
-			a.logger.Error("failed-to-reject-task", err, lager.Data{
-				"task":           task,
-				"auction-result": "failed",
-			})
-		}
+	logger := a.logger.Session("auction-completed", failureData(results))
+	startTime := a.clock.Now()
+
+	if len(results.FailedTasks) > 0 || len(results.FailedLRPs) > 0 {
+		a.updateBBS(logger, results)
 	}
 
-	for i := range results.FailedLRPs {
-		lrp := &results.FailedLRPs[i]
-		err := a.bbsClient.FailActualLRP(a.logger, &lrp.ActualLRPKey, lrp.PlacementError)
-		if err != nil {
-			a.logger.Error("failed-to-fail-LRP", err, lager.Data{
-				"lrp":            lrp,
-				"auction-result": "failed",
-			})
+	err := a.sink.SendDuration(AuctionCompletedDuration, a.clock.Since(startTime), nil)
+	if err != nil {
+		logger.Error("failed-to-send-auction-completed-duration", err)
+	}
+
+	a.auctionLock.Lock()
+	auction := CompletedAuction{
+		Results:     results,
+		CellCount:   a.auctionCellCount,
+		CellIDs:     a.auctionCellIDs,
+		CellStates:  a.auctionCellStates,
+		StartedAt:   a.auctionStartedAt,
+		CompletedAt: a.clock.Now(),
+	}
+	if a.auctionCellStates != nil {
+		a.cellStates = a.auctionCellStates
+	}
+	a.auctionCellStates = nil
+	a.auctionLock.Unlock()
+
+	for _, observer := range a.observers {
+		observer.AuctionCompleted(auction)
+	}
+}
+
+// WorkRefused rejects the failed tasks and fails the failed actual LRPs of
+// work that was refused before it reached an auction. Unlike
+// AuctionCompleted, it does not notify the observers.
+func (a *AuctionRunnerDelegate) WorkRefused(results auctiontypes.AuctionResults) {
+	if len(results.FailedTasks) == 0 && len(results.FailedLRPs) == 0 {
+		return
+	}
+
+	a.updateBBS(a.logger.Session("work-refused", failureData(results)), results)
+}
+
+func failureData(results auctiontypes.AuctionResults) lager.Data {
+	return lager.Data{
+		"failed-tasks": len(results.FailedTasks),
+		"failed-lrps":  len(results.FailedLRPs),
+	}
+}
+
+func (a *AuctionRunnerDelegate) updateBBS(logger lager.Logger, results auctiontypes.AuctionResults) {
+	works := a.rejectTaskWorks(logger, results.FailedTasks)
+	works = append(works, a.failActualLRPWorks(logger, results.FailedLRPs)...)
+
+	a.work(logger, works)
+}
+
+func (a *AuctionRunnerDelegate) rejectTaskWorks(logger lager.Logger, tasks []auctiontypes.TaskAuction) []func() {
+	works := make([]func(), 0, len(tasks))
+	for i := range tasks {
+		task := &tasks[i]
+		works = append(works, func() {
+			span := a.startSpan("bbs.RejectTask", task.TaskGuid)
+			err := a.bbsClient.RejectTask(logger, task.TaskGuid, task.PlacementError)
+			endSpan(span, err)
+			if err != nil {
+				logger.Error("failed-to-reject-task", err, lager.Data{
+					"task":           task,
+					"auction-result": "failed",
+				})
+			}
+		})
+	}
+	return works
+}
+
+func (a *AuctionRunnerDelegate) failActualLRPWorks(logger lager.Logger, lrps []auctiontypes.LRPAuction) []func() {
+	works := make([]func(), 0, len(lrps))
+	for i := range lrps {
+		lrp := &lrps[i]
+		works = append(works, func() {
+			span := a.startSpan("bbs.FailActualLRP", lrp.Identifier())
+			err := a.bbsClient.FailActualLRP(logger, &lrp.ActualLRPKey, lrp.PlacementError)
+			endSpan(span, err)
+			if err != nil {
+				logger.Error("failed-to-fail-LRP", err, lager.Data{
+					"lrp":            lrp,
+					"auction-result": "failed",
+				})
+			}
+		})
+	}
+	return works
+}
+
+func (a *AuctionRunnerDelegate) work(logger lager.Logger, works []func()) {
+	throttler, err := workpool.NewThrottler(a.maxBBSUpdateWorkers, works)
+	if err != nil {
+		logger.Error("failed-to-construct-throttler", err, lager.Data{"max-workers": a.maxBBSUpdateWorkers})
+		return
+	}
+	throttler.Work()
+}
+
+// CellStates returns the states reported by the cells during the most recently
+// completed auction. The states of an auction in progress are not returned
+// until it completes.
+func (a *AuctionRunnerDelegate) CellStates() map[string]rep.CellState {
+	a.auctionLock.Lock()
+	defer a.auctionLock.Unlock()
+
+	states := make(map[string]rep.CellState, len(a.cellStates))
+	for cellID, state := range a.cellStates {
+		states[cellID] = state
+	}
+	return states
+}
+
+func (a *AuctionRunnerDelegate) recordCellState(cellID string, state rep.CellState) {
+	a.auctionLock.Lock()
+	defer a.auctionLock.Unlock()
+
+	if a.auctionCellStates != nil {
+		a.auctionCellStates[cellID] = state
+	}
+}
+
+// startSpan starts a span as a child of the trace context the work item was
+// submitted with. It returns nil when the work item has no trace context.
+func (a *AuctionRunnerDelegate) startSpan(name, identifier string, attributes ...attribute.KeyValue) trace.Span {
+	if a.traces == nil {
+		return nil
+	}
+
+	spanContext := a.traces.SpanContext(identifier)
+	if !spanContext.IsValid() {
+		return nil
+	}
+
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), spanContext)
+	attributes = append(attributes, attribute.String("work", identifier))
+	_, span := tracing.Tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+	return span
+}
+
+func endSpan(span trace.Span, err error) {
+	if span == nil {
+		return
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func endSpans(spans []trace.Span, err error) {
+	for _, span := range spans {
+		endSpan(span, err)
+	}
+}
+
+// cellClient keeps the state a cell reports to the auction so that observers
+// can reason about placement decisions afterwards, and traces the work
+// performed on the cell.
+type cellClient struct {
+	rep.Client
+	cellID   string
+	delegate *AuctionRunnerDelegate
+}
+
+func (c *cellClient) State(logger lager.Logger) (rep.CellState, error) {
+	state, err := c.Client.State(logger)
+	if err == nil {
+		c.delegate.recordCellState(c.cellID, state)
+	}
+	return state, err
+}
+
+func (c *cellClient) Perform(logger lager.Logger, work rep.Work) (rep.Work, error) {
+	cellID := attribute.String("cell-id", c.cellID)
+
+	spans := map[string]trace.Span{}
+	for i := range work.LRPs {
+		identifier := work.LRPs[i].Identifier()
+		spans[identifier] = c.delegate.startSpan("rep.Perform", identifier, cellID)
+	}
+	for i := range work.Tasks {
+		identifier := work.Tasks[i].TaskGuid
+		spans[identifier] = c.delegate.startSpan("rep.Perform", identifier, cellID)
+	}
+
+	failedWork, err := c.Client.Perform(logger, work)
+
+	failed := map[string]bool{}
+	for i := range failedWork.LRPs {
+		failed[failedWork.LRPs[i].Identifier()] = true
+	}
+	for i := range failedWork.Tasks {
+		failed[failedWork.Tasks[i].TaskGuid] = true
+	}
+
+	for identifier, span := range spans {
+		spanErr := err
+		if spanErr == nil && failed[identifier] {
+			spanErr = errPerformRejected
 		}
+		endSpan(span, spanErr)
 	}
+
+	return failedWork, err
 }
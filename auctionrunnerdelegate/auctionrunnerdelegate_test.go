@@ -2,10 +2,12 @@ package auctionrunnerdelegate_test
 
 import (
 	"errors"
+	"time"
 
 	"code.cloudfoundry.org/auction/auctiontypes"
 	"code.cloudfoundry.org/bbs/fake_bbs"
 	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock/fakeclock"
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/repfakes"
 
@@ -13,10 +15,16 @@ import (
 	"code.cloudfoundry.org/lager/lagertest"
 
 	"code.cloudfoundry.org/auctioneer/auctionrunnerdelegate"
+	"code.cloudfoundry.org/auctioneer/metrics/metricsfakes"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var _ = Describe("Auction Runner Delegate", func() {
@@ -25,6 +33,8 @@ var _ = Describe("Auction Runner Delegate", func() {
 		bbsClient        *fake_bbs.FakeInternalClient
 		repClientFactory *repfakes.FakeClientFactory
 		repClient        *repfakes.FakeClient
+		fakeSink         *metricsfakes.FakeSink
+		fakeClock        *fakeclock.FakeClock
 		logger           lager.Logger
 	)
 
@@ -33,9 +43,11 @@ var _ = Describe("Auction Runner Delegate", func() {
 		repClientFactory = &repfakes.FakeClientFactory{}
 		repClient = &repfakes.FakeClient{}
 		repClientFactory.CreateClientReturns(repClient, nil)
+		fakeSink = &metricsfakes.FakeSink{}
+		fakeClock = fakeclock.NewFakeClock(time.Now())
 		logger = lagertest.NewTestLogger("delegate")
 
-		delegate = auctionrunnerdelegate.New(repClientFactory, bbsClient, logger)
+		delegate = auctionrunnerdelegate.New(repClientFactory, bbsClient, fakeSink, 10, nil, fakeClock, logger)
 	})
 
 	Describe("fetching cell reps", func() {
@@ -101,8 +113,63 @@ var _ = Describe("Auction Runner Delegate", func() {
 				Expect(reps).To(HaveKey("cell-A"))
 				Expect(reps).To(HaveKey("cell-B"))
 
-				Expect(reps["cell-A"]).To(Equal(repClient))
-				Expect(reps["cell-B"]).To(Equal(repClient))
+				_, err = reps["cell-A"].Perform(logger, rep.Work{})
+				Expect(err).NotTo(HaveOccurred())
+				_, err = reps["cell-B"].Perform(logger, rep.Work{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(repClient.PerformCallCount()).To(Equal(2))
+			})
+
+			It("records the states reported by the cells", func() {
+				repClient.StateReturns(rep.CellState{CellID: "cell-A", Zone: "zone-1"}, nil)
+
+				reps, err := delegate.FetchCellReps()
+				Expect(err).NotTo(HaveOccurred())
+
+				state, err := reps["cell-A"].State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state.Zone).To(Equal("zone-1"))
+				Expect(delegate.CellStates()).To(BeEmpty())
+
+				delegate.AuctionCompleted(auctiontypes.AuctionResults{})
+				Expect(delegate.CellStates()).To(Equal(map[string]rep.CellState{
+					"cell-A": {CellID: "cell-A", Zone: "zone-1"},
+				}))
+			})
+
+			It("keeps returning the states of the last completed auction while the next one runs", func() {
+				repClient.StateReturns(rep.CellState{CellID: "cell-A", Zone: "zone-1"}, nil)
+
+				reps, err := delegate.FetchCellReps()
+				Expect(err).NotTo(HaveOccurred())
+				_, err = reps["cell-A"].State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				delegate.AuctionCompleted(auctiontypes.AuctionResults{})
+
+				repClient.StateReturns(rep.CellState{CellID: "cell-A", Zone: "zone-2"}, nil)
+				reps, err = delegate.FetchCellReps()
+				Expect(err).NotTo(HaveOccurred())
+				_, err = reps["cell-A"].State(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(delegate.CellStates()).To(Equal(map[string]rep.CellState{
+					"cell-A": {CellID: "cell-A", Zone: "zone-1"},
+				}))
+			})
+
+			Context("when a cell fails to report its state", func() {
+				It("does not record a state for it", func() {
+					repClient.StateReturns(rep.CellState{}, errors.New("boom"))
+
+					reps, err := delegate.FetchCellReps()
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = reps["cell-A"].State(logger)
+					Expect(err).To(MatchError("boom"))
+
+					delegate.AuctionCompleted(auctiontypes.AuctionResults{})
+					Expect(delegate.CellStates()).To(BeEmpty())
+				})
 			})
 
 			Context("when creating a rep client fails", func() {
@@ -190,26 +257,228 @@ var _ = Describe("Auction Runner Delegate", func() {
 					},
 				},
 			}
+		})
+
+		Context("when the auction has failures", func() {
+			BeforeEach(func() {
+				bbsClient.FailActualLRPStub = func(lager.Logger, *models.ActualLRPKey, string) error {
+					fakeClock.Increment(time.Second)
+					return nil
+				}
+
+				delegate.AuctionCompleted(results)
+			})
+
+			It("should reject all tasks with the appropriate failure reason", func() {
+				Expect(bbsClient.RejectTaskCallCount()).To(Equal(1))
+				_, taskGuid, failureReason := bbsClient.RejectTaskArgsForCall(0)
+				Expect(taskGuid).To(Equal("failed-task"))
+				Expect(failureReason).To(Equal("insufficient resources"))
+			})
+
+			It("should mark all failed LRPs as UNCLAIMED with the appropriate placement error", func() {
+				Expect(bbsClient.FailActualLRPCallCount()).To(Equal(2))
+
+				failures := map[models.ActualLRPKey]string{}
+				for i := 0; i < bbsClient.FailActualLRPCallCount(); i++ {
+					_, lrpKey, errorMessage := bbsClient.FailActualLRPArgsForCall(i)
+					failures[*lrpKey] = errorMessage
+				}
+
+				Expect(failures).To(Equal(map[models.ActualLRPKey]string{
+					models.NewActualLRPKey("insufficient-capacity", 0, "domain"): "insufficient resources",
+					models.NewActualLRPKey("incompatible-stacks", 0, "domain"):   auctiontypes.ErrorCellMismatch.Error(),
+				}))
+			})
+
+			It("emits the time it took to update the BBS", func() {
+				Expect(fakeSink.SendDurationCallCount()).To(Equal(1))
+				name, value, _ := fakeSink.SendDurationArgsForCall(0)
+				Expect(name).To(Equal("AuctioneerAuctionCompletedDuration"))
+				Expect(value).To(Equal(2 * time.Second))
+			})
+		})
+
+		Context("when the BBS updates are slow", func() {
+			var blockingBBSClient chan struct{}
+
+			BeforeEach(func() {
+				blockingBBSClient = make(chan struct{})
+				bbsClient.FailActualLRPStub = func(lager.Logger, *models.ActualLRPKey, string) error {
+					<-blockingBBSClient
+					return nil
+				}
+			})
+
+			It("sends the updates concurrently", func() {
+				done := make(chan struct{})
+				go func() {
+					defer close(done)
+					delegate.AuctionCompleted(results)
+				}()
+
+				Eventually(bbsClient.FailActualLRPCallCount).Should(Equal(2))
+				Eventually(bbsClient.RejectTaskCallCount).Should(Equal(1))
+				Consistently(done).ShouldNot(BeClosed())
+
+				close(blockingBBSClient)
+				Eventually(done).Should(BeClosed())
+			})
+		})
+
+		Context("when observers are registered", func() {
+			var observer *fakeObserver
+
+			BeforeEach(func() {
+				observer = &fakeObserver{}
+				delegate = auctionrunnerdelegate.New(repClientFactory, bbsClient, fakeSink, 10, nil, fakeClock, logger, observer)
+
+				cellPresence1 := models.NewCellPresence("cell-A", "cell-a.url", "", "zone-1", models.NewCellCapacity(123, 456, 789), []string{}, []string{}, []string{}, []string{})
+				cellPresence2 := models.NewCellPresence("cell-B", "cell-b.url", "", "zone-1", models.NewCellCapacity(123, 456, 789), []string{}, []string{}, []string{}, []string{})
+				bbsClient.CellsReturns([]*models.CellPresence{&cellPresence1, &cellPresence2}, nil)
+			})
+
+			It("notifies them of the completed auction after updating the BBS", func() {
+				startedAt := fakeClock.Now()
+				_, err := delegate.FetchCellReps()
+				Expect(err).NotTo(HaveOccurred())
+
+				fakeClock.Increment(time.Second)
+				delegate.AuctionCompleted(results)
 
-			delegate.AuctionCompleted(results)
+				Expect(bbsClient.RejectTaskCallCount()).To(Equal(1))
+				Expect(observer.auctions).To(HaveLen(1))
+				Expect(observer.auctions[0]).To(Equal(auctionrunnerdelegate.CompletedAuction{
+					Results:     results,
+					CellCount:   2,
+					CellIDs:     []string{"cell-A", "cell-B"},
+					CellStates:  map[string]rep.CellState{},
+					StartedAt:   startedAt,
+					CompletedAt: startedAt.Add(time.Second),
+				}))
+			})
+
+			It("updates the BBS with refused work without notifying them", func() {
+				delegate.WorkRefused(results)
+
+				Expect(bbsClient.RejectTaskCallCount()).To(Equal(1))
+				Expect(bbsClient.FailActualLRPCallCount()).To(Equal(2))
+				Expect(observer.auctions).To(BeEmpty())
+				Expect(fakeSink.SendDurationCallCount()).To(Equal(0))
+			})
 		})
 
-		It("should reject all tasks with the appropriate failure reason", func() {
-			Expect(bbsClient.RejectTaskCallCount()).To(Equal(1))
-			_, taskGuid, failureReason := bbsClient.RejectTaskArgsForCall(0)
-			Expect(taskGuid).To(Equal("failed-task"))
-			Expect(failureReason).To(Equal("insufficient resources"))
+		Context("when the auction has no failures", func() {
+			BeforeEach(func() {
+				results.FailedTasks = nil
+				results.FailedLRPs = nil
+
+				delegate.AuctionCompleted(results)
+			})
+
+			It("does not update the BBS", func() {
+				Expect(bbsClient.RejectTaskCallCount()).To(Equal(0))
+				Expect(bbsClient.FailActualLRPCallCount()).To(Equal(0))
+			})
+
+			It("still emits the auction completed duration", func() {
+				Expect(fakeSink.SendDurationCallCount()).To(Equal(1))
+				name, value, _ := fakeSink.SendDurationArgsForCall(0)
+				Expect(name).To(Equal("AuctioneerAuctionCompletedDuration"))
+				Expect(value).To(BeZero())
+			})
 		})
+	})
 
-		It("should mark all failed LRPs as UNCLAIMED with the appropriate placement error", func() {
-			Expect(bbsClient.FailActualLRPCallCount()).To(Equal(2))
-			_, lrpKey, errorMessage := bbsClient.FailActualLRPArgsForCall(0)
-			Expect(*lrpKey).To(Equal(models.NewActualLRPKey("insufficient-capacity", 0, "domain")))
-			Expect(errorMessage).To(Equal("insufficient resources"))
+	Describe("tracing", func() {
+		var (
+			recorder    *tracetest.SpanRecorder
+			spanContext trace.SpanContext
+			pc          rep.PlacementConstraint
+			resource    rep.Resource
+		)
 
-			_, lrpKey1, errorMessage1 := bbsClient.FailActualLRPArgsForCall(1)
-			Expect(*lrpKey1).To(Equal(models.NewActualLRPKey("incompatible-stacks", 0, "domain")))
-			Expect(errorMessage1).To(Equal(auctiontypes.ErrorCellMismatch.Error()))
+		BeforeEach(func() {
+			recorder = tracetest.NewSpanRecorder()
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+			traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+			Expect(err).NotTo(HaveOccurred())
+			spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+			Expect(err).NotTo(HaveOccurred())
+			spanContext = trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled})
+
+			traces := &fakeWorkTraces{spanContexts: map[string]trace.SpanContext{
+				"traced-task":  spanContext,
+				"traced-lrp.0": spanContext,
+			}}
+			delegate = auctionrunnerdelegate.New(repClientFactory, bbsClient, fakeSink, 10, traces, fakeClock, logger)
+
+			cellPresence := models.NewCellPresence("cell-A", "cell-a.url", "", "zone-1", models.NewCellCapacity(123, 456, 789), []string{}, []string{}, []string{}, []string{})
+			bbsClient.CellsReturns([]*models.CellPresence{&cellPresence}, nil)
+
+			resource = rep.NewResource(10, 10, 10)
+			pc = rep.NewPlacementConstraint("preloaded:linux", nil, nil)
+		})
+
+		AfterEach(func() {
+			otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		})
+
+		It("creates a span for each traced work item performed on a cell", func() {
+			failedTask := rep.NewTask("traced-task", "domain", resource, pc)
+			repClient.PerformReturns(rep.Work{Tasks: []rep.Task{failedTask}}, nil)
+
+			reps, err := delegate.FetchCellReps()
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = reps["cell-A"].Perform(logger, rep.Work{
+				Tasks: []rep.Task{failedTask, rep.NewTask("untraced-task", "domain", resource, pc)},
+				LRPs:  []rep.LRP{rep.NewLRP("", models.NewActualLRPKey("traced-lrp", 0, "domain"), resource, pc)},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			spans := recorder.Ended()
+			Expect(spans).To(HaveLen(2))
+			statuses := map[codes.Code]int{}
+			for _, span := range spans {
+				Expect(span.Name()).To(Equal("rep.Perform"))
+				Expect(span.Parent()).To(Equal(spanContext.WithRemote(true)))
+				statuses[span.Status().Code]++
+			}
+			Expect(statuses).To(Equal(map[codes.Code]int{codes.Error: 1, codes.Unset: 1}))
+		})
+
+		It("creates a span for each BBS update of a traced work item", func() {
+			delegate.AuctionCompleted(auctiontypes.AuctionResults{
+				FailedTasks: []auctiontypes.TaskAuction{
+					{Task: rep.NewTask("traced-task", "domain", resource, pc)},
+				},
+				FailedLRPs: []auctiontypes.LRPAuction{
+					{LRP: rep.NewLRP("", models.NewActualLRPKey("untraced-lrp", 0, "domain"), resource, pc)},
+				},
+			})
+
+			spans := recorder.Ended()
+			Expect(spans).To(HaveLen(1))
+			Expect(spans[0].Name()).To(Equal("bbs.RejectTask"))
+			Expect(spans[0].Parent().TraceID()).To(Equal(spanContext.TraceID()))
 		})
 	})
 })
+
+type fakeObserver struct {
+	auctions []auctionrunnerdelegate.CompletedAuction
+}
+
+func (o *fakeObserver) AuctionCompleted(auction auctionrunnerdelegate.CompletedAuction) {
+	o.auctions = append(o.auctions, auction)
+}
+
+type fakeWorkTraces struct {
+	spanContexts map[string]trace.SpanContext
+}
+
+func (f *fakeWorkTraces) SpanContext(identifier string) trace.SpanContext {
+	return f.spanContexts[identifier]
+}
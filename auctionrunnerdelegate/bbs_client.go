@@ -0,0 +1,56 @@
+package auctionrunnerdelegate
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager"
+)
+
+// BBSClient is the part of the BBS API the delegate uses to find cells and
+// record the work the auction could not place. bbs.InternalClient implements
+// it.
+type BBSClient interface {
+	Cells(logger lager.Logger) ([]*models.CellPresence, error)
+	RejectTask(logger lager.Logger, taskGuid, reason string) error
+	FailActualLRP(logger lager.Logger, key *models.ActualLRPKey, errorMessage string) error
+}
+
+// ReloadingBBSClient forwards to a BBS client that can be replaced while
+// requests are in flight, for example with one built from rotated
+// certificates.
+type ReloadingBBSClient struct {
+	lock   sync.RWMutex
+	client BBSClient
+}
+
+func NewReloadingBBSClient(client BBSClient) *ReloadingBBSClient {
+	return &ReloadingBBSClient{client: client}
+}
+
+// Set replaces the client used by subsequent requests.
+func (c *ReloadingBBSClient) Set(client BBSClient) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.client = client
+}
+
+func (c *ReloadingBBSClient) current() BBSClient {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.client
+}
+
+func (c *ReloadingBBSClient) Cells(logger lager.Logger) ([]*models.CellPresence, error) {
+	return c.current().Cells(logger)
+}
+
+func (c *ReloadingBBSClient) RejectTask(logger lager.Logger, taskGuid, reason string) error {
+	return c.current().RejectTask(logger, taskGuid, reason)
+}
+
+func (c *ReloadingBBSClient) FailActualLRP(logger lager.Logger, key *models.ActualLRPKey, errorMessage string) error {
+	return c.current().FailActualLRP(logger, key, errorMessage)
+}
@@ -0,0 +1,59 @@
+package auctionrunnerdelegate_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/auctioneer/auctionrunnerdelegate"
+	"code.cloudfoundry.org/bbs/fake_bbs"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReloadingBBSClient", func() {
+	var (
+		logger          *lagertest.TestLogger
+		original        *fake_bbs.FakeInternalClient
+		replacement     *fake_bbs.FakeInternalClient
+		reloadingClient *auctionrunnerdelegate.ReloadingBBSClient
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		original = &fake_bbs.FakeInternalClient{}
+		replacement = &fake_bbs.FakeInternalClient{}
+		reloadingClient = auctionrunnerdelegate.NewReloadingBBSClient(original)
+	})
+
+	It("forwards requests to the client it was created with", func() {
+		cell := models.NewCellPresence("cell-A", "cell-a.url", "", "zone-1", models.NewCellCapacity(123, 456, 789), []string{}, []string{}, []string{}, []string{})
+		original.CellsReturns([]*models.CellPresence{&cell}, nil)
+		original.RejectTaskReturns(errors.New("boom"))
+
+		cells, err := reloadingClient.Cells(logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cells).To(ConsistOf(&cell))
+
+		Expect(reloadingClient.RejectTask(logger, "task-guid", "no room")).To(MatchError("boom"))
+		_, taskGuid, reason := original.RejectTaskArgsForCall(0)
+		Expect(taskGuid).To(Equal("task-guid"))
+		Expect(reason).To(Equal("no room"))
+
+		key := models.NewActualLRPKey("process-guid", 1, "domain")
+		Expect(reloadingClient.FailActualLRP(logger, &key, "no room")).To(Succeed())
+		_, failedKey, message := original.FailActualLRPArgsForCall(0)
+		Expect(failedKey).To(Equal(&key))
+		Expect(message).To(Equal("no room"))
+	})
+
+	It("forwards requests to the client it was last set to", func() {
+		reloadingClient.Set(replacement)
+
+		_, err := reloadingClient.Cells(logger)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(original.CellsCallCount()).To(Equal(0))
+		Expect(replacement.CellsCallCount()).To(Equal(1))
+	})
+})
@@ -0,0 +1,13 @@
+package auditlog_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestAuditlog(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Audit Log Suite")
+}
@@ -0,0 +1 @@
+package auditlog // import "code.cloudfoundry.org/auctioneer/auditlog"
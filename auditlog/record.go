@@ -0,0 +1,130 @@
+package auditlog
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auctioneer/auctionrunnerdelegate"
+	"code.cloudfoundry.org/auctioneer/placementexplainer"
+	"code.cloudfoundry.org/rep"
+)
+
+const (
+	TaskWorkType = "task"
+	LRPWorkType  = "lrp"
+
+	SucceededResult = "succeeded"
+	FailedResult    = "failed"
+)
+
+// Record is the audit trail entry for a single work item of a completed
+// auction.
+type Record struct {
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	Result    string    `json:"result"`
+
+	TaskGuid    string `json:"task_guid,omitempty"`
+	ProcessGuid string `json:"process_guid,omitempty"`
+	Index       int32  `json:"index"`
+	Domain      string `json:"domain"`
+
+	Resource            rep.Resource            `json:"resource"`
+	PlacementConstraint rep.PlacementConstraint `json:"placement_constraint"`
+
+	Winner         string `json:"winner,omitempty"`
+	CandidateCount int    `json:"candidate_count"`
+	Attempts       int    `json:"attempts"`
+	PlacementError string `json:"placement_error,omitempty"`
+
+	CellRejections map[string][]string `json:"cell_rejections,omitempty"`
+
+	QueueTime        time.Time     `json:"queue_time"`
+	AuctionStartedAt time.Time     `json:"auction_started_at"`
+	WaitDuration     time.Duration `json:"wait_duration_ns"`
+	AuctionDuration  time.Duration `json:"auction_duration_ns"`
+}
+
+// NewRecords builds one Record per work item of the given auction, successful
+// items first. explanations are those of the auction's failed work items, as
+// returned by placementexplainer.Explain.
+func NewRecords(auction auctionrunnerdelegate.CompletedAuction, explanations []placementexplainer.Explanation) []Record {
+	results := auction.Results
+	records := make([]Record, 0, len(results.SuccessfulLRPs)+len(results.SuccessfulTasks)+len(results.FailedLRPs)+len(results.FailedTasks))
+
+	for i := range results.SuccessfulLRPs {
+		records = append(records, newLRPRecord(auction, &results.SuccessfulLRPs[i], SucceededResult))
+	}
+	for i := range results.SuccessfulTasks {
+		records = append(records, newTaskRecord(auction, &results.SuccessfulTasks[i], SucceededResult))
+	}
+
+	// explanations are in the same order as the failed LRPs followed by the
+	// failed tasks
+	for i := range results.FailedLRPs {
+		record := newLRPRecord(auction, &results.FailedLRPs[i], FailedResult)
+		record.CellRejections = explanations[i].CellRejections
+		records = append(records, record)
+	}
+	for i := range results.FailedTasks {
+		record := newTaskRecord(auction, &results.FailedTasks[i], FailedResult)
+		record.CellRejections = explanations[len(results.FailedLRPs)+i].CellRejections
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func newLRPRecord(auction auctionrunnerdelegate.CompletedAuction, lrp *auctiontypes.LRPAuction, result string) Record {
+	record := newRecord(auction, lrp.AuctionRecord, LRPWorkType, result)
+	record.ProcessGuid = lrp.ProcessGuid
+	record.Index = lrp.Index
+	record.Domain = lrp.Domain
+	record.Resource = lrp.Resource
+	record.PlacementConstraint = lrp.PlacementConstraint
+	record.CandidateCount = candidateCount(auction, lrp.PlacementConstraint)
+	return record
+}
+
+func newTaskRecord(auction auctionrunnerdelegate.CompletedAuction, task *auctiontypes.TaskAuction, result string) Record {
+	record := newRecord(auction, task.AuctionRecord, TaskWorkType, result)
+	record.TaskGuid = task.TaskGuid
+	record.Domain = task.Domain
+	record.Resource = task.Resource
+	record.PlacementConstraint = task.PlacementConstraint
+	record.CandidateCount = candidateCount(auction, task.PlacementConstraint)
+	return record
+}
+
+func newRecord(auction auctionrunnerdelegate.CompletedAuction, auctionRecord auctiontypes.AuctionRecord, workType, result string) Record {
+	record := Record{
+		Timestamp:        auction.CompletedAt,
+		Type:             workType,
+		Result:           result,
+		Winner:           auctionRecord.Winner,
+		Attempts:         auctionRecord.Attempts,
+		PlacementError:   auctionRecord.PlacementError,
+		QueueTime:        auctionRecord.QueueTime,
+		AuctionStartedAt: auction.StartedAt,
+		AuctionDuration:  auction.CompletedAt.Sub(auction.StartedAt),
+	}
+
+	if !auctionRecord.QueueTime.IsZero() {
+		record.WaitDuration = auction.CompletedAt.Sub(auctionRecord.QueueTime)
+	}
+
+	return record
+}
+
+// candidateCount returns how many of the cells that reported a state during the
+// auction satisfy the placement constraint, whatever their free resources.
+func candidateCount(auction auctionrunnerdelegate.CompletedAuction, pc rep.PlacementConstraint) int {
+	count := 0
+	for cellID := range auction.CellStates {
+		state := auction.CellStates[cellID]
+		if len(placementexplainer.ConstraintRejectionReasons(&state, pc)) == 0 {
+			count++
+		}
+	}
+	return count
+}
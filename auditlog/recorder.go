@@ -0,0 +1,34 @@
+package auditlog
+
+import (
+	"code.cloudfoundry.org/auctioneer/auctionrunnerdelegate"
+	"code.cloudfoundry.org/auctioneer/placementexplainer"
+	"code.cloudfoundry.org/lager"
+)
+
+// Recorder writes an audit record for every work item of every completed
+// auction to its sink. It observes the placementexplainer.Explainer, so that
+// the cell rejections of failed work are only computed once per auction.
+type Recorder struct {
+	sink   Sink
+	logger lager.Logger
+}
+
+func NewRecorder(logger lager.Logger, sink Sink) *Recorder {
+	return &Recorder{
+		sink:   sink,
+		logger: logger.Session("audit-log"),
+	}
+}
+
+func (r *Recorder) AuctionExplained(auction auctionrunnerdelegate.CompletedAuction, explanations []placementexplainer.Explanation) {
+	records := NewRecords(auction, explanations)
+	if len(records) == 0 {
+		return
+	}
+
+	err := r.sink.Write(records)
+	if err != nil {
+		r.logger.Error("failed-to-write-records", err, lager.Data{"num-records": len(records)})
+	}
+}
@@ -0,0 +1,155 @@
+package auditlog_test
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auctioneer/auctionrunnerdelegate"
+	"code.cloudfoundry.org/auctioneer/auditlog"
+	"code.cloudfoundry.org/auctioneer/placementexplainer"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Recorder", func() {
+	var (
+		logger   *lagertest.TestLogger
+		sink     *fakeSink
+		recorder *auditlog.Recorder
+		auction  auctionrunnerdelegate.CompletedAuction
+
+		queueTime time.Time
+		startedAt time.Time
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		sink = &fakeSink{}
+		recorder = auditlog.NewRecorder(logger, sink)
+
+		queueTime = time.Unix(1000, 0)
+		startedAt = queueTime.Add(2 * time.Second)
+
+		resource := rep.NewResource(10, 20, 30)
+		pc := rep.NewPlacementConstraint("preloaded:linux", []string{"tag"}, []string{})
+
+		auction = auctionrunnerdelegate.CompletedAuction{
+			Results: auctiontypes.AuctionResults{
+				SuccessfulLRPs: []auctiontypes.LRPAuction{{
+					LRP:           rep.NewLRP("ig-1", models.NewActualLRPKey("process-guid", 1, "lrp-domain"), resource, pc),
+					AuctionRecord: auctiontypes.AuctionRecord{Winner: "cell-a", Attempts: 1, QueueTime: queueTime},
+				}},
+				FailedTasks: []auctiontypes.TaskAuction{{
+					Task:          rep.NewTask("task-guid", "task-domain", resource, pc),
+					AuctionRecord: auctiontypes.AuctionRecord{Attempts: 3, QueueTime: queueTime, PlacementError: "insufficient resources"},
+				}},
+			},
+			CellCount: 4,
+			CellStates: map[string]rep.CellState{
+				"cell-b": {
+					RootFSProviders:    rep.RootFSProviders{"preloaded": rep.ArbitraryRootFSProvider{}},
+					AvailableResources: rep.NewResources(5, 100, 10),
+					PlacementTags:      []string{"tag"},
+				},
+				"cell-c": {
+					RootFSProviders:    rep.RootFSProviders{"preloaded": rep.ArbitraryRootFSProvider{}},
+					AvailableResources: rep.NewResources(100, 100, 10),
+				},
+			},
+			StartedAt:   startedAt,
+			CompletedAt: startedAt.Add(time.Second),
+		}
+	})
+
+	It("writes a record for every work item", func() {
+		recorder.AuctionExplained(auction, placementexplainer.Explain(auction))
+
+		Expect(sink.records).To(HaveLen(2))
+
+		lrpRecord := sink.records[0]
+		Expect(lrpRecord.Type).To(Equal(auditlog.LRPWorkType))
+		Expect(lrpRecord.Result).To(Equal(auditlog.SucceededResult))
+		Expect(lrpRecord.ProcessGuid).To(Equal("process-guid"))
+		Expect(lrpRecord.Index).To(BeEquivalentTo(1))
+		Expect(lrpRecord.Domain).To(Equal("lrp-domain"))
+		Expect(lrpRecord.Winner).To(Equal("cell-a"))
+		Expect(lrpRecord.CandidateCount).To(Equal(1))
+		Expect(lrpRecord.Resource).To(Equal(rep.NewResource(10, 20, 30)))
+
+		taskRecord := sink.records[1]
+		Expect(taskRecord.Type).To(Equal(auditlog.TaskWorkType))
+		Expect(taskRecord.Result).To(Equal(auditlog.FailedResult))
+		Expect(taskRecord.TaskGuid).To(Equal("task-guid"))
+		Expect(taskRecord.Domain).To(Equal("task-domain"))
+		Expect(taskRecord.Winner).To(BeEmpty())
+		Expect(taskRecord.Attempts).To(Equal(3))
+		Expect(taskRecord.PlacementError).To(Equal("insufficient resources"))
+		Expect(taskRecord.PlacementConstraint.PlacementTags).To(ConsistOf("tag"))
+		Expect(taskRecord.CandidateCount).To(Equal(1))
+		Expect(taskRecord.CellRejections).To(Equal(map[string][]string{
+			"cell-b": {placementexplainer.ReasonInsufficientMemory},
+			"cell-c": {placementexplainer.ReasonPlacementTagMismatch},
+		}))
+	})
+
+	It("records the auction timings", func() {
+		recorder.AuctionExplained(auction, placementexplainer.Explain(auction))
+
+		record := sink.records[0]
+		Expect(record.Timestamp).To(Equal(startedAt.Add(time.Second)))
+		Expect(record.QueueTime).To(Equal(queueTime))
+		Expect(record.AuctionStartedAt).To(Equal(startedAt))
+		Expect(record.WaitDuration).To(Equal(3 * time.Second))
+		Expect(record.AuctionDuration).To(Equal(time.Second))
+	})
+
+	It("records the index of the first LRP instance", func() {
+		auction.Results.SuccessfulLRPs[0].Index = 0
+		recorder.AuctionExplained(auction, placementexplainer.Explain(auction))
+
+		line, err := json.Marshal(sink.records[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(line)).To(ContainSubstring(`"index":0`))
+	})
+
+	Context("when the auction had no work", func() {
+		It("does not write to the sink", func() {
+			recorder.AuctionExplained(auctionrunnerdelegate.CompletedAuction{}, nil)
+			Expect(sink.writes).To(Equal(0))
+		})
+	})
+
+	Context("when writing to the sink fails", func() {
+		BeforeEach(func() {
+			sink.err = errors.New("disk full")
+		})
+
+		It("logs the error", func() {
+			recorder.AuctionExplained(auction, placementexplainer.Explain(auction))
+			Expect(logger).To(gbytes.Say("test.audit-log.failed-to-write-records"))
+		})
+	})
+})
+
+type fakeSink struct {
+	lock    sync.Mutex
+	writes  int
+	records []auditlog.Record
+	err     error
+}
+
+func (s *fakeSink) Write(records []auditlog.Record) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.writes++
+	s.records = append(s.records, records...)
+	return s.err
+}
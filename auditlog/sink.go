@@ -0,0 +1,220 @@
+package auditlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"code.cloudfoundry.org/lager"
+)
+
+const (
+	DefaultMaxSizeBytes = 100 * 1024 * 1024
+	DefaultMaxBackups   = 5
+	DefaultQueueSize    = 100
+)
+
+var errQueueFull = errors.New("queue is full")
+
+// Sink persists audit records. Implementations must be safe for concurrent
+// use.
+type Sink interface {
+	Write(records []Record) error
+}
+
+// FileSink appends records as JSON lines to a file. Once the file would grow
+// beyond maxSizeBytes it is rotated to <path>.1, shifting older backups up to
+// <path>.<maxBackups>.
+//
+// Write only queues the records; Run writes them through a buffer so that the
+// file system never holds up the auction. Once signalled, Run writes whatever
+// is still queued and closes the file.
+type FileSink struct {
+	logger       lager.Logger
+	path         string
+	maxSizeBytes int64
+	maxBackups   int
+	batches      chan []Record
+
+	lock   sync.Mutex
+	closed bool
+
+	file   *os.File
+	writer *bufio.Writer
+	size   int64
+}
+
+func NewFileSink(logger lager.Logger, path string, maxSizeBytes int64, maxBackups int) (*FileSink, error) {
+	if maxSizeBytes <= 0 {
+		maxSizeBytes = DefaultMaxSizeBytes
+	}
+	if maxBackups < 0 {
+		maxBackups = 0
+	}
+
+	sink := &FileSink{
+		logger:       logger.Session("audit-log-file"),
+		path:         path,
+		maxSizeBytes: maxSizeBytes,
+		maxBackups:   maxBackups,
+		batches:      make(chan []Record, DefaultQueueSize),
+	}
+
+	if err := sink.open(); err != nil {
+		return nil, err
+	}
+
+	return sink, nil
+}
+
+func (s *FileSink) Write(records []Record) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return os.ErrClosed
+	}
+
+	select {
+	case s.batches <- records:
+		return nil
+	default:
+		return errQueueFull
+	}
+}
+
+func (s *FileSink) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	close(ready)
+
+	for {
+		select {
+		case records := <-s.batches:
+			s.write(records)
+			if len(s.batches) == 0 {
+				s.flush()
+			}
+
+		case <-signals:
+			return s.close()
+		}
+	}
+}
+
+// close stops accepting records, writes those still queued and closes the
+// file.
+func (s *FileSink) close() error {
+	s.lock.Lock()
+	s.closed = true
+	s.lock.Unlock()
+
+	// Write only queues records while holding the lock, so nothing is added
+	// to the queue from here on.
+	for len(s.batches) > 0 {
+		s.write(<-s.batches)
+	}
+
+	s.flush()
+	if s.file == nil {
+		return nil
+	}
+
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+func (s *FileSink) write(records []Record) {
+	if s.file == nil {
+		s.logger.Error("failed-to-write-records", os.ErrClosed, lager.Data{"num-records": len(records)})
+		return
+	}
+
+	for i := range records {
+		line, err := json.Marshal(&records[i])
+		if err != nil {
+			s.logger.Error("failed-to-marshal-record", err)
+			continue
+		}
+		line = append(line, '\n')
+
+		if s.size > 0 && s.size+int64(len(line)) > s.maxSizeBytes {
+			if err := s.rotate(); err != nil {
+				s.logger.Error("failed-to-rotate", err, lager.Data{"num-records": len(records) - i})
+				return
+			}
+		}
+
+		n, err := s.writer.Write(line)
+		s.size += int64(n)
+		if err != nil {
+			s.logger.Error("failed-to-write-records", err, lager.Data{"num-records": len(records) - i})
+			return
+		}
+	}
+}
+
+func (s *FileSink) flush() {
+	if s.writer == nil {
+		return
+	}
+
+	if err := s.writer.Flush(); err != nil {
+		s.logger.Error("failed-to-flush", err)
+	}
+}
+
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0640)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	s.file = file
+	s.writer = bufio.NewWriter(file)
+	s.size = info.Size()
+	return nil
+}
+
+func (s *FileSink) rotate() error {
+	if err := s.writer.Flush(); err != nil {
+		return err
+	}
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	s.file = nil
+	s.writer = nil
+
+	if s.maxBackups == 0 {
+		if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return s.open()
+	}
+
+	for i := s.maxBackups - 1; i > 0; i-- {
+		err := os.Rename(s.backupPath(i), s.backupPath(i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if err := os.Rename(s.path, s.backupPath(1)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return s.open()
+}
+
+func (s *FileSink) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", s.path, n)
+}
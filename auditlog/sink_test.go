@@ -0,0 +1,155 @@
+package auditlog_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/auctioneer/auditlog"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileSink", func() {
+	var (
+		logger  *lagertest.TestLogger
+		tmpDir  string
+		logPath string
+		sink    *auditlog.FileSink
+		process ifrit.Process
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "auditlog")
+		Expect(err).NotTo(HaveOccurred())
+		logPath = filepath.Join(tmpDir, "auctions.jsonl")
+		process = nil
+	})
+
+	AfterEach(func() {
+		if process != nil {
+			ginkgomon.Interrupt(process)
+		}
+		Expect(os.RemoveAll(tmpDir)).To(Succeed())
+	})
+
+	readRecords := func(path string) []auditlog.Record {
+		file, err := os.Open(path)
+		Expect(err).NotTo(HaveOccurred())
+		defer file.Close()
+
+		records := []auditlog.Record{}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			record := auditlog.Record{}
+			Expect(json.Unmarshal(scanner.Bytes(), &record)).To(Succeed())
+			records = append(records, record)
+		}
+		Expect(scanner.Err()).NotTo(HaveOccurred())
+		return records
+	}
+
+	guids := func(records []auditlog.Record) []string {
+		result := []string{}
+		for _, record := range records {
+			result = append(result, record.TaskGuid)
+		}
+		return result
+	}
+
+	It("appends each record as a JSON line", func() {
+		var err error
+		sink, err = auditlog.NewFileSink(logger, logPath, 0, 0)
+		Expect(err).NotTo(HaveOccurred())
+		process = ginkgomon.Invoke(sink)
+
+		Expect(sink.Write([]auditlog.Record{{TaskGuid: "task-1"}, {TaskGuid: "task-2"}})).To(Succeed())
+		Expect(sink.Write([]auditlog.Record{{TaskGuid: "task-3"}})).To(Succeed())
+
+		Eventually(func() []string {
+			return guids(readRecords(logPath))
+		}).Should(Equal([]string{"task-1", "task-2", "task-3"}))
+	})
+
+	Context("when the file grows beyond the maximum size", func() {
+		var recordSize int64
+
+		BeforeEach(func() {
+			line, err := json.Marshal(auditlog.Record{TaskGuid: "task-0"})
+			Expect(err).NotTo(HaveOccurred())
+			recordSize = int64(len(line) + 1)
+
+			sink, err = auditlog.NewFileSink(logger, logPath, 2*recordSize, 2)
+			Expect(err).NotTo(HaveOccurred())
+			process = ginkgomon.Invoke(sink)
+
+			for _, guid := range []string{"task-1", "task-2", "task-3", "task-4", "task-5", "task-6", "task-7"} {
+				Expect(sink.Write([]auditlog.Record{{TaskGuid: guid}})).To(Succeed())
+			}
+			ginkgomon.Interrupt(process)
+		})
+
+		It("rotates the file and keeps the configured number of backups", func() {
+			Expect(readRecords(logPath)).To(ConsistOf(auditlog.Record{TaskGuid: "task-7"}))
+			Expect(readRecords(logPath + ".1")).To(ConsistOf(auditlog.Record{TaskGuid: "task-5"}, auditlog.Record{TaskGuid: "task-6"}))
+			Expect(readRecords(logPath + ".2")).To(ConsistOf(auditlog.Record{TaskGuid: "task-3"}, auditlog.Record{TaskGuid: "task-4"}))
+			Expect(logPath + ".3").NotTo(BeAnExistingFile())
+		})
+	})
+
+	Context("when it is signalled", func() {
+		BeforeEach(func() {
+			var err error
+			sink, err = auditlog.NewFileSink(logger, logPath, 0, 0)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("writes the queued records before closing the file", func() {
+			Expect(sink.Write([]auditlog.Record{{TaskGuid: "task-1"}})).To(Succeed())
+			Expect(sink.Write([]auditlog.Record{{TaskGuid: "task-2"}})).To(Succeed())
+
+			process = ginkgomon.Invoke(sink)
+			ginkgomon.Interrupt(process)
+
+			Expect(guids(readRecords(logPath))).To(Equal([]string{"task-1", "task-2"}))
+		})
+
+		It("refuses further records", func() {
+			process = ginkgomon.Invoke(sink)
+			ginkgomon.Interrupt(process)
+
+			Expect(sink.Write([]auditlog.Record{{TaskGuid: "task-1"}})).To(MatchError(os.ErrClosed))
+		})
+	})
+
+	Context("when the queue is full", func() {
+		It("returns an error rather than blocking", func() {
+			var err error
+			sink, err = auditlog.NewFileSink(logger, logPath, 0, 0)
+			Expect(err).NotTo(HaveOccurred())
+
+			for i := 0; i < auditlog.DefaultQueueSize; i++ {
+				Expect(sink.Write([]auditlog.Record{{TaskGuid: "task"}})).To(Succeed())
+			}
+			Expect(sink.Write([]auditlog.Record{{TaskGuid: "task"}})).To(HaveOccurred())
+
+			process = ginkgomon.Invoke(sink)
+		})
+	})
+
+	Context("when the file cannot be opened", func() {
+		It("returns an error", func() {
+			_, err := auditlog.NewFileSink(logger, filepath.Join(tmpDir, "missing", "auctions.jsonl"), 0, 0)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package capacitymetrics_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestCapacitymetrics(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Capacity Metrics Suite")
+}
@@ -0,0 +1,155 @@
+package capacitymetrics
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"code.cloudfoundry.org/auctioneer/metrics"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+const (
+	CellCount             = "AuctioneerCapacityCells"
+	TotalMemory           = "AuctioneerCapacityTotalMemory"
+	RemainingMemory       = "AuctioneerCapacityRemainingMemory"
+	TotalDisk             = "AuctioneerCapacityTotalDisk"
+	RemainingDisk         = "AuctioneerCapacityRemainingDisk"
+	TotalContainers       = "AuctioneerCapacityTotalContainers"
+	RemainingContainers   = "AuctioneerCapacityRemainingContainers"
+	LargestFreeSlotMemory = "AuctioneerCapacityLargestFreeSlotMemory"
+	LargestFreeSlotDisk   = "AuctioneerCapacityLargestFreeSlotDisk"
+	ZoneTag               = "zone"
+	PlacementTagsTag      = "placement_tags"
+)
+
+// CellStateProvider returns the most recently reported state of each cell.
+type CellStateProvider interface {
+	CellStates() map[string]rep.CellState
+}
+
+// Notifier periodically summarizes the last known cell states and emits the
+// capacity of the cluster, grouped by zone and placement tags.
+type Notifier struct {
+	logger     lager.Logger
+	ticker     clock.Ticker
+	cellStates CellStateProvider
+	sink       metrics.Sink
+}
+
+func NewNotifier(logger lager.Logger, ticker clock.Ticker, cellStates CellStateProvider, sink metrics.Sink) *Notifier {
+	return &Notifier{
+		logger:     logger,
+		ticker:     ticker,
+		cellStates: cellStates,
+		sink:       sink,
+	}
+}
+
+func (n *Notifier) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := n.logger.Session("capacity-metrics-notifier")
+	close(ready)
+
+	logger.Info("started")
+	defer logger.Info("finished")
+
+	for {
+		select {
+		case <-n.ticker.C():
+			n.emit(logger)
+
+		case <-signals:
+			n.ticker.Stop()
+			return nil
+		}
+	}
+}
+
+type group struct {
+	zone          string
+	placementTags string
+}
+
+// capacity is the summary of the cells in a group. Evacuating cells count
+// towards the totals but not towards the remaining capacity, since no work
+// can be placed on them.
+type capacity struct {
+	cells                 int
+	total                 rep.Resources
+	remaining             rep.Resources
+	largestFreeSlotMemory int32
+	largestFreeSlotDisk   int32
+}
+
+func (n *Notifier) emit(logger lager.Logger) {
+	states := n.cellStates.CellStates()
+	if len(states) == 0 {
+		return
+	}
+
+	groups := summarize(states)
+	for g, c := range groups {
+		tags := map[string]string{
+			ZoneTag:          g.zone,
+			PlacementTagsTag: g.placementTags,
+		}
+
+		n.send(logger, n.sink.SendGauge(CellCount, float64(c.cells), metrics.MetricUnit, tags))
+		n.send(logger, n.sink.SendGauge(TotalMemory, float64(c.total.MemoryMB), metrics.MebiBytesUnit, tags))
+		n.send(logger, n.sink.SendGauge(RemainingMemory, float64(c.remaining.MemoryMB), metrics.MebiBytesUnit, tags))
+		n.send(logger, n.sink.SendGauge(TotalDisk, float64(c.total.DiskMB), metrics.MebiBytesUnit, tags))
+		n.send(logger, n.sink.SendGauge(RemainingDisk, float64(c.remaining.DiskMB), metrics.MebiBytesUnit, tags))
+		n.send(logger, n.sink.SendGauge(TotalContainers, float64(c.total.Containers), metrics.MetricUnit, tags))
+		n.send(logger, n.sink.SendGauge(RemainingContainers, float64(c.remaining.Containers), metrics.MetricUnit, tags))
+		n.send(logger, n.sink.SendGauge(LargestFreeSlotMemory, float64(c.largestFreeSlotMemory), metrics.MebiBytesUnit, tags))
+		n.send(logger, n.sink.SendGauge(LargestFreeSlotDisk, float64(c.largestFreeSlotDisk), metrics.MebiBytesUnit, tags))
+	}
+}
+
+func (n *Notifier) send(logger lager.Logger, err error) {
+	if err != nil {
+		logger.Error("failed-to-send-capacity-metric", err)
+	}
+}
+
+// summarize groups the cells by zone and by their sorted, comma separated
+// placement tags. The largest free slot is the free memory and disk of the
+// cell with the most free memory that can still run a container.
+func summarize(states map[string]rep.CellState) map[group]*capacity {
+	groups := map[group]*capacity{}
+
+	for _, state := range states {
+		placementTags := append([]string{}, state.PlacementTags...)
+		sort.Strings(placementTags)
+		g := group{zone: state.Zone, placementTags: strings.Join(placementTags, ",")}
+
+		c, ok := groups[g]
+		if !ok {
+			c = &capacity{}
+			groups[g] = c
+		}
+
+		c.cells++
+		c.total.MemoryMB += state.TotalResources.MemoryMB
+		c.total.DiskMB += state.TotalResources.DiskMB
+		c.total.Containers += state.TotalResources.Containers
+
+		if state.Evacuating {
+			continue
+		}
+
+		available := state.AvailableResources
+		c.remaining.MemoryMB += available.MemoryMB
+		c.remaining.DiskMB += available.DiskMB
+		c.remaining.Containers += available.Containers
+
+		if available.Containers > 0 && available.MemoryMB > c.largestFreeSlotMemory {
+			c.largestFreeSlotMemory = available.MemoryMB
+			c.largestFreeSlotDisk = available.DiskMB
+		}
+	}
+
+	return groups
+}
@@ -0,0 +1,136 @@
+package capacitymetrics_test
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/capacitymetrics"
+	"code.cloudfoundry.org/auctioneer/metrics"
+	"code.cloudfoundry.org/auctioneer/metrics/metricsfakes"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Notifier", func() {
+	const interval = 10 * time.Second
+
+	var (
+		fakeClock *fakeclock.FakeClock
+		fakeSink  *metricsfakes.FakeSink
+		provider  *fakeCellStateProvider
+		process   ifrit.Process
+	)
+
+	BeforeEach(func() {
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		fakeSink = new(metricsfakes.FakeSink)
+		provider = &fakeCellStateProvider{}
+	})
+
+	JustBeforeEach(func() {
+		notifier := capacitymetrics.NewNotifier(lagertest.NewTestLogger("test"), fakeClock.NewTicker(interval), provider, fakeSink)
+		process = ginkgomon.Invoke(notifier)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+	})
+
+	// sentGauges returns the value and unit of each gauge keyed by
+	// <name>|<zone>|<placement tags>.
+	sentGauges := func() (map[string]int, map[string]string) {
+		gauges := map[string]int{}
+		units := map[string]string{}
+		for i := 0; i < fakeSink.SendGaugeCallCount(); i++ {
+			name, value, unit, tags := fakeSink.SendGaugeArgsForCall(i)
+			key := name + "|" + tags["zone"] + "|" + tags["placement_tags"]
+			gauges[key] = int(value)
+			units[key] = unit
+		}
+		return gauges, units
+	}
+
+	Context("when cell states are known", func() {
+		BeforeEach(func() {
+			provider.states = map[string]rep.CellState{
+				"cell-1": {
+					Zone:               "z1",
+					TotalResources:     rep.NewResources(1024, 2048, 10),
+					AvailableResources: rep.NewResources(512, 1024, 5),
+				},
+				"cell-2": {
+					Zone:               "z1",
+					TotalResources:     rep.NewResources(1024, 2048, 10),
+					AvailableResources: rep.NewResources(768, 512, 2),
+				},
+				"cell-3": {
+					Zone:               "z1",
+					Evacuating:         true,
+					TotalResources:     rep.NewResources(1024, 2048, 10),
+					AvailableResources: rep.NewResources(1024, 2048, 10),
+				},
+				"cell-4": {
+					Zone:               "z2",
+					PlacementTags:      []string{"b", "a"},
+					TotalResources:     rep.NewResources(2048, 4096, 20),
+					AvailableResources: rep.NewResources(2048, 4096, 0),
+				},
+			}
+		})
+
+		It("emits capacity gauges per zone and placement tags on every tick", func() {
+			Consistently(fakeSink.SendGaugeCallCount).Should(Equal(0))
+
+			fakeClock.WaitForWatcherAndIncrement(interval)
+
+			Eventually(fakeSink.SendGaugeCallCount).Should(Equal(18))
+
+			gauges, units := sentGauges()
+			Expect(units).To(HaveKeyWithValue("AuctioneerCapacityCells|z1|", metrics.MetricUnit))
+			Expect(units).To(HaveKeyWithValue("AuctioneerCapacityTotalMemory|z1|", metrics.MebiBytesUnit))
+			Expect(gauges).To(HaveKeyWithValue("AuctioneerCapacityCells|z1|", 3))
+			Expect(gauges).To(HaveKeyWithValue("AuctioneerCapacityTotalMemory|z1|", 3072))
+			Expect(gauges).To(HaveKeyWithValue("AuctioneerCapacityRemainingMemory|z1|", 1280))
+			Expect(gauges).To(HaveKeyWithValue("AuctioneerCapacityTotalDisk|z1|", 6144))
+			Expect(gauges).To(HaveKeyWithValue("AuctioneerCapacityRemainingDisk|z1|", 1536))
+			Expect(gauges).To(HaveKeyWithValue("AuctioneerCapacityTotalContainers|z1|", 30))
+			Expect(gauges).To(HaveKeyWithValue("AuctioneerCapacityRemainingContainers|z1|", 7))
+			Expect(gauges).To(HaveKeyWithValue("AuctioneerCapacityLargestFreeSlotMemory|z1|", 768))
+			Expect(gauges).To(HaveKeyWithValue("AuctioneerCapacityLargestFreeSlotDisk|z1|", 512))
+
+			Expect(gauges).To(HaveKeyWithValue("AuctioneerCapacityCells|z2|a,b", 1))
+			Expect(gauges).To(HaveKeyWithValue("AuctioneerCapacityRemainingMemory|z2|a,b", 2048))
+			Expect(gauges).To(HaveKeyWithValue("AuctioneerCapacityLargestFreeSlotMemory|z2|a,b", 0))
+
+			fakeClock.WaitForWatcherAndIncrement(interval)
+			Eventually(fakeSink.SendGaugeCallCount).Should(Equal(36))
+		})
+	})
+
+	Context("when no cell states are known", func() {
+		It("does not emit anything", func() {
+			fakeClock.WaitForWatcherAndIncrement(interval)
+
+			Consistently(fakeSink.SendGaugeCallCount).Should(Equal(0))
+		})
+	})
+
+	It("exits when signalled", func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+	})
+})
+
+type fakeCellStateProvider struct {
+	states map[string]rep.CellState
+}
+
+func (f *fakeCellStateProvider) CellStates() map[string]rep.CellState {
+	return f.states
+}
@@ -0,0 +1 @@
+package capacitymetrics // import "code.cloudfoundry.org/auctioneer/capacitymetrics"
@@ -0,0 +1 @@
+package certs // import "code.cloudfoundry.org/auctioneer/certs"
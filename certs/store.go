@@ -0,0 +1,208 @@
+package certs
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/tlsconfig"
+)
+
+var errNoPeerCertificates = errors.New("peer presented no certificates")
+
+// Store holds a certificate, its key and a CA pool read from files. Reload
+// reads the files again, so TLS configurations built from the store pick up
+// rotated certificates without a restart.
+type Store struct {
+	name                      string
+	certFile, keyFile, caFile string
+
+	lock        sync.RWMutex
+	contents    []byte
+	certificate *tls.Certificate
+	notAfter    time.Time
+	pool        *x509.CertPool
+	onReload    []func()
+}
+
+// NewStore reads the files, failing if they do not hold a valid key pair and
+// CA. The name identifies the store in logs and metrics.
+func NewStore(name, certFile, keyFile, caFile string) (*Store, error) {
+	s := &Store{
+		name:     name,
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+	}
+
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) Name() string {
+	return s.name
+}
+
+// Files returns the certificate, key and CA files the store reads.
+func (s *Store) Files() (certFile, keyFile, caFile string) {
+	return s.certFile, s.keyFile, s.caFile
+}
+
+// Reload reads the files and, if their contents changed, replaces the
+// certificate and CA pool and calls the functions registered with OnReload.
+// If the files cannot be parsed, for example because only some of them have
+// been replaced so far, the current certificate is kept and the error is
+// returned.
+func (s *Store) Reload() (bool, error) {
+	contents := []byte{}
+	for _, file := range []string{s.certFile, s.keyFile, s.caFile} {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return false, err
+		}
+		contents = append(contents, data...)
+	}
+
+	s.lock.RLock()
+	unchanged := bytes.Equal(contents, s.contents)
+	s.lock.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to load keypair: %s", err)
+	}
+
+	leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		return false, fmt.Errorf("failed to parse certificate: %s", err)
+	}
+	certificate.Leaf = leaf
+
+	caPEM, err := ioutil.ReadFile(s.caFile)
+	if err != nil {
+		return false, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return false, fmt.Errorf("no certificates found in %s", s.caFile)
+	}
+
+	s.lock.Lock()
+	s.contents = contents
+	s.certificate = &certificate
+	s.notAfter = leaf.NotAfter
+	s.pool = pool
+	onReload := s.onReload
+	s.lock.Unlock()
+
+	for _, f := range onReload {
+		f()
+	}
+
+	return true, nil
+}
+
+// OnReload registers f to be called after each reload that changed the
+// certificates, for clients that cannot use the store's TLS configurations
+// and have to be rebuilt instead.
+func (s *Store) OnReload(f func()) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.onReload = append(s.onReload, f)
+}
+
+func (s *Store) Certificate() *tls.Certificate {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.certificate
+}
+
+// NotAfter returns when the current certificate expires.
+func (s *Store) NotAfter() time.Time {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.notAfter
+}
+
+func (s *Store) Pool() *x509.CertPool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.pool
+}
+
+// ServerConfig returns a TLS configuration for a server that requires client
+// certificates signed by the CA. Each handshake uses the current certificate
+// and CA.
+func (s *Store) ServerConfig() (*tls.Config, error) {
+	base, err := tlsconfig.Build(tlsconfig.WithInternalServiceDefaults()).Server()
+	if err != nil {
+		return nil, err
+	}
+	base.ClientAuth = tls.RequireAndVerifyClientCert
+
+	config := base.Clone()
+	config.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return s.Certificate(), nil
+	}
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		handshake := base.Clone()
+		handshake.Certificates = []tls.Certificate{*s.Certificate()}
+		handshake.ClientCAs = s.Pool()
+		return handshake, nil
+	}
+	return config, nil
+}
+
+// ClientConfig returns a TLS configuration for a client that presents the
+// current certificate and verifies servers against the current CA.
+//
+// The standard verification is replaced by VerifyConnection, since RootCAs
+// cannot be changed once connections have been made with the configuration.
+// VerifyConnection performs the same checks and also runs for resumed
+// sessions.
+func (s *Store) ClientConfig() (*tls.Config, error) {
+	config, err := tlsconfig.Build(tlsconfig.WithInternalServiceDefaults()).Client()
+	if err != nil {
+		return nil, err
+	}
+
+	config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		return s.Certificate(), nil
+	}
+	config.InsecureSkipVerify = true
+	config.VerifyConnection = s.verifyServer
+	return config, nil
+}
+
+func (s *Store) verifyServer(state tls.ConnectionState) error {
+	if len(state.PeerCertificates) == 0 {
+		return errNoPeerCertificates
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, certificate := range state.PeerCertificates[1:] {
+		intermediates.AddCert(certificate)
+	}
+
+	_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       state.ServerName,
+		Roots:         s.Pool(),
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	return err
+}
@@ -0,0 +1,197 @@
+package certs_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+
+	"code.cloudfoundry.org/auctioneer/certs"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var fixturesPath = path.Join(os.Getenv("GOPATH"), "src/code.cloudfoundry.org/auctioneer/cmd/auctioneer/fixtures")
+
+// install copies the named certificate and key, and the CA, from a fixture
+// directory to the given paths.
+func install(fixture, name, certFile, keyFile, caFile string) {
+	copyFile(path.Join(fixturesPath, fixture, name+".crt"), certFile)
+	copyFile(path.Join(fixturesPath, fixture, name+".key"), keyFile)
+	copyFile(path.Join(fixturesPath, fixture, "ca.crt"), caFile)
+}
+
+func copyFile(from, to string) {
+	data, err := ioutil.ReadFile(from)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(ioutil.WriteFile(to, data, 0600)).To(Succeed())
+}
+
+func leaf(certFile string) *x509.Certificate {
+	data, err := ioutil.ReadFile(certFile)
+	Expect(err).NotTo(HaveOccurred())
+	block, _ := pem.Decode(data)
+	Expect(block).NotTo(BeNil())
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	Expect(err).NotTo(HaveOccurred())
+	return certificate
+}
+
+var _ = Describe("Store", func() {
+	var (
+		dir                       string
+		certFile, keyFile, caFile string
+		store                     *certs.Store
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "certs")
+		Expect(err).NotTo(HaveOccurred())
+
+		certFile = filepath.Join(dir, "server.crt")
+		keyFile = filepath.Join(dir, "server.key")
+		caFile = filepath.Join(dir, "ca.crt")
+		install("green-certs", "server", certFile, keyFile, caFile)
+
+		store, err = certs.NewStore("server", certFile, keyFile, caFile)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("loads the certificate", func() {
+		green := leaf(path.Join(fixturesPath, "green-certs", "server.crt"))
+		Expect(store.Name()).To(Equal("server"))
+		Expect(store.Certificate().Leaf.Equal(green)).To(BeTrue())
+		Expect(store.NotAfter()).To(Equal(green.NotAfter))
+	})
+
+	It("fails when the files are invalid", func() {
+		_, err := certs.NewStore("server", path.Join(fixturesPath, "invalid-certs", "server.crt"), path.Join(fixturesPath, "invalid-certs", "server.key"), caFile)
+		Expect(err).To(HaveOccurred())
+	})
+
+	Describe("Reload", func() {
+		var reloads int
+
+		BeforeEach(func() {
+			reloads = 0
+			store.OnReload(func() { reloads++ })
+		})
+
+		It("does nothing when the files have not changed", func() {
+			changed, err := store.Reload()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(BeFalse())
+			Expect(reloads).To(Equal(0))
+		})
+
+		It("replaces the certificate when the files change", func() {
+			install("blue-certs", "server", certFile, keyFile, caFile)
+
+			changed, err := store.Reload()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(BeTrue())
+			Expect(reloads).To(Equal(1))
+
+			blue := leaf(path.Join(fixturesPath, "blue-certs", "server.crt"))
+			Expect(store.Certificate().Leaf.Equal(blue)).To(BeTrue())
+		})
+
+		It("keeps the current certificate while the key does not match it", func() {
+			copyFile(path.Join(fixturesPath, "blue-certs", "server.crt"), certFile)
+
+			changed, err := store.Reload()
+			Expect(err).To(MatchError(ContainSubstring("failed to load keypair")))
+			Expect(changed).To(BeFalse())
+			Expect(reloads).To(Equal(0))
+
+			green := leaf(path.Join(fixturesPath, "green-certs", "server.crt"))
+			Expect(store.Certificate().Leaf.Equal(green)).To(BeTrue())
+		})
+	})
+
+	Describe("TLS configurations", func() {
+		var (
+			server      *httptest.Server
+			clientDir   string
+			clientStore *certs.Store
+			client      *http.Client
+		)
+
+		BeforeEach(func() {
+			serverConfig, err := store.ServerConfig()
+			Expect(err).NotTo(HaveOccurred())
+
+			server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			server.TLS = serverConfig
+			server.StartTLS()
+
+			clientDir, err = ioutil.TempDir("", "client-certs")
+			Expect(err).NotTo(HaveOccurred())
+			install("green-certs", "client", filepath.Join(clientDir, "client.crt"), filepath.Join(clientDir, "client.key"), filepath.Join(clientDir, "ca.crt"))
+
+			clientStore, err = certs.NewStore("client", filepath.Join(clientDir, "client.crt"), filepath.Join(clientDir, "client.key"), filepath.Join(clientDir, "ca.crt"))
+			Expect(err).NotTo(HaveOccurred())
+
+			clientConfig, err := clientStore.ClientConfig()
+			Expect(err).NotTo(HaveOccurred())
+			client = &http.Client{Transport: &http.Transport{TLSClientConfig: clientConfig}}
+		})
+
+		AfterEach(func() {
+			server.Close()
+			os.RemoveAll(clientDir)
+		})
+
+		get := func() error {
+			resp, err := client.Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			return err
+		}
+
+		It("connects with the current certificates", func() {
+			Expect(get()).To(Succeed())
+		})
+
+		It("uses rotated certificates on both sides for new connections", func() {
+			install("blue-certs", "server", certFile, keyFile, caFile)
+			_, err := store.Reload()
+			Expect(err).NotTo(HaveOccurred())
+
+			client.Transport.(*http.Transport).CloseIdleConnections()
+			Expect(get()).NotTo(Succeed())
+
+			install("blue-certs", "client", filepath.Join(clientDir, "client.crt"), filepath.Join(clientDir, "client.key"), filepath.Join(clientDir, "ca.crt"))
+			_, err = clientStore.Reload()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(get()).To(Succeed())
+		})
+
+		It("rejects servers that are not signed by the CA", func() {
+			untrusted := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			defer untrusted.Close()
+
+			_, err := client.Get(untrusted.URL)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("requires a client certificate", func() {
+			config := &tls.Config{RootCAs: store.Pool()}
+			_, err := (&http.Client{Transport: &http.Transport{TLSClientConfig: config}}).Get(server.URL)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
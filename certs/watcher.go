@@ -0,0 +1,93 @@
+package certs
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/metrics"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+)
+
+const (
+	// CertificateTimeToExpiry is the time left, in seconds, before each
+	// certificate expires, tagged with the name of its store. It is a gauge
+	// rather than a duration because it runs to months, far past the largest
+	// duration bucket. It goes negative once the certificate has expired.
+	CertificateTimeToExpiry = "AuctioneerCertificateTimeToExpiry"
+	CertificateTag          = "certificate"
+
+	DefaultReloadInterval = time.Minute
+)
+
+// Watcher reloads the stores on every tick and emits how long each of their
+// certificates has left.
+type Watcher struct {
+	logger lager.Logger
+	clock  clock.Clock
+	ticker clock.Ticker
+	sink   metrics.Sink
+	stores []*Store
+}
+
+func NewWatcher(logger lager.Logger, clock clock.Clock, ticker clock.Ticker, sink metrics.Sink, stores ...*Store) *Watcher {
+	return &Watcher{
+		logger: logger.Session("certificate-watcher"),
+		clock:  clock,
+		ticker: ticker,
+		sink:   sink,
+		stores: stores,
+	}
+}
+
+func (w *Watcher) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	w.emit()
+	close(ready)
+
+	for {
+		select {
+		case <-w.ticker.C():
+			w.reload()
+			w.emit()
+
+		case <-signals:
+			w.ticker.Stop()
+			return nil
+		}
+	}
+}
+
+func (w *Watcher) reload() {
+	for _, store := range w.stores {
+		certFile, keyFile, caFile := store.Files()
+		logger := w.logger.Session("reload", lager.Data{
+			"certificate": store.Name(),
+			"cert-file":   certFile,
+			"key-file":    keyFile,
+			"ca-file":     caFile,
+		})
+
+		changed, err := store.Reload()
+		if err != nil {
+			logger.Error("failed-to-reload", err)
+			continue
+		}
+
+		if changed {
+			logger.Info("reloaded", lager.Data{"not-after": store.NotAfter()})
+		}
+	}
+}
+
+func (w *Watcher) emit() {
+	for _, store := range w.stores {
+		remaining := store.NotAfter().Sub(w.clock.Now())
+
+		err := w.sink.SendGauge(CertificateTimeToExpiry, remaining.Seconds(), metrics.SecondsUnit, map[string]string{
+			CertificateTag: store.Name(),
+		})
+		if err != nil {
+			w.logger.Error("failed-to-send-certificate-expiry", err, lager.Data{"certificate": store.Name()})
+		}
+	}
+}
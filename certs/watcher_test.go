@@ -0,0 +1,95 @@
+package certs_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/certs"
+	"code.cloudfoundry.org/auctioneer/metrics"
+	"code.cloudfoundry.org/auctioneer/metrics/metricsfakes"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Watcher", func() {
+	var (
+		logger    *lagertest.TestLogger
+		fakeClock *fakeclock.FakeClock
+		fakeSink  *metricsfakes.FakeSink
+		dir       string
+		certFile  string
+		keyFile   string
+		caFile    string
+		store     *certs.Store
+		process   ifrit.Process
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeSink = &metricsfakes.FakeSink{}
+
+		var err error
+		dir, err = ioutil.TempDir("", "certs")
+		Expect(err).NotTo(HaveOccurred())
+		certFile = filepath.Join(dir, "server.crt")
+		keyFile = filepath.Join(dir, "server.key")
+		caFile = filepath.Join(dir, "ca.crt")
+		install("green-certs", "server", certFile, keyFile, caFile)
+
+		store, err = certs.NewStore("server", certFile, keyFile, caFile)
+		Expect(err).NotTo(HaveOccurred())
+
+		fakeClock = fakeclock.NewFakeClock(store.NotAfter().Add(-time.Hour))
+	})
+
+	JustBeforeEach(func() {
+		watcher := certs.NewWatcher(logger, fakeClock, fakeClock.NewTicker(time.Minute), fakeSink, store)
+		process = ginkgomon.Invoke(watcher)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+		os.RemoveAll(dir)
+	})
+
+	It("emits the time left before the certificate expires", func() {
+		Expect(fakeSink.SendGaugeCallCount()).To(Equal(1))
+		name, remaining, unit, tags := fakeSink.SendGaugeArgsForCall(0)
+		Expect(name).To(Equal(certs.CertificateTimeToExpiry))
+		Expect(remaining).To(Equal(time.Hour.Seconds()))
+		Expect(unit).To(Equal(metrics.SecondsUnit))
+		Expect(tags).To(Equal(map[string]string{certs.CertificateTag: "server"}))
+	})
+
+	It("emits it again on every tick", func() {
+		fakeClock.WaitForWatcherAndIncrement(time.Minute)
+		Eventually(fakeSink.SendGaugeCallCount).Should(Equal(2))
+		_, remaining, _, _ := fakeSink.SendGaugeArgsForCall(1)
+		Expect(remaining).To(Equal((59 * time.Minute).Seconds()))
+	})
+
+	It("reloads rotated certificates on the next tick", func() {
+		install("blue-certs", "server", certFile, keyFile, caFile)
+		fakeClock.WaitForWatcherAndIncrement(time.Minute)
+
+		Eventually(logger).Should(gbytes.Say(`certificate-watcher.reload.reloaded.*"certificate":"server"`))
+		blue := leaf(path.Join(fixturesPath, "blue-certs", "server.crt"))
+		Expect(store.Certificate().Leaf.Equal(blue)).To(BeTrue())
+	})
+
+	It("logs certificates that cannot be reloaded", func() {
+		Expect(ioutil.WriteFile(keyFile, []byte("not a key"), 0600)).To(Succeed())
+		fakeClock.WaitForWatcherAndIncrement(time.Minute)
+
+		Eventually(logger).Should(gbytes.Say("certificate-watcher.reload.failed-to-reload"))
+	})
+})
@@ -2,39 +2,70 @@ package auctioneer
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
+	"code.cloudfoundry.org/auctioneer/tracing"
 	cfhttp "code.cloudfoundry.org/cfhttp/v2"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/tlsconfig"
 	"github.com/tedsuo/rata"
 )
 
+const (
+	// MaxRetryAfter is the longest Retry-After delay the client waits for
+	// before resending a throttled request. Longer delays are returned to the
+	// caller as errors.
+	MaxRetryAfter = 30 * time.Second
+
+	maxThrottledRetries = 3
+)
+
 //go:generate counterfeiter -o auctioneerfakes/fake_client.go . Client
 type Client interface {
 	RequestLRPAuctions(logger lager.Logger, lrpStart []*LRPStartRequest) error
 	RequestTaskAuctions(logger lager.Logger, tasks []*TaskStartRequest) error
 }
 
+// ContextClient is implemented by the clients returned by NewClient and
+// NewSecureClient. The trace context in ctx is sent to the auctioneer in a W3C
+// traceparent header so the auction can be traced back to the request.
+type ContextClient interface {
+	Client
+	RequestLRPAuctionsWithContext(ctx context.Context, logger lager.Logger, lrpStart []*LRPStartRequest) error
+	RequestTaskAuctionsWithContext(ctx context.Context, logger lager.Logger, tasks []*TaskStartRequest) error
+}
+
 type auctioneerClient struct {
 	httpClient         *http.Client
 	insecureHTTPClient *http.Client
 	url                string
 	requireTLS         bool
+	requestTimeout     time.Duration
 }
 
+// NewClient returns a client for the auctioneer at auctioneerURL, which is
+// either an http:// URL or a unix:// socket address.
 func NewClient(auctioneerURL string, requestTimeout time.Duration) Client {
+	httpClient := cfhttp.NewClient(
+		cfhttp.WithRequestTimeout(requestTimeout),
+	)
+
 	return &auctioneerClient{
-		httpClient: cfhttp.NewClient(
-			cfhttp.WithRequestTimeout(requestTimeout),
-		),
-		url: auctioneerURL,
+		httpClient:     httpClient,
+		url:            clientURL(httpClient, auctioneerURL, SchemeHTTP),
+		requestTimeout: requestTimeout,
 	}
 }
 
+// NewSecureClient returns a client that uses mutual TLS to reach the
+// auctioneer at auctioneerURL. When auctioneerURL is a unix:// socket address
+// TLS is used over the socket, falling back to plain HTTP unless requireTLS is
+// set.
 func NewSecureClient(auctioneerURL, caFile, certFile, keyFile string, requireTLS bool, requestTimeout time.Duration) (Client, error) {
 	insecureHTTPClient := cfhttp.NewClient(
 		cfhttp.WithRequestTimeout(requestTimeout),
@@ -53,70 +84,137 @@ func NewSecureClient(auctioneerURL, caFile, certFile, keyFile string, requireTLS
 		cfhttp.WithTLSConfig(tlsConfig),
 	)
 
+	url := clientURL(httpClient, auctioneerURL, SchemeHTTPS)
+	clientURL(insecureHTTPClient, auctioneerURL, SchemeHTTP)
+
 	return &auctioneerClient{
 		httpClient:         httpClient,
 		insecureHTTPClient: insecureHTTPClient,
-		url:                auctioneerURL,
+		url:                url,
 		requireTLS:         requireTLS,
+		requestTimeout:     requestTimeout,
 	}, nil
 }
 
 func (c *auctioneerClient) RequestLRPAuctions(logger lager.Logger, lrpStarts []*LRPStartRequest) error {
+	ctx, cancel := c.requestContext()
+	defer cancel()
+	return c.RequestLRPAuctionsWithContext(ctx, logger, lrpStarts)
+}
+
+func (c *auctioneerClient) RequestLRPAuctionsWithContext(ctx context.Context, logger lager.Logger, lrpStarts []*LRPStartRequest) error {
 	logger = logger.Session("request-lrp-auctions")
 
-	reqGen := rata.NewRequestGenerator(c.url, Routes)
 	payload, err := json.Marshal(lrpStarts)
 	if err != nil {
 		return err
 	}
 
-	req, err := reqGen.CreateRequest(CreateLRPAuctionsRoute, rata.Params{}, bytes.NewBuffer(payload))
-	if err != nil {
-		return err
-	}
+	return c.requestAuctions(ctx, logger, CreateLRPAuctionsRoute, payload)
+}
 
-	req.Header.Set("Content-Type", "application/json")
+func (c *auctioneerClient) RequestTaskAuctions(logger lager.Logger, tasks []*TaskStartRequest) error {
+	ctx, cancel := c.requestContext()
+	defer cancel()
+	return c.RequestTaskAuctionsWithContext(ctx, logger, tasks)
+}
+
+func (c *auctioneerClient) RequestTaskAuctionsWithContext(ctx context.Context, logger lager.Logger, tasks []*TaskStartRequest) error {
+	logger = logger.Session("request-task-auctions")
 
-	resp, err := c.doRequest(logger, req)
+	payload, err := json.Marshal(tasks)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusAccepted {
-		return fmt.Errorf("http error: status code %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
-	}
 
-	return nil
+	return c.requestAuctions(ctx, logger, CreateTaskAuctionsRoute, payload)
 }
 
-func (c *auctioneerClient) RequestTaskAuctions(logger lager.Logger, tasks []*TaskStartRequest) error {
-	logger = logger.Session("request-task-auctions")
+// requestContext bounds the requests made without a context: a request and
+// its throttled retries, including the waits between them, must complete
+// within the request timeout, or within MaxRetryAfter when there is none.
+func (c *auctioneerClient) requestContext() (context.Context, context.CancelFunc) {
+	timeout := c.requestTimeout
+	if timeout <= 0 {
+		timeout = MaxRetryAfter
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
 
+// requestAuctions submits the payload to route. While the auctioneer responds
+// 429, the request is sent again once the Retry-After delay has passed, up to
+// maxThrottledRetries times and as long as the delay is at most
+// MaxRetryAfter. A delay that would end after the deadline of ctx is not
+// waited for.
+func (c *auctioneerClient) requestAuctions(ctx context.Context, logger lager.Logger, route string, payload []byte) error {
 	reqGen := rata.NewRequestGenerator(c.url, Routes)
-	payload, err := json.Marshal(tasks)
-	if err != nil {
-		return err
+
+	for attempt := 0; ; attempt++ {
+		req, err := reqGen.CreateRequest(route, rata.Params{}, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		tracing.Inject(ctx, req.Header)
+
+		resp, err := c.doRequest(logger, req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusAccepted {
+			return nil
+		}
+
+		statusErr := fmt.Errorf("http error: status code %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= maxThrottledRetries {
+			return statusErr
+		}
+
+		retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !ok || retryAfter > MaxRetryAfter {
+			return statusErr
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(retryAfter).After(deadline) {
+			return statusErr
+		}
+
+		logger.Info("throttled", lager.Data{"retry-after": retryAfter.String(), "attempt": attempt + 1})
+
+		timer := time.NewTimer(retryAfter)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
 	}
+}
 
-	req, err := reqGen.CreateRequest(CreateTaskAuctionsRoute, rata.Params{}, bytes.NewBuffer(payload))
-	if err != nil {
-		return err
+// parseRetryAfter parses a Retry-After header given either as a number of
+// seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
 
-	resp, err := c.doRequest(logger, req)
+	date, err := http.ParseTime(value)
 	if err != nil {
-		return err
+		return 0, false
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusAccepted {
-		return fmt.Errorf("http error: status code %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+	if delay := date.Sub(now); delay > 0 {
+		return delay, true
 	}
-
-	return nil
+	return 0, true
 }
 
 func (c *auctioneerClient) doRequest(logger lager.Logger, req *http.Request) (*http.Response, error) {
@@ -1,6 +1,9 @@
 package auctioneer_test
 
 import (
+	"context"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"os"
 	"path"
@@ -9,10 +12,12 @@ import (
 	"code.cloudfoundry.org/auctioneer"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/tlsconfig"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/ghttp"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var _ = Describe("Auctioneer Client", func() {
@@ -48,6 +53,169 @@ var _ = Describe("Auctioneer Client", func() {
 			err := c.RequestLRPAuctions(dummyLogger, []*auctioneer.LRPStartRequest{})
 			Expect(err.Error()).To(ContainSubstring("request canceled"))
 		})
+
+		Context("when a trace context is given", func() {
+			var ctx context.Context
+
+			BeforeEach(func() {
+				traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+				Expect(err).NotTo(HaveOccurred())
+				spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+				Expect(err).NotTo(HaveOccurred())
+				ctx = trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+					TraceID:    traceID,
+					SpanID:     spanID,
+					TraceFlags: trace.FlagsSampled,
+				}))
+
+				fakeAuctioneerServer.SetHandler(0, ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"),
+					ghttp.RespondWith(http.StatusAccepted, nil),
+				))
+			})
+
+			It("propagates it in the traceparent header of task auctions", func() {
+				c := auctioneer.NewClient(fakeAuctioneerServer.URL(), 5*time.Second).(auctioneer.ContextClient)
+
+				err := c.RequestTaskAuctionsWithContext(ctx, dummyLogger, []*auctioneer.TaskStartRequest{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeAuctioneerServer.ReceivedRequests()).To(HaveLen(1))
+			})
+
+			It("propagates it in the traceparent header of LRP auctions", func() {
+				c := auctioneer.NewClient(fakeAuctioneerServer.URL(), 5*time.Second).(auctioneer.ContextClient)
+
+				err := c.RequestLRPAuctionsWithContext(ctx, dummyLogger, []*auctioneer.LRPStartRequest{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeAuctioneerServer.ReceivedRequests()).To(HaveLen(1))
+			})
+		})
+	})
+
+	Context("when the auctioneer throttles requests", func() {
+		var (
+			fakeAuctioneerServer *ghttp.Server
+			c                    auctioneer.Client
+		)
+
+		throttled := func(retryAfter string) http.HandlerFunc {
+			return ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/v1/tasks"),
+				ghttp.RespondWith(http.StatusTooManyRequests, `{"error":"too much pending work"}`, http.Header{"Retry-After": {retryAfter}}),
+			)
+		}
+
+		tasks := func() []*auctioneer.TaskStartRequest {
+			return []*auctioneer.TaskStartRequest{{Task: rep.Task{TaskGuid: "task-guid"}}}
+		}
+
+		BeforeEach(func() {
+			fakeAuctioneerServer = ghttp.NewServer()
+			c = auctioneer.NewClient(fakeAuctioneerServer.URL(), 5*time.Second)
+		})
+
+		AfterEach(func() {
+			fakeAuctioneerServer.Close()
+		})
+
+		It("resends the request once the Retry-After delay has passed", func() {
+			fakeAuctioneerServer.AppendHandlers(
+				throttled("1"),
+				ghttp.RespondWith(http.StatusAccepted, nil),
+			)
+
+			startedAt := time.Now()
+			err := c.RequestTaskAuctions(lagertest.NewTestLogger("client_test"), tasks())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(time.Since(startedAt)).To(BeNumerically(">=", time.Second))
+			Expect(fakeAuctioneerServer.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		It("gives up after retrying a few times", func() {
+			fakeAuctioneerServer.AppendHandlers(throttled("0"), throttled("0"), throttled("0"), throttled("0"))
+
+			err := c.RequestTaskAuctions(lagertest.NewTestLogger("client_test"), tasks())
+			Expect(err).To(MatchError("http error: status code 429 (Too Many Requests)"))
+			Expect(fakeAuctioneerServer.ReceivedRequests()).To(HaveLen(4))
+		})
+
+		It("does not wait longer than the maximum delay", func() {
+			fakeAuctioneerServer.AppendHandlers(throttled("3600"))
+
+			err := c.RequestTaskAuctions(lagertest.NewTestLogger("client_test"), tasks())
+			Expect(err).To(MatchError("http error: status code 429 (Too Many Requests)"))
+			Expect(fakeAuctioneerServer.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("does not wait for a delay that ends after the request timeout", func() {
+			fakeAuctioneerServer.AppendHandlers(throttled("20"))
+
+			startedAt := time.Now()
+			err := c.RequestTaskAuctions(lagertest.NewTestLogger("client_test"), tasks())
+			Expect(err).To(MatchError("http error: status code 429 (Too Many Requests)"))
+			Expect(time.Since(startedAt)).To(BeNumerically("<", time.Second))
+			Expect(fakeAuctioneerServer.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("does not wait for a delay that ends after the deadline of the context", func() {
+			fakeAuctioneerServer.AppendHandlers(throttled("20"))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			err := c.(auctioneer.ContextClient).RequestTaskAuctionsWithContext(ctx, lagertest.NewTestLogger("client_test"), tasks())
+			Expect(err).To(MatchError("http error: status code 429 (Too Many Requests)"))
+			Expect(fakeAuctioneerServer.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("stops waiting when the context is done", func() {
+			fakeAuctioneerServer.AppendHandlers(throttled("20"))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(100*time.Millisecond, cancel)
+
+			err := c.(auctioneer.ContextClient).RequestTaskAuctionsWithContext(ctx, lagertest.NewTestLogger("client_test"), tasks())
+			Expect(err).To(Equal(context.Canceled))
+		})
+	})
+
+	Context("when the auctioneer listens on a unix socket", func() {
+		var (
+			socketDir            string
+			fakeAuctioneerServer *ghttp.Server
+		)
+
+		BeforeEach(func() {
+			var err error
+			socketDir, err = ioutil.TempDir("", "auctioneer-socket")
+			Expect(err).NotTo(HaveOccurred())
+
+			listener, err := net.Listen("unix", path.Join(socketDir, "auctioneer.sock"))
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeAuctioneerServer = ghttp.NewUnstartedServer()
+			fakeAuctioneerServer.HTTPTestServer.Listener.Close()
+			fakeAuctioneerServer.HTTPTestServer.Listener = listener
+			fakeAuctioneerServer.Start()
+
+			fakeAuctioneerServer.AppendHandlers(ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/v1/lrps"),
+				ghttp.RespondWith(http.StatusAccepted, nil),
+			))
+		})
+
+		AfterEach(func() {
+			fakeAuctioneerServer.Close()
+			os.RemoveAll(socketDir)
+		})
+
+		It("sends requests over the socket", func() {
+			c := auctioneer.NewClient("unix://"+path.Join(socketDir, "auctioneer.sock"), 5*time.Second)
+
+			err := c.RequestLRPAuctions(lagertest.NewTestLogger("client_test"), []*auctioneer.LRPStartRequest{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeAuctioneerServer.ReceivedRequests()).To(HaveLen(1))
+		})
 	})
 
 	Describe("NewSecureClient", func() {
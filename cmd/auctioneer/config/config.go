@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"os"
 
+	"code.cloudfoundry.org/auctioneer/domainlimit"
+	"code.cloudfoundry.org/auctioneer/failurenotifier"
+	"code.cloudfoundry.org/auctioneer/quota"
 	"code.cloudfoundry.org/debugserver"
 	loggingclient "code.cloudfoundry.org/diego-logging-client"
 	"code.cloudfoundry.org/durationjson"
@@ -12,35 +15,65 @@ import (
 )
 
 type AuctioneerConfig struct {
-	AuctionRunnerWorkers            int                   `json:"auction_runner_workers,omitempty"`
-	BBSAddress                      string                `json:"bbs_address,omitempty"`
-	BBSCACertFile                   string                `json:"bbs_ca_cert_file,omitempty"`
-	BBSClientCertFile               string                `json:"bbs_client_cert_file,omitempty"`
-	BBSClientKeyFile                string                `json:"bbs_client_key_file,omitempty"`
-	BBSClientSessionCacheSize       int                   `json:"bbs_client_session_cache_size,omitempty"`
-	BBSMaxIdleConnsPerHost          int                   `json:"bbs_max_idle_conns_per_host,omitempty"`
-	CACertFile                      string                `json:"ca_cert_file,omitempty"`
-	CellStateTimeout                durationjson.Duration `json:"cell_state_timeout,omitempty"`
-	CommunicationTimeout            durationjson.Duration `json:"communication_timeout,omitempty"`
-	ConsulCluster                   string                `json:"consul_cluster,omitempty"`
-	EnableConsulServiceRegistration bool                  `json:"enable_consul_service_registration,omitempty"`
-	ListenAddress                   string                `json:"listen_address,omitempty"`
-	LockRetryInterval               durationjson.Duration `json:"lock_retry_interval,omitempty"`
-	LockTTL                         durationjson.Duration `json:"lock_ttl,omitempty"`
-	LoggregatorConfig               loggingclient.Config  `json:"loggregator"`
-	RepCACert                       string                `json:"rep_ca_cert,omitempty"`
-	RepClientCert                   string                `json:"rep_client_cert,omitempty"`
-	RepClientKey                    string                `json:"rep_client_key,omitempty"`
-	RepClientSessionCacheSize       int                   `json:"rep_client_session_cache_size,omitempty"`
-	RepRequireTLS                   bool                  `json:"rep_require_tls,omitempty"`
-	ReportInterval                  durationjson.Duration `json:"report_interval,omitempty"`
-	ServerCertFile                  string                `json:"server_cert_file,omitempty"`
-	ServerKeyFile                   string                `json:"server_key_file,omitempty"`
-	SkipConsulLock                  bool                  `json:"skip_consul_lock"`
-	StartingContainerCountMaximum   int                   `json:"starting_container_count_maximum,omitempty"`
-	StartingContainerWeight         float64               `json:"starting_container_weight,omitempty"`
-	UUID                            string                `json:"uuid,omitempty"`
-	LocksLocketEnabled              bool                  `json:"locks_locket_enabled"`
+	AdminCACertFile                 string                 `json:"admin_ca_cert_file,omitempty"`
+	AdminListenAddress              string                 `json:"admin_listen_address,omitempty"`
+	AdminServerCertFile             string                 `json:"admin_server_cert_file,omitempty"`
+	AdminServerKeyFile              string                 `json:"admin_server_key_file,omitempty"`
+	AdmissionRetryAfter             durationjson.Duration  `json:"admission_retry_after,omitempty"`
+	AuctionRunnerWorkers            int                    `json:"auction_runner_workers,omitempty"`
+	AuditLogMaxBackups              int                    `json:"audit_log_max_backups,omitempty"`
+	AuditLogMaxSizeMB               int                    `json:"audit_log_max_size_mb,omitempty"`
+	AuditLogPath                    string                 `json:"audit_log_path,omitempty"`
+	AuthorizedClients               map[string][]string    `json:"authorized_clients,omitempty"`
+	BBSAddress                      string                 `json:"bbs_address,omitempty"`
+	BBSCACertFile                   string                 `json:"bbs_ca_cert_file,omitempty"`
+	BBSClientCertFile               string                 `json:"bbs_client_cert_file,omitempty"`
+	BBSClientKeyFile                string                 `json:"bbs_client_key_file,omitempty"`
+	BBSClientSessionCacheSize       int                    `json:"bbs_client_session_cache_size,omitempty"`
+	BBSMaxIdleConnsPerHost          int                    `json:"bbs_max_idle_conns_per_host,omitempty"`
+	BBSUpdateWorkers                int                    `json:"bbs_update_workers,omitempty"`
+	CACertFile                      string                 `json:"ca_cert_file,omitempty"`
+	CellStateTimeout                durationjson.Duration  `json:"cell_state_timeout,omitempty"`
+	CertificateReloadInterval       durationjson.Duration  `json:"certificate_reload_interval,omitempty"`
+	CommunicationTimeout            durationjson.Duration  `json:"communication_timeout,omitempty"`
+	ConsulCluster                   string                 `json:"consul_cluster,omitempty"`
+	DomainLimits                    domainlimit.Config     `json:"domain_limits"`
+	DrainTimeout                    durationjson.Duration  `json:"drain_timeout,omitempty"`
+	EnableConsulServiceRegistration bool                   `json:"enable_consul_service_registration,omitempty"`
+	ListenAddress                   string                 `json:"listen_address,omitempty"`
+	LockFilePath                    string                 `json:"lock_file_path,omitempty"`
+	LockMode                        string                 `json:"lock_mode,omitempty"`
+	LockRetryInterval               durationjson.Duration  `json:"lock_retry_interval,omitempty"`
+	LockTTL                         durationjson.Duration  `json:"lock_ttl,omitempty"`
+	LoggregatorConfig               loggingclient.Config   `json:"loggregator"`
+	MaxItemsPerRequest              int                    `json:"max_items_per_request,omitempty"`
+	MaxPendingWork                  int                    `json:"max_pending_work,omitempty"`
+	MaxRequestBodyBytes             int64                  `json:"max_request_body_bytes,omitempty"`
+	MetricsSink                     string                 `json:"metrics_sink,omitempty"`
+	PendingWorkCheckpointInterval   durationjson.Duration  `json:"pending_work_checkpoint_interval,omitempty"`
+	PendingWorkMaxAge               durationjson.Duration  `json:"pending_work_max_age,omitempty"`
+	PendingWorkSnapshotPath         string                 `json:"pending_work_snapshot_path,omitempty"`
+	PlacementFailureWebhooks        failurenotifier.Config `json:"placement_failure_webhooks"`
+	PrometheusListenAddress         string                 `json:"prometheus_listen_address,omitempty"`
+	Quotas                          quota.Config           `json:"quotas"`
+	RepCACert                       string                 `json:"rep_ca_cert,omitempty"`
+	RepClientCert                   string                 `json:"rep_client_cert,omitempty"`
+	RepClientKey                    string                 `json:"rep_client_key,omitempty"`
+	RepClientSessionCacheSize       int                    `json:"rep_client_session_cache_size,omitempty"`
+	RepRequireTLS                   bool                   `json:"rep_require_tls,omitempty"`
+	ReportInterval                  durationjson.Duration  `json:"report_interval,omitempty"`
+	ServerCertFile                  string                 `json:"server_cert_file,omitempty"`
+	ServerKeyFile                   string                 `json:"server_key_file,omitempty"`
+	SkipConsulLock                  bool                   `json:"skip_consul_lock"`
+	StartingContainerCountMaximum   int                    `json:"starting_container_count_maximum,omitempty"`
+	StartingContainerWeight         float64                `json:"starting_container_weight,omitempty"`
+	StatsdAddress                   string                 `json:"statsd_address,omitempty"`
+	StatsdPrefix                    string                 `json:"statsd_prefix,omitempty"`
+	TracingCollectorAddress         string                 `json:"tracing_collector_address,omitempty"`
+	TracingCollectorInsecure        bool                   `json:"tracing_collector_insecure,omitempty"`
+	UUID                            string                 `json:"uuid,omitempty"`
+	Zone                            string                 `json:"zone,omitempty"`
+	LocksLocketEnabled              bool                   `json:"locks_locket_enabled"`
 	debugserver.DebugServerConfig
 	lagerflags.LagerConfig
 	locket.ClientLocketConfig
@@ -57,6 +90,7 @@ func NewAuctioneerConfig(configPath string) (AuctioneerConfig, error) {
 	defer configFile.Close()
 
 	decoder := json.NewDecoder(configFile)
+	decoder.DisallowUnknownFields()
 
 	err = decoder.Decode(&cfg)
 	if err != nil {
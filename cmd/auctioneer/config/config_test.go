@@ -6,6 +6,9 @@ import (
 	"time"
 
 	"code.cloudfoundry.org/auctioneer/cmd/auctioneer/config"
+	"code.cloudfoundry.org/auctioneer/domainlimit"
+	"code.cloudfoundry.org/auctioneer/failurenotifier"
+	"code.cloudfoundry.org/auctioneer/quota"
 	"code.cloudfoundry.org/debugserver"
 	loggingclient "code.cloudfoundry.org/diego-logging-client"
 	"code.cloudfoundry.org/durationjson"
@@ -21,20 +24,44 @@ var _ = Describe("AuctioneerConfig", func() {
 
 	BeforeEach(func() {
 		configData = `{
+			"admin_ca_cert_file": "/var/vcap/jobs/auctioneer/config/admin.ca",
+			"admin_listen_address": "127.0.0.1:9017",
+			"admin_server_cert_file": "/var/vcap/jobs/auctioneer/config/admin.crt",
+			"admin_server_key_file": "/var/vcap/jobs/auctioneer/config/admin.key",
+			"admission_retry_after": "10s",
 			"auction_runner_workers": 10,
+			"audit_log_max_backups": 3,
+			"audit_log_max_size_mb": 50,
+			"audit_log_path": "/var/vcap/sys/log/auctioneer/auctions.jsonl",
+			"authorized_clients": {
+				"CreateTaskAuctions": ["bbs.service.cf.internal"],
+				"CreateLRPAuctions": ["bbs.service.cf.internal", "spiffe://cf/admin-tooling"]
+			},
 			"bbs_address": "1.1.1.1:9091",
 			"bbs_ca_cert_file": "/tmp/bbs_ca_cert",
 			"bbs_client_cert_file": "/tmp/bbs_client_cert",
 			"bbs_client_key_file": "/tmp/bbs_client_key",
 			"bbs_client_session_cache_size": 100,
 			"bbs_max_idle_conns_per_host": 10,
+			"bbs_update_workers": 50,
 			"ca_cert_file": "/path-to-cert",
 			"cell_state_timeout": "2s",
+			"certificate_reload_interval": "30s",
 			"communication_timeout": "15s",
 			"consul_cluster": "1.1.1.1",
 			"debug_address": "127.0.0.1:17017",
+			"domain_limits": {
+				"default": {"rate": 100, "burst": 500},
+				"domains": {
+					"cf-apps": {"rate": 1000, "weight": 4},
+					"ci": {"rate": 10, "burst": 50, "weight": 1}
+				}
+			},
+			"drain_timeout": "20s",
 			"enable_consul_service_registration": true,
 			"listen_address": "0.0.0.0:9090",
+			"lock_file_path": "/var/vcap/data/auctioneer/auctioneer.lock",
+			"lock_mode": "file",
 			"lock_retry_interval": "1m",
 			"lock_ttl": "20s",
 			"locks_locket_enabled": true,
@@ -55,6 +82,37 @@ var _ = Describe("AuctioneerConfig", func() {
 				"loggregator_job_ip": "job-ip",
 				"loggregator_job_origin": "job-origin"
 			},
+			"max_items_per_request": 500,
+			"max_pending_work": 10000,
+			"max_request_body_bytes": 1048576,
+			"metrics_sink": "statsd",
+			"pending_work_checkpoint_interval": "10s",
+			"pending_work_max_age": "3m",
+			"pending_work_snapshot_path": "/var/vcap/data/auctioneer/pending-work.json",
+			"placement_failure_webhooks": {
+				"endpoints": [
+					{
+						"url": "https://autoscaler.example.com/placement-failures",
+						"ca_cert_file": "/var/vcap/jobs/auctioneer/config/webhook.ca",
+						"client_cert_file": "/var/vcap/jobs/auctioneer/config/webhook.crt",
+						"client_key_file": "/var/vcap/jobs/auctioneer/config/webhook.key"
+					}
+				],
+				"flush_timeout": "4s",
+				"max_retries": 5,
+				"queue_size": 20,
+				"request_timeout": "3s",
+				"retry_interval": "2s"
+			},
+			"prometheus_listen_address": "127.0.0.1:9100",
+			"quotas": {
+				"domains": {
+					"ci": {"memory_mb": 65536, "containers": 200}
+				},
+				"placement_tags": {
+					"gpu": {"memory_mb": 8192, "disk_mb": 16384}
+				}
+			},
 			"rep_ca_cert": "/var/vcap/jobs/auctioneer/config/rep.ca",
 			"rep_client_cert": "/var/vcap/jobs/auctioneer/config/rep.crt",
 			"rep_client_key": "/var/vcap/jobs/auctioneer/config/rep.key",
@@ -66,7 +124,12 @@ var _ = Describe("AuctioneerConfig", func() {
 			"skip_consul_lock": true,
 			"starting_container_count_maximum": 10,
 			"starting_container_weight": 0.5,
-			"uuid": "bosh-boshy-bosh-bosh"
+			"statsd_address": "127.0.0.1:8125",
+			"statsd_prefix": "auctioneer",
+			"tracing_collector_address": "127.0.0.1:4318",
+			"tracing_collector_insecure": true,
+			"uuid": "bosh-boshy-bosh-bosh",
+			"zone": "z1"
     }`
 	})
 
@@ -90,16 +153,31 @@ var _ = Describe("AuctioneerConfig", func() {
 		auctioneerConfig, err := config.NewAuctioneerConfig(configFilePath)
 		Expect(err).NotTo(HaveOccurred())
 
+		webhookMaxRetries := 5
 		expectedConfig := config.AuctioneerConfig{
-			AuctionRunnerWorkers:      10,
+			AdminCACertFile:      "/var/vcap/jobs/auctioneer/config/admin.ca",
+			AdminListenAddress:   "127.0.0.1:9017",
+			AdminServerCertFile:  "/var/vcap/jobs/auctioneer/config/admin.crt",
+			AdminServerKeyFile:   "/var/vcap/jobs/auctioneer/config/admin.key",
+			AdmissionRetryAfter:  durationjson.Duration(10 * time.Second),
+			AuctionRunnerWorkers: 10,
+			AuditLogMaxBackups:   3,
+			AuditLogMaxSizeMB:    50,
+			AuditLogPath:         "/var/vcap/sys/log/auctioneer/auctions.jsonl",
+			AuthorizedClients: map[string][]string{
+				"CreateTaskAuctions": {"bbs.service.cf.internal"},
+				"CreateLRPAuctions":  {"bbs.service.cf.internal", "spiffe://cf/admin-tooling"},
+			},
 			BBSAddress:                "1.1.1.1:9091",
 			BBSCACertFile:             "/tmp/bbs_ca_cert",
 			BBSClientCertFile:         "/tmp/bbs_client_cert",
 			BBSClientKeyFile:          "/tmp/bbs_client_key",
 			BBSClientSessionCacheSize: 100,
 			BBSMaxIdleConnsPerHost:    10,
+			BBSUpdateWorkers:          50,
 			CACertFile:                "/path-to-cert",
 			CellStateTimeout:          durationjson.Duration(2 * time.Second),
+			CertificateReloadInterval: durationjson.Duration(30 * time.Second),
 			LocksLocketEnabled:        true,
 			ClientLocketConfig: locket.ClientLocketConfig{
 				LocketAddress:        "laksdjflksdajflkajsdf",
@@ -112,11 +190,21 @@ var _ = Describe("AuctioneerConfig", func() {
 			DebugServerConfig: debugserver.DebugServerConfig{
 				DebugAddress: "127.0.0.1:17017",
 			},
+			DomainLimits: domainlimit.Config{
+				Default: domainlimit.Limit{Rate: 100, Burst: 500},
+				Domains: map[string]domainlimit.Limit{
+					"cf-apps": {Rate: 1000, Weight: 4},
+					"ci":      {Rate: 10, Burst: 50, Weight: 1},
+				},
+			},
+			DrainTimeout:                    durationjson.Duration(20 * time.Second),
 			EnableConsulServiceRegistration: true,
 			LagerConfig: lagerflags.LagerConfig{
 				LogLevel: "debug",
 			},
 			ListenAddress:     "0.0.0.0:9090",
+			LockFilePath:      "/var/vcap/data/auctioneer/auctioneer.lock",
+			LockMode:          "file",
 			LockRetryInterval: durationjson.Duration(1 * time.Minute),
 			LockTTL:           durationjson.Duration(20 * time.Second),
 			LoggregatorConfig: loggingclient.Config{
@@ -131,6 +219,35 @@ var _ = Describe("AuctioneerConfig", func() {
 				JobIP:         "job-ip",
 				JobOrigin:     "job-origin",
 			},
+			MaxItemsPerRequest:            500,
+			MaxPendingWork:                10000,
+			MaxRequestBodyBytes:           1048576,
+			MetricsSink:                   "statsd",
+			PendingWorkCheckpointInterval: durationjson.Duration(10 * time.Second),
+			PendingWorkMaxAge:             durationjson.Duration(3 * time.Minute),
+			PendingWorkSnapshotPath:       "/var/vcap/data/auctioneer/pending-work.json",
+			PlacementFailureWebhooks: failurenotifier.Config{
+				Endpoints: []failurenotifier.EndpointConfig{{
+					URL:            "https://autoscaler.example.com/placement-failures",
+					CACertFile:     "/var/vcap/jobs/auctioneer/config/webhook.ca",
+					ClientCertFile: "/var/vcap/jobs/auctioneer/config/webhook.crt",
+					ClientKeyFile:  "/var/vcap/jobs/auctioneer/config/webhook.key",
+				}},
+				FlushTimeout:   durationjson.Duration(4 * time.Second),
+				MaxRetries:     &webhookMaxRetries,
+				QueueSize:      20,
+				RequestTimeout: durationjson.Duration(3 * time.Second),
+				RetryInterval:  durationjson.Duration(2 * time.Second),
+			},
+			PrometheusListenAddress: "127.0.0.1:9100",
+			Quotas: quota.Config{
+				Domains: map[string]quota.Quota{
+					"ci": {MemoryMB: 65536, Containers: 200},
+				},
+				PlacementTags: map[string]quota.Quota{
+					"gpu": {MemoryMB: 8192, DiskMB: 16384},
+				},
+			},
 			RepCACert:                     "/var/vcap/jobs/auctioneer/config/rep.ca",
 			RepClientCert:                 "/var/vcap/jobs/auctioneer/config/rep.crt",
 			RepClientKey:                  "/var/vcap/jobs/auctioneer/config/rep.key",
@@ -142,7 +259,12 @@ var _ = Describe("AuctioneerConfig", func() {
 			SkipConsulLock:                true,
 			StartingContainerCountMaximum: 10,
 			StartingContainerWeight:       .5,
+			StatsdAddress:                 "127.0.0.1:8125",
+			StatsdPrefix:                  "auctioneer",
+			TracingCollectorAddress:       "127.0.0.1:4318",
+			TracingCollectorInsecure:      true,
 			UUID: "bosh-boshy-bosh-bosh",
+			Zone:                          "z1",
 		}
 
 		Expect(auctioneerConfig).To(Equal(expectedConfig))
@@ -165,4 +287,15 @@ var _ = Describe("AuctioneerConfig", func() {
 			Expect(err).To(HaveOccurred())
 		})
 	})
+
+	Context("when the file contains an unknown property", func() {
+		BeforeEach(func() {
+			configData = `{"bbs_address": "1.1.1.1:9091", "bbs_adress": "1.1.1.1:9091"}`
+		})
+
+		It("returns an error naming it", func() {
+			_, err := config.NewAuctioneerConfig(configFilePath)
+			Expect(err).To(MatchError(ContainSubstring("bbs_adress")))
+		})
+	})
 })
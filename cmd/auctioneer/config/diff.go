@@ -0,0 +1,46 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// ChangedFields returns the names of the top-level properties that differ
+// between two configurations, as they appear in the configuration file.
+func ChangedFields(a, b AuctioneerConfig) ([]string, error) {
+	aFields, err := fields(a)
+	if err != nil {
+		return nil, err
+	}
+	bFields, err := fields(b)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := []string{}
+	for name, value := range aFields {
+		if !reflect.DeepEqual(value, bFields[name]) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range bFields {
+		if _, ok := aFields[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+
+	sort.Strings(changed)
+	return changed, nil
+}
+
+func fields(c AuctioneerConfig) (map[string]interface{}, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
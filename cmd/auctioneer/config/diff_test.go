@@ -0,0 +1,54 @@
+package config_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/cmd/auctioneer/config"
+	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	"code.cloudfoundry.org/durationjson"
+	"code.cloudfoundry.org/lager/lagerflags"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ChangedFields", func() {
+	var before, after config.AuctioneerConfig
+
+	BeforeEach(func() {
+		before = config.AuctioneerConfig{
+			AuctionRunnerWorkers: 10,
+			BBSAddress:           "1.1.1.1:9091",
+			CellStateTimeout:     durationjson.Duration(time.Second),
+			LagerConfig:          lagerflags.LagerConfig{LogLevel: "info"},
+			LoggregatorConfig:    loggingclient.Config{APIPort: 1234},
+		}
+		after = before
+	})
+
+	It("returns nothing when the configurations are the same", func() {
+		changed, err := config.ChangedFields(before, after)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeEmpty())
+	})
+
+	It("returns the sorted names of the changed properties", func() {
+		after.CellStateTimeout = durationjson.Duration(2 * time.Second)
+		after.AuctionRunnerWorkers = 20
+		after.LagerConfig.LogLevel = "debug"
+		after.LoggregatorConfig.APIPort = 4321
+
+		changed, err := config.ChangedFields(before, after)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(Equal([]string{"auction_runner_workers", "cell_state_timeout", "log_level", "loggregator"}))
+	})
+
+	It("returns properties that were added or removed", func() {
+		after.BBSAddress = ""
+		after.ListenAddress = "0.0.0.0:9016"
+
+		changed, err := config.ChangedFields(before, after)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(Equal([]string{"bbs_address", "listen_address"}))
+	})
+})
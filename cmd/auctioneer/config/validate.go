@@ -0,0 +1,319 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/auctioneer/domainlimit"
+	"code.cloudfoundry.org/auctioneer/locallock"
+	"code.cloudfoundry.org/auctioneer/metrics"
+	"code.cloudfoundry.org/auctioneer/quota"
+	"code.cloudfoundry.org/durationjson"
+)
+
+// FieldError describes a problem with a single configuration property,
+// identified by its JSON name.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors is every problem Validate found with a configuration.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return "invalid configuration: " + strings.Join(messages, "; ")
+}
+
+// Validate checks the configuration for missing or inconsistent values. It
+// returns ValidationErrors listing every problem found, or nil.
+func (c AuctioneerConfig) Validate() error {
+	v := &validator{}
+
+	v.required("bbs_address", c.BBSAddress)
+	v.together(
+		field{"bbs_ca_cert_file", c.BBSCACertFile},
+		field{"bbs_client_cert_file", c.BBSClientCertFile},
+		field{"bbs_client_key_file", c.BBSClientKeyFile},
+	)
+	if c.BBSUpdateWorkers < 0 {
+		v.add("bbs_update_workers", "must not be negative")
+	}
+
+	if v.required("listen_address", c.ListenAddress) {
+		listenAddress, err := auctioneer.ParseListenAddress(c.ListenAddress)
+		if err != nil {
+			v.add("listen_address", fmt.Sprintf("must be a host and port or a unix:// socket address: %s", err))
+		} else if listenAddress.IsUnix() && c.EnableConsulServiceRegistration {
+			v.add("enable_consul_service_registration", "cannot register an auctioneer listening on a unix socket")
+		}
+	}
+	v.together(
+		field{"server_cert_file", c.ServerCertFile},
+		field{"server_key_file", c.ServerKeyFile},
+		field{"ca_cert_file", c.CACertFile},
+	)
+
+	c.validateAdmin(v)
+	c.validateAuthorizedClients(v)
+	c.validateDomainLimits(v)
+	c.validateQuotas(v)
+
+	if c.MaxRequestBodyBytes < 0 {
+		v.add("max_request_body_bytes", "must not be negative")
+	}
+	if c.MaxItemsPerRequest < 0 {
+		v.add("max_items_per_request", "must not be negative")
+	}
+	if c.MaxPendingWork < 0 {
+		v.add("max_pending_work", "must not be negative")
+	}
+
+	if c.AuctionRunnerWorkers <= 0 {
+		v.add("auction_runner_workers", "must be greater than zero")
+	}
+	if c.StartingContainerWeight < 0 || c.StartingContainerWeight > 1 {
+		v.add("starting_container_weight", "must be between 0 and 1")
+	}
+	if c.StartingContainerCountMaximum < 0 {
+		v.add("starting_container_count_maximum", "must not be negative")
+	}
+
+	v.together(
+		field{"rep_client_cert", c.RepClientCert},
+		field{"rep_client_key", c.RepClientKey},
+	)
+	if c.RepRequireTLS {
+		v.required("rep_ca_cert", c.RepCACert)
+		v.required("rep_client_cert", c.RepClientCert)
+		v.required("rep_client_key", c.RepClientKey)
+	}
+
+	c.validateLocks(v)
+	c.validateMetrics(v)
+
+	v.notNegative("admission_retry_after", c.AdmissionRetryAfter)
+	v.notNegative("cell_state_timeout", c.CellStateTimeout)
+	v.notNegative("certificate_reload_interval", c.CertificateReloadInterval)
+	v.notNegative("communication_timeout", c.CommunicationTimeout)
+	v.notNegative("drain_timeout", c.DrainTimeout)
+	v.notNegative("lock_retry_interval", c.LockRetryInterval)
+	v.notNegative("lock_ttl", c.LockTTL)
+	v.notNegative("pending_work_checkpoint_interval", c.PendingWorkCheckpointInterval)
+	v.notNegative("pending_work_max_age", c.PendingWorkMaxAge)
+	v.notNegative("report_interval", c.ReportInterval)
+
+	if len(v.errors) > 0 {
+		return v.errors
+	}
+	return nil
+}
+
+func (c AuctioneerConfig) validateAdmin(v *validator) {
+	v.together(
+		field{"admin_server_cert_file", c.AdminServerCertFile},
+		field{"admin_server_key_file", c.AdminServerKeyFile},
+		field{"admin_ca_cert_file", c.AdminCACertFile},
+	)
+
+	if c.AdminListenAddress == "" {
+		if c.AdminServerCertFile != "" || c.AdminServerKeyFile != "" || c.AdminCACertFile != "" {
+			v.add("admin_listen_address", "is required when the admin TLS files are set")
+		}
+		return
+	}
+
+	adminAddress, err := auctioneer.ParseListenAddress(c.AdminListenAddress)
+	if err != nil {
+		v.add("admin_listen_address", fmt.Sprintf("must be a host and port or a unix:// socket address: %s", err))
+		return
+	}
+	if c.AdminListenAddress == c.ListenAddress {
+		v.add("admin_listen_address", "must differ from listen_address")
+	}
+
+	// Admin clients on a TCP address are only authenticated by their
+	// certificates, so the admin TLS files are required there.
+	if !adminAddress.IsUnix() && c.AdminServerCertFile == "" && c.AdminServerKeyFile == "" && c.AdminCACertFile == "" {
+		for _, name := range []string{"admin_server_cert_file", "admin_server_key_file", "admin_ca_cert_file"} {
+			v.add(name, "is required when admin_listen_address is a TCP address")
+		}
+	}
+}
+
+func (c AuctioneerConfig) validateAuthorizedClients(v *validator) {
+	if len(c.AuthorizedClients) == 0 {
+		return
+	}
+
+	if c.ServerCertFile == "" {
+		v.add("authorized_clients", "requires server_cert_file, server_key_file and ca_cert_file to be set")
+	}
+
+	routes := make([]string, 0, len(c.AuthorizedClients))
+	for route := range c.AuthorizedClients {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+
+	for _, route := range routes {
+		if _, ok := auctioneer.Routes.FindRouteByName(route); !ok {
+			v.add("authorized_clients", fmt.Sprintf("unknown route %q", route))
+		} else if len(c.AuthorizedClients[route]) == 0 {
+			v.add("authorized_clients", fmt.Sprintf("route %q must allow at least one client identity", route))
+		}
+	}
+}
+
+func (c AuctioneerConfig) validateDomainLimits(v *validator) {
+	validateLimit := func(name string, limit domainlimit.Limit) {
+		if limit.Rate < 0 || limit.Burst < 0 || limit.Weight < 0 {
+			v.add("domain_limits", name+": rate, burst and weight must not be negative")
+		}
+	}
+
+	validateLimit("default", c.DomainLimits.Default)
+
+	domains := make([]string, 0, len(c.DomainLimits.Domains))
+	for domain := range c.DomainLimits.Domains {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	for _, domain := range domains {
+		validateLimit(fmt.Sprintf("domain %q", domain), c.DomainLimits.Domains[domain])
+	}
+}
+
+func (c AuctioneerConfig) validateQuotas(v *validator) {
+	validateQuotas := func(kind string, quotas map[string]quota.Quota) {
+		names := make([]string, 0, len(quotas))
+		for name := range quotas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			q := quotas[name]
+			if q.MemoryMB < 0 || q.DiskMB < 0 || q.Containers < 0 {
+				v.add("quotas", fmt.Sprintf("%s %q: memory_mb, disk_mb and containers must not be negative", kind, name))
+			}
+		}
+	}
+
+	validateQuotas("domain", c.Quotas.Domains)
+	validateQuotas("placement tag", c.Quotas.PlacementTags)
+}
+
+func (c AuctioneerConfig) validateLocks(v *validator) {
+	if !c.SkipConsulLock || c.EnableConsulServiceRegistration {
+		v.required("consul_cluster", c.ConsulCluster)
+	}
+
+	if c.LocksLocketEnabled {
+		v.required("uuid", c.UUID)
+		v.required("locket_address", c.LocketAddress)
+	}
+
+	switch c.LockMode {
+	case "", locallock.AlwaysLeaderLockMode:
+	case locallock.FileLockMode:
+		v.required("lock_file_path", c.LockFilePath)
+	default:
+		v.add("lock_mode", fmt.Sprintf("must be %q or %q", locallock.FileLockMode, locallock.AlwaysLeaderLockMode))
+	}
+
+	if c.SkipConsulLock && !c.LocksLocketEnabled && c.LockMode == "" {
+		v.add("lock_mode", "a lock must be configured: enable the consul or locket lock, or set a lock mode")
+	}
+}
+
+func (c AuctioneerConfig) validateMetrics(v *validator) {
+	switch c.MetricsSink {
+	case "", metrics.LoggregatorSinkName, metrics.NoopSinkName:
+	case metrics.StatsdSinkName:
+		v.required("statsd_address", c.StatsdAddress)
+	case metrics.PrometheusSinkName:
+		v.required("prometheus_listen_address", c.PrometheusListenAddress)
+	default:
+		v.add("metrics_sink", fmt.Sprintf("unknown metrics sink %q", c.MetricsSink))
+	}
+
+	if c.PrometheusListenAddress != "" {
+		v.hostPort("prometheus_listen_address", c.PrometheusListenAddress)
+	}
+}
+
+type field struct {
+	name  string
+	value string
+}
+
+type validator struct {
+	errors ValidationErrors
+}
+
+func (v *validator) add(name, message string) {
+	v.errors = append(v.errors, FieldError{Field: name, Message: message})
+}
+
+// required reports whether the value is set, recording an error if not.
+func (v *validator) required(name, value string) bool {
+	if value == "" {
+		v.add(name, "is required")
+		return false
+	}
+	return true
+}
+
+// together records an error for each of the fields that is missing when any
+// of them is set.
+func (v *validator) together(fields ...field) {
+	set := []string{}
+	for _, f := range fields {
+		if f.value != "" {
+			set = append(set, f.name)
+		}
+	}
+
+	if len(set) == 0 || len(set) == len(fields) {
+		return
+	}
+
+	for _, f := range fields {
+		if f.value == "" {
+			v.add(f.name, "is required when "+strings.Join(set, ", ")+" is set")
+		}
+	}
+}
+
+func (v *validator) hostPort(name, address string) {
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		v.add(name, fmt.Sprintf("must be a host and port: %s", err))
+		return
+	}
+
+	if p, err := strconv.Atoi(port); err != nil || p < 0 || p > 65535 {
+		v.add(name, fmt.Sprintf("invalid port %q", port))
+	}
+}
+
+func (v *validator) notNegative(name string, d durationjson.Duration) {
+	if time.Duration(d) < 0 {
+		v.add(name, "must not be negative")
+	}
+}
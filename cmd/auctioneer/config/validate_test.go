@@ -0,0 +1,254 @@
+package config_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/cmd/auctioneer/config"
+	"code.cloudfoundry.org/auctioneer/domainlimit"
+	"code.cloudfoundry.org/auctioneer/quota"
+	"code.cloudfoundry.org/durationjson"
+	"code.cloudfoundry.org/locket"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Validate", func() {
+	var cfg config.AuctioneerConfig
+
+	BeforeEach(func() {
+		cfg = config.AuctioneerConfig{
+			AuctionRunnerWorkers:    1000,
+			BBSAddress:              "https://bbs.service.cf.internal:8889",
+			ConsulCluster:           "http://127.0.0.1:8500",
+			ListenAddress:           "0.0.0.0:9016",
+			StartingContainerWeight: 0.25,
+		}
+	})
+
+	fields := func(err error) []string {
+		validationErrors, ok := err.(config.ValidationErrors)
+		Expect(ok).To(BeTrue(), "expected ValidationErrors, got %#v", err)
+
+		names := []string{}
+		for _, fieldError := range validationErrors {
+			names = append(names, fieldError.Field)
+		}
+		return names
+	}
+
+	It("accepts a valid configuration", func() {
+		Expect(cfg.Validate()).To(Succeed())
+	})
+
+	It("reports every problem at once", func() {
+		cfg.BBSAddress = ""
+		cfg.ListenAddress = "0.0.0.0"
+		cfg.AuctionRunnerWorkers = 0
+		cfg.LocksLocketEnabled = true
+		cfg.ServerCertFile = "server.crt"
+
+		err := cfg.Validate()
+		Expect(fields(err)).To(ConsistOf(
+			"bbs_address",
+			"listen_address",
+			"auction_runner_workers",
+			"uuid",
+			"locket_address",
+			"server_key_file",
+			"ca_cert_file",
+		))
+		Expect(err.Error()).To(ContainSubstring("bbs_address: is required"))
+		Expect(err.Error()).To(ContainSubstring("server_key_file: is required when server_cert_file is set"))
+	})
+
+	It("requires a port in the listen address", func() {
+		cfg.ListenAddress = "0.0.0.0:http"
+		Expect(fields(cfg.Validate())).To(Equal([]string{"listen_address"}))
+	})
+
+	It("accepts an IPv6 listen address", func() {
+		cfg.ListenAddress = "[::]:9016"
+		Expect(cfg.Validate()).To(Succeed())
+	})
+
+	It("rejects an unbracketed IPv6 listen address", func() {
+		cfg.ListenAddress = "::1:9016"
+		Expect(fields(cfg.Validate())).To(Equal([]string{"listen_address"}))
+	})
+
+	It("accepts a unix socket listen address", func() {
+		cfg.ListenAddress = "unix:///var/vcap/data/auctioneer/auctioneer.sock"
+		Expect(cfg.Validate()).To(Succeed())
+	})
+
+	It("does not register an auctioneer listening on a unix socket with consul", func() {
+		cfg.ListenAddress = "unix:///var/vcap/data/auctioneer/auctioneer.sock"
+		cfg.EnableConsulServiceRegistration = true
+		Expect(fields(cfg.Validate())).To(Equal([]string{"enable_consul_service_registration"}))
+	})
+
+	It("requires the rep TLS files when rep TLS is required", func() {
+		cfg.RepRequireTLS = true
+		Expect(fields(cfg.Validate())).To(ConsistOf("rep_ca_cert", "rep_client_cert", "rep_client_key"))
+	})
+
+	It("requires a weight between 0 and 1", func() {
+		cfg.StartingContainerWeight = 1.5
+		Expect(fields(cfg.Validate())).To(Equal([]string{"starting_container_weight"}))
+	})
+
+	It("rejects negative durations", func() {
+		cfg.DrainTimeout = durationjson.Duration(-time.Second)
+		Expect(fields(cfg.Validate())).To(Equal([]string{"drain_timeout"}))
+	})
+
+	Context("admin listener", func() {
+		It("accepts an admin listen address with the admin TLS files", func() {
+			cfg.AdminListenAddress = "127.0.0.1:9017"
+			cfg.AdminServerCertFile = "admin.crt"
+			cfg.AdminServerKeyFile = "admin.key"
+			cfg.AdminCACertFile = "admin.ca"
+			Expect(cfg.Validate()).To(Succeed())
+		})
+
+		It("requires the admin TLS files for a TCP admin listen address", func() {
+			cfg.AdminListenAddress = "127.0.0.1:9017"
+			Expect(fields(cfg.Validate())).To(ConsistOf("admin_server_cert_file", "admin_server_key_file", "admin_ca_cert_file"))
+		})
+
+		It("accepts a unix socket admin listen address without the admin TLS files", func() {
+			cfg.AdminListenAddress = "unix:///var/vcap/data/auctioneer/admin.sock"
+			Expect(cfg.Validate()).To(Succeed())
+		})
+
+		It("requires the admin listen address to differ from the listen address", func() {
+			cfg.AdminListenAddress = cfg.ListenAddress
+			cfg.AdminServerCertFile = "admin.crt"
+			cfg.AdminServerKeyFile = "admin.key"
+			cfg.AdminCACertFile = "admin.ca"
+			Expect(fields(cfg.Validate())).To(Equal([]string{"admin_listen_address"}))
+		})
+
+		It("rejects an invalid admin listen address", func() {
+			cfg.AdminListenAddress = "127.0.0.1"
+			Expect(fields(cfg.Validate())).To(Equal([]string{"admin_listen_address"}))
+		})
+
+		It("requires the admin TLS files together", func() {
+			cfg.AdminListenAddress = "127.0.0.1:9017"
+			cfg.AdminServerCertFile = "admin.crt"
+			Expect(fields(cfg.Validate())).To(ConsistOf("admin_server_key_file", "admin_ca_cert_file"))
+		})
+
+		It("requires an admin listen address when the admin TLS files are set", func() {
+			cfg.AdminServerCertFile = "admin.crt"
+			cfg.AdminServerKeyFile = "admin.key"
+			cfg.AdminCACertFile = "admin.ca"
+			Expect(fields(cfg.Validate())).To(Equal([]string{"admin_listen_address"}))
+		})
+	})
+
+	It("rejects negative admission limits", func() {
+		cfg.MaxItemsPerRequest = -1
+		cfg.MaxPendingWork = -1
+		cfg.MaxRequestBodyBytes = -1
+		Expect(fields(cfg.Validate())).To(ConsistOf("max_items_per_request", "max_pending_work", "max_request_body_bytes"))
+	})
+
+	It("rejects negative domain limits", func() {
+		cfg.DomainLimits = domainlimit.Config{
+			Default: domainlimit.Limit{Rate: 10},
+			Domains: map[string]domainlimit.Limit{"ci": {Rate: -1}},
+		}
+		err := cfg.Validate()
+		Expect(fields(err)).To(Equal([]string{"domain_limits"}))
+		Expect(err.Error()).To(ContainSubstring(`domain "ci": rate, burst and weight must not be negative`))
+	})
+
+	It("rejects negative quotas", func() {
+		cfg.Quotas = quota.Config{
+			Domains:       map[string]quota.Quota{"ci": {MemoryMB: 1024}},
+			PlacementTags: map[string]quota.Quota{"gpu": {Containers: -1}},
+		}
+		err := cfg.Validate()
+		Expect(fields(err)).To(Equal([]string{"quotas"}))
+		Expect(err.Error()).To(ContainSubstring(`placement tag "gpu": memory_mb, disk_mb and containers must not be negative`))
+	})
+
+	Context("authorized clients", func() {
+		BeforeEach(func() {
+			cfg.CACertFile = "ca.crt"
+			cfg.ServerCertFile = "server.crt"
+			cfg.ServerKeyFile = "server.key"
+		})
+
+		It("accepts client identities for auction routes", func() {
+			cfg.AuthorizedClients = map[string][]string{"CreateTaskAuctions": {"bbs.service.cf.internal"}}
+			Expect(cfg.Validate()).To(Succeed())
+		})
+
+		It("rejects unknown routes", func() {
+			cfg.AuthorizedClients = map[string][]string{"CreateTaskAuction": {"bbs.service.cf.internal"}}
+			err := cfg.Validate()
+			Expect(fields(err)).To(Equal([]string{"authorized_clients"}))
+			Expect(err.Error()).To(ContainSubstring(`unknown route "CreateTaskAuction"`))
+		})
+
+		It("rejects routes that allow no clients", func() {
+			cfg.AuthorizedClients = map[string][]string{"CreateLRPAuctions": {}}
+			Expect(fields(cfg.Validate())).To(Equal([]string{"authorized_clients"}))
+		})
+
+		It("requires TLS", func() {
+			cfg.CACertFile = ""
+			cfg.ServerCertFile = ""
+			cfg.ServerKeyFile = ""
+			cfg.AuthorizedClients = map[string][]string{"CreateTaskAuctions": {"bbs.service.cf.internal"}}
+			Expect(fields(cfg.Validate())).To(Equal([]string{"authorized_clients"}))
+		})
+	})
+
+	Context("locks", func() {
+		It("requires a lock", func() {
+			cfg.SkipConsulLock = true
+			Expect(fields(cfg.Validate())).To(Equal([]string{"lock_mode"}))
+		})
+
+		It("does not require consul when only locket is used", func() {
+			cfg.SkipConsulLock = true
+			cfg.ConsulCluster = ""
+			cfg.LocksLocketEnabled = true
+			cfg.UUID = "auctioneer-uuid"
+			cfg.ClientLocketConfig = locket.ClientLocketConfig{LocketAddress: "locket.service.cf.internal:8891"}
+			Expect(cfg.Validate()).To(Succeed())
+		})
+
+		It("rejects an unknown lock mode", func() {
+			cfg.LockMode = "bogus"
+			Expect(fields(cfg.Validate())).To(Equal([]string{"lock_mode"}))
+		})
+
+		It("requires a lock file path for the file lock mode", func() {
+			cfg.LockMode = "file"
+			Expect(fields(cfg.Validate())).To(Equal([]string{"lock_file_path"}))
+		})
+	})
+
+	Context("metrics", func() {
+		It("rejects an unknown sink", func() {
+			cfg.MetricsSink = "carrier-pigeon"
+			Expect(fields(cfg.Validate())).To(Equal([]string{"metrics_sink"}))
+		})
+
+		It("requires a statsd address for the statsd sink", func() {
+			cfg.MetricsSink = "statsd"
+			Expect(fields(cfg.Validate())).To(Equal([]string{"statsd_address"}))
+		})
+
+		It("requires a prometheus listen address for the prometheus sink", func() {
+			cfg.MetricsSink = "prometheus"
+			Expect(fields(cfg.Validate())).To(Equal([]string{"prometheus_listen_address"}))
+		})
+	})
+})
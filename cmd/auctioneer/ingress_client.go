@@ -0,0 +1,90 @@
+package main
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/metrics"
+	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	loggregator "code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// ingressClient adapts a Sink to the IngressClient interface expected by
+// locket, lockheldmetrics and the runtime emitter. Envelope tags passed as
+// options are sent as tags. App logs and container metrics are discarded.
+type ingressClient struct {
+	sink metrics.Sink
+}
+
+func newIngressClient(sink metrics.Sink) loggingclient.IngressClient {
+	return &ingressClient{sink: sink}
+}
+
+func (c *ingressClient) SendDuration(name string, value time.Duration, opts ...loggregator.EmitGaugeOption) error {
+	return c.sink.SendDuration(name, value, gaugeTags(opts))
+}
+
+func (c *ingressClient) SendMebiBytes(name string, value int, opts ...loggregator.EmitGaugeOption) error {
+	return c.sink.SendGauge(name, float64(value), metrics.MebiBytesUnit, gaugeTags(opts))
+}
+
+func (c *ingressClient) SendMetric(name string, value int, opts ...loggregator.EmitGaugeOption) error {
+	return c.sink.SendGauge(name, float64(value), metrics.MetricUnit, gaugeTags(opts))
+}
+
+func (c *ingressClient) SendBytesPerSecond(name string, value float64) error {
+	return c.sink.SendGauge(name, value, metrics.BytesPerSecondUnit, nil)
+}
+
+func (c *ingressClient) SendRequestsPerSecond(name string, value float64) error {
+	return c.sink.SendGauge(name, value, metrics.RequestsPerSecondUnit, nil)
+}
+
+func (c *ingressClient) IncrementCounter(name string) error {
+	return c.sink.IncrementCounter(name, 1, nil)
+}
+
+func (c *ingressClient) IncrementCounterWithDelta(name string, value uint64) error {
+	return c.sink.IncrementCounter(name, value, nil)
+}
+
+func (c *ingressClient) SendAppLog(message, sourceType string, tags map[string]string) error {
+	return nil
+}
+
+func (c *ingressClient) SendAppErrorLog(message, sourceType string, tags map[string]string) error {
+	return nil
+}
+
+func (c *ingressClient) SendAppMetrics(metrics loggingclient.ContainerMetric) error {
+	return nil
+}
+
+func (c *ingressClient) SendSpikeMetrics(metrics loggingclient.SpikeMetric) error {
+	return nil
+}
+
+func (c *ingressClient) SendComponentMetric(name string, value float64, unit string) error {
+	return c.sink.SendGauge(name, value, unit, nil)
+}
+
+// gaugeTags extracts the envelope tags set by the given options.
+func gaugeTags(opts []loggregator.EmitGaugeOption) map[string]string {
+	if len(opts) == 0 {
+		return nil
+	}
+
+	envelope := &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Gauge{
+			Gauge: &loggregator_v2.Gauge{
+				Metrics: map[string]*loggregator_v2.GaugeValue{},
+			},
+		},
+		Tags: map[string]string{},
+	}
+
+	for _, opt := range opts {
+		opt(envelope)
+	}
+	return envelope.Tags
+}
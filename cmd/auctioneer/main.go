@@ -1,12 +1,12 @@
 package main
 
 import (
+	"crypto/tls"
 	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/hashicorp/consul/api"
@@ -15,8 +15,24 @@ import (
 	"code.cloudfoundry.org/auctioneer"
 	"code.cloudfoundry.org/auctioneer/auctionmetricemitterdelegate"
 	"code.cloudfoundry.org/auctioneer/auctionrunnerdelegate"
+	"code.cloudfoundry.org/auctioneer/auditlog"
+	"code.cloudfoundry.org/auctioneer/capacitymetrics"
+	"code.cloudfoundry.org/auctioneer/certs"
 	"code.cloudfoundry.org/auctioneer/cmd/auctioneer/config"
+	"code.cloudfoundry.org/auctioneer/cordon"
+	"code.cloudfoundry.org/auctioneer/domainlimit"
+	"code.cloudfoundry.org/auctioneer/drain"
+	"code.cloudfoundry.org/auctioneer/failurenotifier"
 	"code.cloudfoundry.org/auctioneer/handlers"
+	"code.cloudfoundry.org/auctioneer/locallock"
+	"code.cloudfoundry.org/auctioneer/metrics"
+	"code.cloudfoundry.org/auctioneer/pause"
+	"code.cloudfoundry.org/auctioneer/pendingwork"
+	"code.cloudfoundry.org/auctioneer/placementexplainer"
+	"code.cloudfoundry.org/auctioneer/quota"
+	"code.cloudfoundry.org/auctioneer/tracing"
+	"code.cloudfoundry.org/auctioneer/tuning"
+	"code.cloudfoundry.org/auctioneer/worktracker"
 	"code.cloudfoundry.org/bbs"
 	cfhttp "code.cloudfoundry.org/cfhttp/v2"
 	"code.cloudfoundry.org/consuladapter"
@@ -28,11 +44,8 @@ import (
 	"code.cloudfoundry.org/localip"
 	"code.cloudfoundry.org/locket"
 	"code.cloudfoundry.org/locket/jointlock"
-	"code.cloudfoundry.org/locket/lock"
 	"code.cloudfoundry.org/locket/lockheldmetrics"
-	locketmodels "code.cloudfoundry.org/locket/models"
 	"code.cloudfoundry.org/rep"
-	"code.cloudfoundry.org/tlsconfig"
 
 	"code.cloudfoundry.org/auction/auctionrunner"
 	"code.cloudfoundry.org/auction/auctiontypes"
@@ -50,51 +63,143 @@ var configFilePath = flag.String(
 	"Path to JSON configuration file",
 )
 
-const (
-	serverProtocol    = "http"
-	auctioneerLockKey = "auctioneer"
+var validateConfig = flag.Bool(
+	"validate-config",
+	false,
+	"Validate the configuration file and exit",
 )
 
+// version is reported in the auctioneer's presence. It is set at build time
+// with -ldflags "-X main.version=...".
+var version = "dev"
+
 func main() {
 	flag.Parse()
 
 	cfg, err := config.NewAuctioneerConfig(*configFilePath)
 	if err != nil {
-		// TODO: Test me?
-		panic(err)
+		logger, _ := lagerflags.NewFromConfig("auctioneer", lagerflags.DefaultLagerConfig())
+		logger.Fatal("failed-to-load-config", err, lager.Data{"path": *configFilePath})
 	}
 
 	logger, reconfigurableSink := lagerflags.NewFromConfig("auctioneer", cfg.LagerConfig)
-	metronClient, err := initializeMetron(logger, cfg)
-	if err != nil {
-		logger.Fatal("failed-to-initialize-metron", err)
+
+	if err := cfg.Validate(); err != nil {
+		logger.Fatal("invalid-config", err)
 	}
 
-	if err := validateBBSAddress(cfg.BBSAddress); err != nil {
-		logger.Fatal("invalid-bbs-address", err)
+	if *validateConfig {
+		logger.Info("config-valid")
+		os.Exit(0)
 	}
 
-	consulClient, err := consuladapter.NewClientFromUrl(cfg.ConsulCluster)
-	if err != nil {
-		logger.Fatal("new-client-failed", err)
+	metricsSink, prometheusSink := initializeMetrics(logger, cfg)
+	metronClient := newIngressClient(metricsSink)
+
+	var consulClient consuladapter.Client
+	if !cfg.SkipConsulLock || cfg.EnableConsulServiceRegistration {
+		consulClient, err = consuladapter.NewClientFromUrl(cfg.ConsulCluster)
+		if err != nil {
+			logger.Fatal("new-client-failed", err)
+		}
 	}
 
-	port, err := strconv.Atoi(strings.Split(cfg.ListenAddress, ":")[1])
+	listenAddress, err := auctioneer.ParseListenAddress(cfg.ListenAddress)
 	if err != nil {
-		logger.Fatal("invalid-port", err)
+		logger.Fatal("invalid-listen-address", err)
 	}
 
 	clock := clock.NewClock()
-	auctioneerServiceClient := auctioneer.NewServiceClient(consulClient, clock)
 
-	auctionRunner := initializeAuctionRunner(logger, cfg, initializeBBSClient(logger, cfg), metronClient)
+	var tracingProvider ifrit.Runner
+	if cfg.TracingCollectorAddress != "" {
+		tracingProvider, err = tracing.NewProvider(logger, cfg.TracingCollectorAddress, cfg.TracingCollectorInsecure)
+		if err != nil {
+			logger.Fatal("failed-to-initialize-tracing", err)
+		}
+	}
+
+	workTracker := worktracker.New(logger, clock, metricsSink, worktracker.DefaultMaxAge)
+	quotaEnforcer := quota.New(logger, clock, cfg.Quotas)
+	var explanationObservers []placementexplainer.ExplanationObserver
+	var auditLogSink *auditlog.FileSink
+	if cfg.AuditLogPath != "" {
+		auditLogSink = initializeAuditLogSink(logger, cfg)
+		explanationObservers = append(explanationObservers, auditlog.NewRecorder(logger, auditLogSink))
+	}
+	explainer := placementexplainer.New(logger, placementexplainer.DefaultMaxExplanations, explanationObservers...)
+	observers := []auctionrunnerdelegate.AuctionObserver{workTracker, explainer, quotaEnforcer}
+
+	var failureNotifier *failurenotifier.Notifier
+	if len(cfg.PlacementFailureWebhooks.Endpoints) > 0 {
+		failureNotifier, err = failurenotifier.New(logger, clock, cfg.PlacementFailureWebhooks)
+		if err != nil {
+			logger.Fatal("invalid-placement-failure-webhooks", err)
+		}
+		observers = append(observers, failureNotifier)
+	}
+
+	certificateStores := []*certs.Store{}
+
+	bbsClient := auctionrunnerdelegate.NewReloadingBBSClient(initializeBBSClient(logger, cfg))
+	if cfg.BBSClientCertFile != "" {
+		bbsStore := initializeCertificateStore(logger, "invalid-bbs-tls-config", "bbs-client", cfg.BBSClientCertFile, cfg.BBSClientKeyFile, cfg.BBSCACertFile)
+		bbsStore.OnReload(func() {
+			// The BBS client reads its certificates once, so a new one is
+			// built from the rotated files.
+			client, err := newBBSClient(cfg)
+			if err != nil {
+				logger.Error("failed-to-rebuild-bbs-client", err)
+				return
+			}
+			bbsClient.Set(client)
+		})
+		certificateStores = append(certificateStores, bbsStore)
+	}
+
+	var repStore *certs.Store
+	if cfg.RepCACert != "" && cfg.RepClientCert != "" && cfg.RepClientKey != "" {
+		repStore = initializeCertificateStore(logger, "invalid-rep-tls-config", "rep-client", cfg.RepClientCert, cfg.RepClientKey, cfg.RepCACert)
+		certificateStores = append(certificateStores, repStore)
+	}
+
+	communicationTimeout := tuning.NewTimeout(time.Duration(cfg.CommunicationTimeout))
+	cellStateTimeout := tuning.NewTimeout(time.Duration(cfg.CellStateTimeout))
+	auctionRunnerDelegate := initializeAuctionRunnerDelegate(logger, cfg, bbsClient, repStore, metricsSink, workTracker, observers, communicationTimeout, cellStateTimeout)
+	cordons := cordon.New(logger)
+	tunableRunner := initializeAuctionRunner(logger, cfg, clock, cordons.Delegate(auctionRunnerDelegate), metricsSink)
+	reloader := tuning.NewReloader(logger, reloadConfig(cfg), tunableRunner, communicationTimeout, cellStateTimeout, quotaEnforcer)
+	auctionRunner := pause.New(logger, quota.NewRunner(logger, tunableRunner, quotaEnforcer, workTracker, auctionRunnerDelegate))
+
+	tlsEnabled := cfg.ServerCertFile != "" || cfg.ServerKeyFile != "" || cfg.CACertFile != ""
+	scheme := auctioneer.SchemeHTTP
+	if tlsEnabled {
+		scheme = auctioneer.SchemeHTTPS
+	}
+
+	presence := auctioneer.Presence{
+		AuctioneerAddress: auctioneerAddress(logger, scheme, listenAddress),
+		Scheme:            scheme,
+		Version:           version,
+		StartedAt:         clock.Now().UnixNano(),
+		Zone:              cfg.Zone,
+		APIVersions:       auctioneer.SupportedAPIVersions,
+	}
 
 	locks := []grouper.Member{}
 	if !cfg.SkipConsulLock {
+		uuid, err := uuid.NewV4()
+		if err != nil {
+			logger.Fatal("Couldn't generate uuid", err)
+		}
+
+		consulPresence := presence
+		consulPresence.AuctioneerID = uuid.String()
+
 		lockMaintainer := initializeLockMaintainer(
 			logger,
-			auctioneerServiceClient,
-			port,
+			auctioneer.NewServiceClient(consulClient, clock),
+			consulPresence,
 			time.Duration(cfg.LockTTL),
 			time.Duration(cfg.LockRetryInterval),
 			metronClient,
@@ -103,30 +208,36 @@ func main() {
 	}
 
 	if cfg.LocksLocketEnabled {
-		if cfg.UUID == "" {
-			logger.Fatal("invalid-uuid", errors.New("invalid-uuid-from-config"))
-		}
-
 		locketClient, err := locket.NewClient(logger, cfg.ClientLocketConfig)
 		if err != nil {
 			logger.Fatal("failed-to-connect-to-locket", err)
 		}
 
-		lockIdentifier := &locketmodels.Resource{
-			Key:      auctioneerLockKey,
-			Owner:    cfg.UUID,
-			TypeCode: locketmodels.LOCK,
-			Type:     locketmodels.LockType,
-		}
+		locketPresence := presence
+		locketPresence.AuctioneerID = cfg.UUID
 
-		locks = append(locks, grouper.Member{"sql-lock", lock.NewLockRunner(
+		sqlLock := initializeLockMaintainer(
 			logger,
-			locketClient,
-			lockIdentifier,
-			locket.DefaultSessionTTLInSeconds,
-			clock,
+			auctioneer.NewLocketServiceClient(locketClient, clock),
+			locketPresence,
+			locket.DefaultSessionTTL,
 			locket.SQLRetryInterval,
-		)})
+			metronClient,
+		)
+		locks = append(locks, grouper.Member{"sql-lock", sqlLock})
+	}
+
+	switch cfg.LockMode {
+	case "":
+	case locallock.FileLockMode:
+		if cfg.LockFilePath == "" {
+			logger.Fatal("invalid-lock-file-path", errors.New("lock_file_path is required for the file lock mode"))
+		}
+		locks = append(locks, grouper.Member{"file-lock", locallock.NewFileLockRunner(logger, cfg.LockFilePath, time.Duration(cfg.LockRetryInterval), clock)})
+	case locallock.AlwaysLeaderLockMode:
+		locks = append(locks, grouper.Member{"always-leader", locallock.NewAlwaysLeaderRunner(logger)})
+	default:
+		logger.Fatal("invalid-lock-mode", fmt.Errorf("unknown lock mode %q", cfg.LockMode))
 	}
 
 	var lock ifrit.Runner
@@ -139,42 +250,133 @@ func main() {
 		lock = jointlock.NewJointLock(clock, locket.DefaultSessionTTL, locks...)
 	}
 
-	var auctionServer ifrit.Runner
-	if cfg.ServerCertFile != "" || cfg.ServerKeyFile != "" || cfg.CACertFile != "" {
-		tlsConfig, err := tlsconfig.Build(
-			tlsconfig.WithInternalServiceDefaults(),
-			tlsconfig.WithIdentityFromFile(cfg.ServerCertFile, cfg.ServerKeyFile),
-		).Server(tlsconfig.WithClientAuthenticationFromFile(cfg.CACertFile))
+	drainer := drain.New(logger, clock, workTracker, auctionRunner, time.Duration(cfg.DrainTimeout))
+	admissionLimits := handlers.AdmissionLimits{
+		MaxRequestBodyBytes: cfg.MaxRequestBodyBytes,
+		MaxItemsPerRequest:  cfg.MaxItemsPerRequest,
+		MaxPendingWork:      cfg.MaxPendingWork,
+		RetryAfter:          time.Duration(cfg.AdmissionRetryAfter),
+	}
+	var domainLimiter handlers.DomainLimiter
+	if cfg.DomainLimits.Enabled() {
+		domainLimiter = domainlimit.New(logger, clock, metricsSink, cfg.DomainLimits)
+	}
+	handler := handlers.RejectWhileDraining(handlers.New(logger, auctionRunner, workTracker, metricsSink, handlers.AuthorizedClients(cfg.AuthorizedClients), admissionLimits, workTracker, domainLimiter), drainer)
+
+	var serverTLSConfig *tls.Config
+	if tlsEnabled {
+		serverStore := initializeCertificateStore(logger, "invalid-tls-config", "server", cfg.ServerCertFile, cfg.ServerKeyFile, cfg.CACertFile)
+		certificateStores = append(certificateStores, serverStore)
+
+		serverTLSConfig, err = serverStore.ServerConfig()
 		if err != nil {
 			logger.Fatal("invalid-tls-config", err)
 		}
-		auctionServer = http_server.NewTLSServer(cfg.ListenAddress, handlers.New(logger, auctionRunner, metronClient), tlsConfig)
-	} else {
-		auctionServer = http_server.New(cfg.ListenAddress, handlers.New(logger, auctionRunner, metronClient))
 	}
+	auctionServer := initializeServer(logger, listenAddress, handler, serverTLSConfig)
 
-	metricsTicker := clock.NewTicker(time.Duration(cfg.ReportInterval))
-	lockHeldMetronNotifier := lockheldmetrics.NewLockHeldMetronNotifier(logger, metricsTicker, metronClient)
+	// Every notifier reporting on the report interval subscribes to the same
+	// ticker, so they report on the same ticks.
+	metricsTicker := metrics.NewReportTicker(clock.NewTicker(time.Duration(cfg.ReportInterval)))
+	lockHeldMetronNotifier := lockheldmetrics.NewLockHeldMetronNotifier(logger, metricsTicker.Subscribe(), metronClient)
 
 	members := grouper.Members{
+		{"report-ticker", metricsTicker},
 		{"lock-held-metrics", lockHeldMetronNotifier},
 		{"lock", lock},
 		{"set-lock-held-metrics", lockheldmetrics.SetLockHeldRunner(logger, *lockHeldMetronNotifier)},
-		{"auction-runner", auctionRunner},
-		{"auction-server", auctionServer},
+		{"capacity-metrics", capacitymetrics.NewNotifier(logger, metricsTicker.Subscribe(), auctionRunnerDelegate, metricsSink)},
+		{"queue-depth-metrics", worktracker.NewDepthNotifier(logger, metricsTicker.Subscribe(), workTracker, metricsSink)},
+	}
+
+	if failureNotifier != nil {
+		members = append(members, grouper.Member{"failure-notifier", failureNotifier})
+	}
+
+	members = append(members, grouper.Member{"auction-runner", auctionRunner})
+
+	if cfg.PendingWorkSnapshotPath != "" {
+		checkpointInterval := time.Duration(cfg.PendingWorkCheckpointInterval)
+		if checkpointInterval == 0 {
+			checkpointInterval = pendingwork.DefaultCheckpointInterval
+		}
+
+		store := pendingwork.NewFileStore(cfg.PendingWorkSnapshotPath)
+		members = append(members,
+			grouper.Member{"pending-work-restorer", pendingwork.NewRestorer(logger, clock, store, workTracker, auctionRunner, domainLimiter, time.Duration(cfg.PendingWorkMaxAge))},
+			grouper.Member{"pending-work-checkpointer", pendingwork.NewCheckpointer(logger, clock, clock.NewTicker(checkpointInterval), workTracker, store)},
+		)
+	}
+
+	members = append(members, grouper.Member{"auction-server", auctionServer})
+
+	if cfg.AdminListenAddress != "" {
+		var adminStore *certs.Store
+		if cfg.AdminServerCertFile != "" {
+			adminStore = initializeCertificateStore(logger, "invalid-admin-tls-config", "admin-server", cfg.AdminServerCertFile, cfg.AdminServerKeyFile, cfg.AdminCACertFile)
+			certificateStores = append(certificateStores, adminStore)
+		}
+
+		adminHandler := handlers.NewAdminHandler(version, presence.StartedAt, drainer, workTracker, auctionRunnerDelegate, cordons, quotaEnforcer, auctionRunner, reloader)
+		adminServer := initializeAdminServer(logger, cfg, handlers.NewAdmin(logger, adminHandler, explainer), adminStore)
+		members = append(members, grouper.Member{"admin-server", adminServer})
 	}
 
 	if cfg.EnableConsulServiceRegistration {
-		registrationRunner := initializeRegistrationRunner(logger, consulClient, clock, port)
+		registrationRunner := initializeRegistrationRunner(logger, consulClient, clock, listenAddress.Port)
 		members = append(members, grouper.Member{"registration-runner", registrationRunner})
 	}
 
+	// The ordered group stops its members in reverse, so the drainer is
+	// signalled first and the locks are only released once it has exited.
+	members = append(members, grouper.Member{"drain", drainer})
+
+	// The reloader starts before the lock so that SIGHUP, which would
+	// otherwise terminate the process, is handled on standby auctioneers too.
+	members = append(grouper.Members{
+		{"reloader", reloader},
+	}, members...)
+
+	// Certificates are reloaded whether or not this auctioneer holds the
+	// lock, so that a standby reports their expiry and its servers start
+	// with the current ones once it acquires the lock.
+	if len(certificateStores) > 0 {
+		reloadInterval := time.Duration(cfg.CertificateReloadInterval)
+		if reloadInterval == 0 {
+			reloadInterval = certs.DefaultReloadInterval
+		}
+
+		watcher := certs.NewWatcher(logger, clock, clock.NewTicker(reloadInterval), metricsSink, certificateStores...)
+		members = append(grouper.Members{
+			{"certificate-watcher", watcher},
+		}, members...)
+	}
+
 	if cfg.DebugAddress != "" {
 		members = append(grouper.Members{
 			{"debug-server", debugserver.Runner(cfg.DebugAddress, reconfigurableSink)},
 		}, members...)
 	}
 
+	if tracingProvider != nil {
+		members = append(grouper.Members{
+			{"tracing", tracingProvider},
+		}, members...)
+	}
+
+	if prometheusSink != nil {
+		members = append(grouper.Members{
+			{"prometheus-server", http_server.New(cfg.PrometheusListenAddress, prometheusSink.Handler())},
+		}, members...)
+	}
+
+	// The audit log is closed last, once nothing can complete an auction.
+	if auditLogSink != nil {
+		members = append(grouper.Members{
+			{"audit-log", auditLogSink},
+		}, members...)
+	}
+
 	group := grouper.NewOrdered(os.Interrupt, members)
 
 	monitor := ifrit.Invoke(sigmon.New(group))
@@ -190,13 +392,20 @@ func main() {
 	logger.Info("exited")
 }
 
-func initializeAuctionRunner(logger lager.Logger, cfg config.AuctioneerConfig, bbsClient bbs.InternalClient, metronClient loggingclient.IngressClient) auctiontypes.AuctionRunner {
-	httpClient := cfhttp.NewClient(
-		cfhttp.WithRequestTimeout(time.Duration(cfg.CommunicationTimeout)),
-	)
-	stateClient := cfhttp.NewClient(
-		cfhttp.WithRequestTimeout(time.Duration(cfg.CellStateTimeout)),
-	)
+func initializeAuctionRunnerDelegate(
+	logger lager.Logger,
+	cfg config.AuctioneerConfig,
+	bbsClient auctionrunnerdelegate.BBSClient,
+	repStore *certs.Store,
+	metricsSink metrics.Sink,
+	traces auctionrunnerdelegate.WorkTraces,
+	observers []auctionrunnerdelegate.AuctionObserver,
+	communicationTimeout, cellStateTimeout *tuning.Timeout,
+) *auctionrunnerdelegate.AuctionRunnerDelegate {
+	// The request timeouts are enforced by the transports wrapped below, so
+	// that a reload can change them.
+	httpClient := cfhttp.NewClient()
+	stateClient := cfhttp.NewClient()
 	repTLSConfig := &rep.TLSConfig{
 		RequireTLS:      cfg.RepRequireTLS,
 		CaCertFile:      cfg.RepCACert,
@@ -209,36 +418,159 @@ func initializeAuctionRunner(logger lager.Logger, cfg config.AuctioneerConfig, b
 		logger.Fatal("new-rep-client-factory-failed", err)
 	}
 
-	delegate := auctionrunnerdelegate.New(repClientFactory, bbsClient, logger)
-	metricEmitter := auctionmetricemitterdelegate.New(metronClient)
-	workPool, err := workpool.NewWorkPool(cfg.AuctionRunnerWorkers)
+	// The rep client factory configures TLS on the clients' own transports
+	// from the files once. Replacing that configuration with the store's
+	// lets new connections use rotated certificates.
+	if repStore != nil {
+		for _, client := range []*http.Client{httpClient, stateClient} {
+			tlsConfig, err := repStore.ClientConfig()
+			if err != nil {
+				logger.Fatal("new-rep-client-factory-failed", err)
+			}
+			tlsConfig.ClientSessionCache = tls.NewLRUClientSessionCache(cfg.RepClientSessionCacheSize)
+
+			transport, ok := client.Transport.(*http.Transport)
+			if !ok {
+				logger.Fatal("new-rep-client-factory-failed", fmt.Errorf("unexpected rep client transport %T", client.Transport))
+			}
+			transport.TLSClientConfig = tlsConfig
+		}
+	}
+
+	// The transports can only be wrapped once the rep client factory has
+	// configured them.
+	httpClient.Transport = communicationTimeout.Transport(httpClient.Transport)
+	stateClient.Transport = cellStateTimeout.Transport(stateClient.Transport)
+
+	bbsUpdateWorkers := cfg.BBSUpdateWorkers
+	if bbsUpdateWorkers == 0 {
+		bbsUpdateWorkers = auctionrunnerdelegate.DefaultMaxBBSUpdateWorkers
+	}
+
+	return auctionrunnerdelegate.New(repClientFactory, bbsClient, metricsSink, bbsUpdateWorkers, traces, clock.NewClock(), logger, observers...)
+}
+
+func initializeAuctionRunner(logger lager.Logger, cfg config.AuctioneerConfig, clock clock.Clock, delegate auctiontypes.AuctionRunnerDelegate, metricsSink metrics.Sink) *tuning.AuctionRunner {
+	metricEmitter := auctionmetricemitterdelegate.New(metricsSink)
+
+	factory := func(params tuning.Parameters, delegate auctiontypes.AuctionRunnerDelegate) (auctiontypes.AuctionRunner, func(), error) {
+		workPool, err := workpool.NewWorkPool(params.AuctionRunnerWorkers)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		runner := auctionrunner.New(
+			logger,
+			delegate,
+			metricEmitter,
+			clock,
+			workPool,
+			params.StartingContainerWeight,
+			params.StartingContainerCountMaximum,
+		)
+		return runner, workPool.Stop, nil
+	}
+
+	auctionRunner, err := tuning.NewAuctionRunner(logger, clock, factory, delegate, tuningParameters(cfg), worktracker.DefaultMaxAge)
 	if err != nil {
 		logger.Fatal("failed-to-construct-auction-runner-workpool", err, lager.Data{"num-workers": cfg.AuctionRunnerWorkers}) // should never happen
 	}
+	return auctionRunner
+}
 
-	return auctionrunner.New(
-		logger,
-		delegate,
-		metricEmitter,
-		clock.NewClock(),
-		workPool,
-		cfg.StartingContainerWeight,
-		cfg.StartingContainerCountMaximum,
-	)
+func tuningParameters(cfg config.AuctioneerConfig) tuning.Parameters {
+	return tuning.Parameters{
+		AuctionRunnerWorkers:          cfg.AuctionRunnerWorkers,
+		StartingContainerCountMaximum: cfg.StartingContainerCountMaximum,
+		StartingContainerWeight:       cfg.StartingContainerWeight,
+		CellStateTimeout:              time.Duration(cfg.CellStateTimeout),
+		CommunicationTimeout:          time.Duration(cfg.CommunicationTimeout),
+		Quotas:                        cfg.Quotas,
+	}
 }
 
-func initializeMetron(logger lager.Logger, cfg config.AuctioneerConfig) (loggingclient.IngressClient, error) {
-	client, err := loggingclient.NewIngressClient(cfg.LoggregatorConfig)
+// reloadConfig reads the configuration file again and reports the properties
+// that differ from the configuration the auctioneer was started with.
+func reloadConfig(initial config.AuctioneerConfig) tuning.LoadFunc {
+	return func() (tuning.Parameters, []string, error) {
+		cfg, err := config.NewAuctioneerConfig(*configFilePath)
+		if err != nil {
+			return tuning.Parameters{}, nil, err
+		}
+
+		if err := cfg.Validate(); err != nil {
+			return tuning.Parameters{}, nil, err
+		}
+
+		changed, err := config.ChangedFields(initial, cfg)
+		if err != nil {
+			return tuning.Parameters{}, nil, err
+		}
+
+		return tuningParameters(cfg), changed, nil
+	}
+}
+
+func initializeAuditLogSink(logger lager.Logger, cfg config.AuctioneerConfig) *auditlog.FileSink {
+	maxBackups := auditlog.DefaultMaxBackups
+	if cfg.AuditLogMaxBackups != 0 {
+		maxBackups = cfg.AuditLogMaxBackups
+	}
+
+	sink, err := auditlog.NewFileSink(logger, cfg.AuditLogPath, int64(cfg.AuditLogMaxSizeMB)*1024*1024, maxBackups)
 	if err != nil {
-		return nil, err
+		logger.Fatal("failed-to-open-audit-log", err, lager.Data{"path": cfg.AuditLogPath})
+	}
+
+	return sink
+}
+
+// initializeMetrics builds the sink selected by cfg.MetricsSink, adding the
+// Prometheus sink whenever a Prometheus listen address is configured.
+func initializeMetrics(logger lager.Logger, cfg config.AuctioneerConfig) (metrics.Sink, *metrics.PrometheusSink) {
+	sinks := []metrics.Sink{}
+
+	var prometheusSink *metrics.PrometheusSink
+	if cfg.PrometheusListenAddress != "" {
+		prometheusSink = metrics.NewPrometheusSink()
+		sinks = append(sinks, prometheusSink)
+	}
+
+	emitRuntimeMetrics := false
+	switch cfg.MetricsSink {
+	case "", metrics.LoggregatorSinkName:
+		sink, err := metrics.NewLoggregatorSink(cfg.LoggregatorConfig)
+		if err != nil {
+			logger.Fatal("failed-to-initialize-metron", err)
+		}
+		sinks = append(sinks, sink)
+		emitRuntimeMetrics = cfg.LoggregatorConfig.UseV2API
+	case metrics.StatsdSinkName:
+		sink, err := metrics.NewStatsdSink(cfg.StatsdAddress, cfg.StatsdPrefix)
+		if err != nil {
+			logger.Fatal("failed-to-initialize-statsd", err)
+		}
+		sinks = append(sinks, sink)
+		emitRuntimeMetrics = true
+	case metrics.PrometheusSinkName:
+		if prometheusSink == nil {
+			logger.Fatal("invalid-metrics-sink", errors.New("prometheus_listen_address is required for the prometheus metrics sink"))
+		}
+		emitRuntimeMetrics = true
+	case metrics.NoopSinkName:
+		sinks = append(sinks, metrics.NewNoopSink())
+	default:
+		logger.Fatal("invalid-metrics-sink", fmt.Errorf("unknown metrics sink %q", cfg.MetricsSink))
 	}
 
-	if cfg.LoggregatorConfig.UseV2API {
-		emitter := runtimeemitter.NewV1(client)
+	sink := metrics.NewFanoutSink(sinks...)
+
+	if emitRuntimeMetrics {
+		emitter := runtimeemitter.NewV1(newIngressClient(sink))
 		go emitter.Run()
 	}
 
-	return client, nil
+	return sink, prometheusSink
 }
 
 func initializeRegistrationRunner(logger lager.Logger, consulClient consuladapter.Client, clock clock.Clock, port int) ifrit.Runner {
@@ -252,17 +584,9 @@ func initializeRegistrationRunner(logger lager.Logger, consulClient consuladapte
 	return locket.NewRegistrationRunner(logger, registration, consulClient, locket.SQLRetryInterval, clock)
 }
 
-func initializeLockMaintainer(
-	logger lager.Logger,
-	serviceClient auctioneer.ServiceClient,
-	port int,
-	lockTTL time.Duration,
-	lockRetryInterval time.Duration,
-	metronClient loggingclient.IngressClient,
-) ifrit.Runner {
-	uuid, err := uuid.NewV4()
-	if err != nil {
-		logger.Fatal("Couldn't generate uuid", err)
+func auctioneerAddress(logger lager.Logger, scheme string, listenAddress auctioneer.ListenAddress) string {
+	if listenAddress.IsUnix() {
+		return listenAddress.URL(scheme, "")
 	}
 
 	localIP, err := localip.LocalIP()
@@ -270,10 +594,60 @@ func initializeLockMaintainer(
 		logger.Fatal("Couldn't determine local IP", err)
 	}
 
-	address := fmt.Sprintf("%s://%s:%d", serverProtocol, localIP, port)
-	auctioneerPresence := auctioneer.NewPresence(uuid.String(), address)
+	return listenAddress.URL(scheme, localIP)
+}
+
+func initializeAdminServer(logger lager.Logger, cfg config.AuctioneerConfig, handler http.Handler, store *certs.Store) ifrit.Runner {
+	listenAddress, err := auctioneer.ParseListenAddress(cfg.AdminListenAddress)
+	if err != nil {
+		logger.Fatal("invalid-admin-listen-address", err)
+	}
+
+	var tlsConfig *tls.Config
+	if store != nil {
+		tlsConfig, err = store.ServerConfig()
+		if err != nil {
+			logger.Fatal("invalid-admin-tls-config", err)
+		}
+	} else if !listenAddress.IsUnix() {
+		logger.Fatal("invalid-admin-tls-config", errors.New("admin TLS files are required on a TCP admin listen address"))
+	}
+
+	return initializeServer(logger, listenAddress, handler, tlsConfig)
+}
+
+func initializeServer(logger lager.Logger, listenAddress auctioneer.ListenAddress, handler http.Handler, tlsConfig *tls.Config) ifrit.Runner {
+	if !listenAddress.IsUnix() {
+		if tlsConfig != nil {
+			return http_server.NewTLSServer(listenAddress.Address, handler, tlsConfig)
+		}
+		return http_server.New(listenAddress.Address, handler)
+	}
+
+	// A socket left behind by an auctioneer that did not exit cleanly would
+	// stop the server from listening. Anything other than a socket is left
+	// for the server to fail on.
+	if info, err := os.Lstat(listenAddress.Address); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(listenAddress.Address); err != nil {
+			logger.Fatal("failed-to-remove-stale-socket", err, lager.Data{"path": listenAddress.Address})
+		}
+	}
+
+	if tlsConfig != nil {
+		return http_server.NewUnixTLSServer(listenAddress.Address, handler, tlsConfig)
+	}
+	return http_server.NewUnixServer(listenAddress.Address, handler)
+}
 
-	lockMaintainer, err := serviceClient.NewAuctioneerLockRunner(logger, auctioneerPresence, lockRetryInterval, lockTTL, metronClient)
+func initializeLockMaintainer(
+	logger lager.Logger,
+	serviceClient auctioneer.ServiceClient,
+	presence auctioneer.Presence,
+	lockTTL time.Duration,
+	lockRetryInterval time.Duration,
+	metronClient loggingclient.IngressClient,
+) ifrit.Runner {
+	lockMaintainer, err := serviceClient.NewAuctioneerLockRunner(logger, presence, lockRetryInterval, lockTTL, metronClient)
 	if err != nil {
 		logger.Fatal("Couldn't create lock maintainer", err)
 	}
@@ -281,15 +655,16 @@ func initializeLockMaintainer(
 	return lockMaintainer
 }
 
-func validateBBSAddress(bbsAddress string) error {
-	if bbsAddress == "" {
-		return errors.New("bbsAddress is required")
+func initializeBBSClient(logger lager.Logger, cfg config.AuctioneerConfig) bbs.InternalClient {
+	bbsClient, err := newBBSClient(cfg)
+	if err != nil {
+		logger.Fatal("Failed to configure secure BBS client", err)
 	}
-	return nil
+	return bbsClient
 }
 
-func initializeBBSClient(logger lager.Logger, cfg config.AuctioneerConfig) bbs.InternalClient {
-	bbsClient, err := bbs.NewClientWithConfig(bbs.ClientConfig{
+func newBBSClient(cfg config.AuctioneerConfig) (bbs.InternalClient, error) {
+	return bbs.NewClientWithConfig(bbs.ClientConfig{
 		URL:                    cfg.BBSAddress,
 		IsTLS:                  true,
 		CAFile:                 cfg.BBSCACertFile,
@@ -299,8 +674,14 @@ func initializeBBSClient(logger lager.Logger, cfg config.AuctioneerConfig) bbs.I
 		MaxIdleConnsPerHost:    cfg.BBSMaxIdleConnsPerHost,
 		RequestTimeout:         time.Duration(cfg.CommunicationTimeout),
 	})
+}
+
+// initializeCertificateStore loads the certificate files, logging action and
+// exiting if they are invalid.
+func initializeCertificateStore(logger lager.Logger, action, name, certFile, keyFile, caFile string) *certs.Store {
+	store, err := certs.NewStore(name, certFile, keyFile, caFile)
 	if err != nil {
-		logger.Fatal("Failed to configure secure BBS client", err)
+		logger.Fatal(action, err, lager.Data{"certificate": name})
 	}
-	return bbsClient
+	return store
 }
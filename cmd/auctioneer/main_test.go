@@ -1,18 +1,26 @@
 package main_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net"
+	"net/http"
 	"os"
 	"os/exec"
 	"path"
+	"strings"
+	"syscall"
 	"time"
 
 	"code.cloudfoundry.org/auctioneer"
 	"code.cloudfoundry.org/auctioneer/cmd/auctioneer/config"
+	"code.cloudfoundry.org/auctioneer/domainlimit"
+	"code.cloudfoundry.org/auctioneer/handlers"
+	"code.cloudfoundry.org/auctioneer/quota"
 	"code.cloudfoundry.org/bbs"
 	"code.cloudfoundry.org/bbs/models"
 	"code.cloudfoundry.org/bbs/models/test/model_helpers"
@@ -29,11 +37,13 @@ import (
 	locketmodels "code.cloudfoundry.org/locket/models"
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/maintain"
+	"code.cloudfoundry.org/tlsconfig"
 	"github.com/hashicorp/consul/api"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
 	. "github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
 	"github.com/tedsuo/ifrit"
 	"github.com/tedsuo/ifrit/ginkgomon"
 )
@@ -70,6 +80,7 @@ func exampleTaskDefinition() *models.TaskDefinition {
 var _ = Describe("Auctioneer", func() {
 	var (
 		auctioneerConfig config.AuctioneerConfig
+		configFilePath   string
 
 		runner            *ginkgomon.Runner
 		auctioneerProcess ifrit.Process
@@ -153,16 +164,17 @@ var _ = Describe("Auctioneer", func() {
 		encoder := json.NewEncoder(configFile)
 		err = encoder.Encode(&auctioneerConfig)
 		Expect(err).NotTo(HaveOccurred())
+		configFilePath = configFile.Name()
 
 		runner = ginkgomon.New(ginkgomon.Config{
 			Name: "auctioneer",
 			Command: exec.Command(
 				auctioneerPath,
-				"-config", configFile.Name(),
+				"-config", configFilePath,
 			),
 			StartCheck: "auctioneer.started",
 			Cleanup: func() {
-				os.RemoveAll(configFile.Name())
+				os.RemoveAll(configFilePath)
 			},
 		})
 	})
@@ -174,6 +186,62 @@ var _ = Describe("Auctioneer", func() {
 		close(signalMetricsChan)
 	})
 
+	Context("when run with -validate-config", func() {
+		var configPath string
+
+		validate := func() *Session {
+			configFile, err := ioutil.TempFile("", "auctioneer-config")
+			Expect(err).NotTo(HaveOccurred())
+			configPath = configFile.Name()
+
+			Expect(json.NewEncoder(configFile).Encode(&auctioneerConfig)).To(Succeed())
+			Expect(configFile.Close()).To(Succeed())
+
+			session, err := Start(exec.Command(auctioneerPath, "-config", configPath, "-validate-config"), GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			return session
+		}
+
+		AfterEach(func() {
+			os.RemoveAll(configPath)
+		})
+
+		It("exits successfully without starting when the config is valid", func() {
+			session := validate()
+			Eventually(session).Should(Exit(0))
+			Expect(session).To(gbytes.Say("auctioneer.config-valid"))
+			Expect(session).NotTo(gbytes.Say("auctioneer.started"))
+		})
+
+		It("reports every problem with an invalid config", func() {
+			auctioneerConfig.BBSAddress = ""
+			auctioneerConfig.AuctionRunnerWorkers = 0
+
+			session := validate()
+			Eventually(session).Should(Exit())
+			Expect(session.ExitCode()).NotTo(Equal(0))
+			Expect(session).To(gbytes.Say("auctioneer.invalid-config.*bbs_address: is required.*auction_runner_workers: must be greater than zero"))
+		})
+	})
+
+	Context("when the config file has an unknown property", func() {
+		It("exits with an error", func() {
+			configFile, err := ioutil.TempFile("", "auctioneer-config")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(configFile.Name())
+
+			_, err = configFile.WriteString(`{"not_a_property": true}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(configFile.Close()).To(Succeed())
+
+			session, err := Start(exec.Command(auctioneerPath, "-config", configFile.Name()), GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(Exit())
+			Expect(session.ExitCode()).NotTo(Equal(0))
+			Expect(session).To(gbytes.Say("auctioneer.failed-to-load-config.*not_a_property"))
+		})
+	})
+
 	Context("when the metron agent isn't up", func() {
 		BeforeEach(func() {
 			testIngressServer.Stop()
@@ -183,6 +251,17 @@ var _ = Describe("Auctioneer", func() {
 			auctioneerProcess = ifrit.Background(runner)
 			Eventually(auctioneerProcess.Wait()).Should(Receive(HaveOccurred()))
 		})
+
+		Context("when metrics are not sent to loggregator", func() {
+			BeforeEach(func() {
+				auctioneerConfig.MetricsSink = "none"
+			})
+
+			It("starts", func() {
+				auctioneerProcess = ginkgomon.Invoke(runner)
+				Consistently(runner).ShouldNot(Exit())
+			})
+		})
 	})
 
 	Context("when the bbs is down", func() {
@@ -255,6 +334,377 @@ var _ = Describe("Auctioneer", func() {
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
+
+		Context("when a prometheus listen address is specified", func() {
+			BeforeEach(func() {
+				port, err := portAllocator.ClaimPorts(1)
+				Expect(err).NotTo(HaveOccurred())
+				auctioneerConfig.PrometheusListenAddress = fmt.Sprintf("127.0.0.1:%d", port)
+			})
+
+			It("serves metrics in the prometheus format", func() {
+				auctioneerProcess = ginkgomon.Invoke(runner)
+
+				resp, err := http.Get(fmt.Sprintf("http://%s/metrics", auctioneerConfig.PrometheusListenAddress))
+				Expect(err).NotTo(HaveOccurred())
+				defer resp.Body.Close()
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			})
+		})
+
+		Context("when a tracing collector address is specified", func() {
+			var collector *ghttp.Server
+
+			BeforeEach(func() {
+				collector = ghttp.NewServer()
+				collector.RouteToHandler("POST", "/v1/traces", ghttp.RespondWith(http.StatusOK, nil))
+				auctioneerConfig.TracingCollectorAddress = collector.Addr()
+				auctioneerConfig.TracingCollectorInsecure = true
+			})
+
+			AfterEach(func() {
+				collector.Close()
+			})
+
+			It("exports spans for auction requests to the collector", func() {
+				auctioneerProcess = ginkgomon.Invoke(runner)
+
+				err := auctioneerClient.RequestTaskAuctions(logger, []*auctioneer.TaskStartRequest{})
+				Expect(err).NotTo(HaveOccurred())
+
+				ginkgomon.Interrupt(auctioneerProcess)
+				Eventually(collector.ReceivedRequests).ShouldNot(BeEmpty())
+			})
+		})
+
+		Context("when it is interrupted", func() {
+			It("drains pending work before the rest of the group exits", func() {
+				auctioneerProcess = ginkgomon.Invoke(runner)
+
+				ginkgomon.Interrupt(auctioneerProcess)
+				Expect(runner).To(gbytes.Say("auctioneer.drain.started"))
+				Expect(runner).To(gbytes.Say("auctioneer.drain.finished"))
+				Expect(runner).To(gbytes.Say("auctioneer.exited"))
+			})
+		})
+
+		Context("when a pending work snapshot path is specified", func() {
+			var snapshotDir string
+
+			BeforeEach(func() {
+				var err error
+				snapshotDir, err = ioutil.TempDir("", "pending-work")
+				Expect(err).NotTo(HaveOccurred())
+				auctioneerConfig.PendingWorkSnapshotPath = path.Join(snapshotDir, "pending-work.json")
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(snapshotDir)
+			})
+
+			It("restores the pending work on startup and checkpoints it on shutdown", func() {
+				auctioneerProcess = ginkgomon.Invoke(runner)
+				Expect(runner).To(gbytes.Say("auctioneer.pending-work-restorer.restored"))
+
+				ginkgomon.Interrupt(auctioneerProcess)
+				Expect(auctioneerConfig.PendingWorkSnapshotPath).To(BeAnExistingFile())
+			})
+		})
+
+		Context("when it receives SIGHUP", func() {
+			var writeConfig = func(cfg config.AuctioneerConfig) {
+				configFile, err := os.Create(configFilePath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(json.NewEncoder(configFile).Encode(&cfg)).To(Succeed())
+				Expect(configFile.Close()).To(Succeed())
+			}
+
+			JustBeforeEach(func() {
+				auctioneerProcess = ginkgomon.Invoke(runner)
+			})
+
+			It("applies the new tuning parameters without restarting", func() {
+				reloaded := auctioneerConfig
+				reloaded.StartingContainerWeight = .5
+				reloaded.CellStateTimeout = durationjson.Duration(2 * time.Second)
+				writeConfig(reloaded)
+
+				auctioneerProcess.Signal(syscall.SIGHUP)
+				Eventually(runner).Should(gbytes.Say("auctioneer.reloader.reload.reloaded"))
+				Eventually(runner).Should(gbytes.Say("auctioneer.tunable-auction-runner.replace.replaced"))
+				Consistently(auctioneerProcess.Wait()).ShouldNot(Receive())
+			})
+
+			It("applies new quotas without restarting", func() {
+				reloaded := auctioneerConfig
+				reloaded.Quotas = quota.Config{
+					Domains: map[string]quota.Quota{"ci": {MemoryMB: 1024}},
+				}
+				writeConfig(reloaded)
+
+				auctioneerProcess.Signal(syscall.SIGHUP)
+				Eventually(runner).Should(gbytes.Say(`auctioneer.quota-enforcer.updated.*"domains":1`))
+				Eventually(runner).Should(gbytes.Say("auctioneer.reloader.reload.reloaded"))
+				Consistently(auctioneerProcess.Wait()).ShouldNot(Receive())
+			})
+
+			It("logs the changed properties that require a restart", func() {
+				reloaded := auctioneerConfig
+				reloaded.ReportInterval = durationjson.Duration(time.Minute)
+				writeConfig(reloaded)
+
+				auctioneerProcess.Signal(syscall.SIGHUP)
+				Eventually(runner).Should(gbytes.Say(`auctioneer.reloader.reload.restart-required.*"fields":\["report_interval"\]`))
+				Eventually(runner).Should(gbytes.Say("auctioneer.reloader.reload.reloaded"))
+			})
+
+			It("keeps running with the current parameters when the configuration is invalid", func() {
+				reloaded := auctioneerConfig
+				reloaded.BBSAddress = ""
+				writeConfig(reloaded)
+
+				auctioneerProcess.Signal(syscall.SIGHUP)
+				Eventually(runner).Should(gbytes.Say("auctioneer.reloader.reload.failed-to-reload.*bbs_address: is required"))
+				Consistently(auctioneerProcess.Wait()).ShouldNot(Receive())
+			})
+		})
+
+		Context("when admission limits are configured", func() {
+			BeforeEach(func() {
+				auctioneerConfig.MaxItemsPerRequest = 1
+				auctioneerConfig.AdmissionRetryAfter = durationjson.Duration(7 * time.Second)
+			})
+
+			JustBeforeEach(func() {
+				auctioneerProcess = ginkgomon.Invoke(runner)
+			})
+
+			It("responds 429 with Retry-After to requests over the limit", func() {
+				resp, err := http.Post("http://"+auctioneerLocation+"/v1/tasks", "application/json", strings.NewReader(`[{"task_guid":"a"},{"task_guid":"b"}]`))
+				Expect(err).NotTo(HaveOccurred())
+				defer resp.Body.Close()
+
+				Expect(resp.StatusCode).To(Equal(http.StatusTooManyRequests))
+				Expect(resp.Header.Get("Retry-After")).To(Equal("7"))
+				Eventually(runner).Should(gbytes.Say("auctioneer.admit.throttled"))
+			})
+		})
+
+		Context("when domain limits are configured", func() {
+			BeforeEach(func() {
+				auctioneerConfig.DomainLimits = domainlimit.Config{
+					Domains: map[string]domainlimit.Limit{
+						"ci": {Rate: 0.1, Burst: 1},
+					},
+				}
+			})
+
+			JustBeforeEach(func() {
+				auctioneerProcess = ginkgomon.Invoke(runner)
+			})
+
+			submit := func(taskGuid string) *http.Response {
+				task := rep.NewTask(taskGuid, "ci", rep.NewResource(1, 1, 1), rep.NewPlacementConstraint(linuxRootFSURL, nil, nil))
+				payload, err := json.Marshal([]auctioneer.TaskStartRequest{auctioneer.NewTaskStartRequest(task)})
+				Expect(err).NotTo(HaveOccurred())
+
+				resp, err := http.Post("http://"+auctioneerLocation+"/v1/tasks", "application/json", bytes.NewReader(payload))
+				Expect(err).NotTo(HaveOccurred())
+				resp.Body.Close()
+				return resp
+			}
+
+			It("throttles domains over their rate", func() {
+				Expect(submit("ci-task-1").StatusCode).To(Equal(http.StatusAccepted))
+
+				resp := submit("ci-task-2")
+				Expect(resp.StatusCode).To(Equal(http.StatusTooManyRequests))
+				Expect(resp.Header.Get("Retry-After")).To(Equal("10"))
+				Eventually(runner).Should(gbytes.Say(`auctioneer.domain-limiter.throttled.*"domain":"ci"`))
+			})
+		})
+
+		Context("when an admin listen address is specified", func() {
+			var (
+				adminURL       string
+				adminTLSConfig *tls.Config
+				adminClient    *http.Client
+			)
+
+			BeforeEach(func() {
+				port, err := portAllocator.ClaimPorts(1)
+				Expect(err).NotTo(HaveOccurred())
+				auctioneerConfig.AdminListenAddress = fmt.Sprintf("127.0.0.1:%d", port)
+				auctioneerConfig.AdminCACertFile = "fixtures/green-certs/ca.crt"
+				auctioneerConfig.AdminServerCertFile = "fixtures/green-certs/server.crt"
+				auctioneerConfig.AdminServerKeyFile = "fixtures/green-certs/server.key"
+				adminURL = "https://" + auctioneerConfig.AdminListenAddress
+
+				adminTLSConfig, err = tlsconfig.Build(
+					tlsconfig.WithInternalServiceDefaults(),
+					tlsconfig.WithIdentityFromFile("fixtures/green-certs/client.crt", "fixtures/green-certs/client.key"),
+				).Client(tlsconfig.WithAuthorityFromFile("fixtures/green-certs/ca.crt"))
+				Expect(err).NotTo(HaveOccurred())
+				adminClient = &http.Client{Transport: &http.Transport{TLSClientConfig: adminTLSConfig}}
+			})
+
+			JustBeforeEach(func() {
+				auctioneerProcess = ginkgomon.Invoke(runner)
+			})
+
+			It("serves the status on the admin listener only", func() {
+				resp, err := adminClient.Get(adminURL + "/v1/status")
+				Expect(err).NotTo(HaveOccurred())
+				defer resp.Body.Close()
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+				status := handlers.AdminStatus{}
+				Expect(json.NewDecoder(resp.Body).Decode(&status)).To(Succeed())
+				Expect(status.Paused).To(BeFalse())
+				Expect(status.StartedAt).NotTo(BeZero())
+
+				resp, err = http.Get("http://" + auctioneerLocation + "/v1/status")
+				Expect(err).NotTo(HaveOccurred())
+				resp.Body.Close()
+				Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+			})
+
+			It("does not serve auction requests on the admin listener", func() {
+				client, err := auctioneer.NewSecureClient(adminURL, "fixtures/green-certs/ca.crt", "fixtures/green-certs/client.crt", "fixtures/green-certs/client.key", true, defaultAuctioneerClientRequestTimeout)
+				Expect(err).NotTo(HaveOccurred())
+				err = client.RequestTaskAuctions(logger, []*auctioneer.TaskStartRequest{})
+				Expect(err).To(MatchError(ContainSubstring("404")))
+			})
+
+			It("pauses and resumes auctions", func() {
+				resp, err := adminClient.Post(adminURL+"/v1/pause", "application/json", nil)
+				Expect(err).NotTo(HaveOccurred())
+				resp.Body.Close()
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				Eventually(runner).Should(gbytes.Say("auctioneer.pause.paused"))
+
+				resp, err = adminClient.Post(adminURL+"/v1/resume", "application/json", nil)
+				Expect(err).NotTo(HaveOccurred())
+				resp.Body.Close()
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				Eventually(runner).Should(gbytes.Say("auctioneer.pause.resumed"))
+			})
+
+			It("cordons cells", func() {
+				request, err := http.NewRequest("PUT", adminURL+"/v1/cordons/cell-a", nil)
+				Expect(err).NotTo(HaveOccurred())
+				resp, err := adminClient.Do(request)
+				Expect(err).NotTo(HaveOccurred())
+				defer resp.Body.Close()
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+				cordoned := []string{}
+				Expect(json.NewDecoder(resp.Body).Decode(&cordoned)).To(Succeed())
+				Expect(cordoned).To(Equal([]string{"cell-a"}))
+			})
+
+			Context("when quotas are configured", func() {
+				BeforeEach(func() {
+					auctioneerConfig.Quotas = quota.Config{
+						PlacementTags: map[string]quota.Quota{"gpu": {Containers: 4}},
+					}
+				})
+
+				It("serves the quotas", func() {
+					resp, err := adminClient.Get(adminURL + "/v1/quotas")
+					Expect(err).NotTo(HaveOccurred())
+					defer resp.Body.Close()
+					Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+					quotas := handlers.AdminQuotas{}
+					Expect(json.NewDecoder(resp.Body).Decode(&quotas)).To(Succeed())
+					Expect(quotas.Quotas).To(Equal(auctioneerConfig.Quotas))
+				})
+			})
+
+			It("requires a client certificate signed by the admin CA", func() {
+				withoutCertificate := adminTLSConfig.Clone()
+				withoutCertificate.Certificates = nil
+				client := &http.Client{Transport: &http.Transport{TLSClientConfig: withoutCertificate}}
+				_, err := client.Get(adminURL + "/v1/status")
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when the listen address is an IPv6 literal", func() {
+			BeforeEach(func() {
+				auctioneerConfig.ListenAddress = fmt.Sprintf("[::1]:%d", auctioneerServerPort)
+			})
+
+			It("serves auction requests", func() {
+				auctioneerProcess = ginkgomon.Invoke(runner)
+
+				client := auctioneer.NewClient(fmt.Sprintf("http://[::1]:%d", auctioneerServerPort), defaultAuctioneerClientRequestTimeout)
+				Eventually(func() error {
+					return client.RequestTaskAuctions(logger, []*auctioneer.TaskStartRequest{})
+				}).ShouldNot(HaveOccurred())
+			})
+		})
+
+		Context("when the listen address is a unix socket", func() {
+			var socketDir, socketPath string
+
+			BeforeEach(func() {
+				var err error
+				socketDir, err = ioutil.TempDir("", "auctioneer-socket")
+				Expect(err).NotTo(HaveOccurred())
+				socketPath = path.Join(socketDir, "auctioneer.sock")
+				auctioneerConfig.ListenAddress = "unix://" + socketPath
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(socketDir)
+			})
+
+			It("serves auction requests on the socket", func() {
+				auctioneerProcess = ginkgomon.Invoke(runner)
+
+				client := auctioneer.NewClient("unix://"+socketPath, defaultAuctioneerClientRequestTimeout)
+				Eventually(func() error {
+					return client.RequestTaskAuctions(logger, []*auctioneer.TaskStartRequest{})
+				}).ShouldNot(HaveOccurred())
+			})
+
+			It("publishes the socket address in its presence", func() {
+				auctioneerProcess = ginkgomon.Invoke(runner)
+
+				serviceClient := auctioneer.NewServiceClient(consulClient, clock.NewClock())
+				var presence auctioneer.Presence
+				Eventually(func() error {
+					var err error
+					presence, err = serviceClient.CurrentAuctioneer()
+					return err
+				}).ShouldNot(HaveOccurred())
+
+				Expect(presence.AuctioneerAddress).To(Equal("unix://" + socketPath))
+				Expect(presence.Scheme).To(Equal(auctioneer.SchemeHTTP))
+				Expect(presence.IsUnixSocket()).To(BeTrue())
+			})
+
+			Context("when a socket was left behind by a previous auctioneer", func() {
+				BeforeEach(func() {
+					listener, err := net.Listen("unix", socketPath)
+					Expect(err).NotTo(HaveOccurred())
+					listener.(*net.UnixListener).SetUnlinkOnClose(false)
+					Expect(listener.Close()).To(Succeed())
+					Expect(socketPath).To(BeAnExistingFile())
+				})
+
+				It("replaces it", func() {
+					auctioneerProcess = ginkgomon.Invoke(runner)
+
+					client := auctioneer.NewClient("unix://"+socketPath, defaultAuctioneerClientRequestTimeout)
+					Eventually(func() error {
+						return client.RequestTaskAuctions(logger, []*auctioneer.TaskStartRequest{})
+					}).ShouldNot(HaveOccurred())
+				})
+			})
+		})
 	})
 
 	Context("with cells of different stacks", func() {
@@ -397,6 +847,35 @@ var _ = Describe("Auctioneer", func() {
 					Expect(completedTask.FailureReason).To(Equal("insufficient resources: disk, memory"))
 				})
 			})
+
+			Context("when the task would exceed the quota of its domain", func() {
+				BeforeEach(func() {
+					auctioneerConfig.Quotas = quota.Config{
+						Domains: map[string]quota.Quota{"domain": {MemoryMB: 1}},
+					}
+				})
+
+				It("should not place the task and mark it as failed with the quota error", func() {
+					auctioneerProcess = ginkgomon.Invoke(runner)
+
+					taskDef := exampleTaskDefinition()
+					taskDef.DiskMb = 1
+					taskDef.MemoryMb = 2
+					taskDef.MaxPids = 1
+					err := bbsClient.DesireTask(logger, "task-guid", "domain", taskDef)
+					Expect(err).NotTo(HaveOccurred())
+
+					Eventually(func() []*models.Task {
+						return getTasksByState(bbsClient, models.Task_Completed)
+					}).Should(HaveLen(1))
+					Expect(linuxCell.Tasks()).To(BeEmpty())
+
+					completedTask := getTasksByState(bbsClient, models.Task_Completed)[0]
+					Expect(completedTask.Failed).To(BeTrue())
+					Expect(completedTask.FailureReason).To(Equal(quota.PlacementError))
+					Eventually(runner).Should(gbytes.Say(`auctioneer.quota-enforcer.admit-tasks.refused.*"domain":"domain"`))
+				})
+			})
 		})
 	})
 
@@ -499,6 +978,14 @@ var _ = Describe("Auctioneer", func() {
 			}).Should(HaveOccurred())
 		})
 
+		It("keeps waiting for the lock when it receives SIGHUP", func() {
+			Eventually(runner).Should(gbytes.Say("auctioneer.reloader.started"))
+
+			auctioneerProcess.Signal(syscall.SIGHUP)
+			Eventually(runner).Should(gbytes.Say("auctioneer.reloader.reload.reloaded"))
+			Consistently(auctioneerProcess.Wait()).ShouldNot(Receive())
+		})
+
 		It("should eventually come up in the event that the lock is released", func() {
 			ginkgomon.Kill(competingAuctioneerProcess)
 
@@ -577,6 +1064,25 @@ var _ = Describe("Auctioneer", func() {
 			Expect(lock.Resource.Owner).To(Equal(auctioneerConfig.UUID))
 		})
 
+		It("can be found through the locket service client", func() {
+			locketClient, err := locket.NewClient(logger, auctioneerConfig.ClientLocketConfig)
+			Expect(err).NotTo(HaveOccurred())
+			serviceClient := auctioneer.NewLocketServiceClient(locketClient, clock.NewClock())
+
+			var presence auctioneer.Presence
+			Eventually(func() error {
+				presence, err = serviceClient.CurrentAuctioneer()
+				return err
+			}).ShouldNot(HaveOccurred())
+
+			Expect(presence.AuctioneerID).To(Equal(auctioneerConfig.UUID))
+			Expect(presence.AuctioneerAddress).To(HaveSuffix(fmt.Sprintf(":%d", auctioneerServerPort)))
+			Expect(presence.Scheme).To(Equal(auctioneer.SchemeHTTP))
+			Expect(presence.APIVersions).To(Equal(auctioneer.SupportedAPIVersions))
+			Expect(presence.StartedAt).NotTo(BeZero())
+			Expect(presence.Validate()).To(Succeed())
+		})
+
 		It("emits metric about holding lock", func() {
 			Eventually(func() error {
 				return auctioneerClient.RequestTaskAuctions(logger, []*auctioneer.TaskStartRequest{
@@ -730,6 +1236,63 @@ var _ = Describe("Auctioneer", func() {
 		})
 	})
 
+	Context("when the auctioneer is configured with a local lock mode", func() {
+		BeforeEach(func() {
+			auctioneerConfig.SkipConsulLock = true
+			auctioneerConfig.LocksLocketEnabled = false
+			auctioneerConfig.ConsulCluster = ""
+		})
+
+		Context("and the mode is always_leader", func() {
+			BeforeEach(func() {
+				auctioneerConfig.LockMode = "always_leader"
+			})
+
+			It("starts without consul or locket and accepts auctions", func() {
+				auctioneerProcess = ginkgomon.Invoke(runner)
+
+				err := auctioneerClient.RequestTaskAuctions(logger, []*auctioneer.TaskStartRequest{})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("and the mode is file", func() {
+			var lockDir string
+
+			BeforeEach(func() {
+				var err error
+				lockDir, err = ioutil.TempDir("", "auctioneer-lock")
+				Expect(err).NotTo(HaveOccurred())
+
+				auctioneerConfig.LockMode = "file"
+				auctioneerConfig.LockFilePath = path.Join(lockDir, "auctioneer.lock")
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(lockDir)
+			})
+
+			It("acquires the file lock and accepts auctions", func() {
+				auctioneerProcess = ginkgomon.Invoke(runner)
+
+				Expect(runner).To(gbytes.Say("file-lock.acquired-lock"))
+				err := auctioneerClient.RequestTaskAuctions(logger, []*auctioneer.TaskStartRequest{})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("and the mode is unknown", func() {
+			BeforeEach(func() {
+				auctioneerConfig.LockMode = "bogus"
+			})
+
+			It("exits with an error", func() {
+				auctioneerProcess = ifrit.Background(runner)
+				Eventually(auctioneerProcess.Wait()).Should(Receive(HaveOccurred()))
+			})
+		})
+	})
+
 	Context("when the auctioneer is configured with TLS options", func() {
 		var caCertFile, serverCertFile, serverKeyFile string
 
@@ -775,7 +1338,7 @@ var _ = Describe("Auctioneer", func() {
 
 				It("fails", func() {
 					Eventually(runner.Buffer()).Should(gbytes.Say(
-						"invalid-tls-config"))
+						"invalid-config.*server_cert_file: is required"))
 					Eventually(runner.ExitCode()).ShouldNot(Equal(0))
 				})
 			})
@@ -789,7 +1352,7 @@ var _ = Describe("Auctioneer", func() {
 
 				It("fails", func() {
 					Eventually(runner.Buffer()).Should(gbytes.Say(
-						"invalid-tls-config"))
+						"invalid-config.*server_cert_file: is required"))
 					Eventually(runner.ExitCode()).ShouldNot(Equal(0))
 				})
 			})
@@ -803,7 +1366,7 @@ var _ = Describe("Auctioneer", func() {
 
 				It("fails", func() {
 					Eventually(runner.Buffer()).Should(gbytes.Say(
-						"invalid-tls-config"))
+						"invalid-config.*server_key_file: is required"))
 					Eventually(runner.ExitCode()).ShouldNot(Equal(0))
 				})
 			})
@@ -839,6 +1402,59 @@ var _ = Describe("Auctioneer", func() {
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
+
+		Context("when the server certificates are rotated", func() {
+			var certsDir string
+
+			install := func(fixture string) {
+				for _, name := range []string{"ca.crt", "server.crt", "server.key"} {
+					data, err := ioutil.ReadFile(path.Join("fixtures", fixture, name))
+					Expect(err).NotTo(HaveOccurred())
+					Expect(ioutil.WriteFile(path.Join(certsDir, name), data, 0600)).To(Succeed())
+				}
+			}
+
+			request := func(fixture string) error {
+				client, err := auctioneer.NewSecureClient(
+					"https://"+auctioneerLocation,
+					path.Join("fixtures", fixture, "ca.crt"),
+					path.Join("fixtures", fixture, "client.crt"),
+					path.Join("fixtures", fixture, "client.key"),
+					true,
+					defaultAuctioneerClientRequestTimeout,
+				)
+				Expect(err).NotTo(HaveOccurred())
+				return client.RequestLRPAuctions(logger, nil)
+			}
+
+			BeforeEach(func() {
+				var err error
+				certsDir, err = ioutil.TempDir("", "auctioneer-certs")
+				Expect(err).NotTo(HaveOccurred())
+				install("green-certs")
+
+				auctioneerConfig.CACertFile = path.Join(certsDir, "ca.crt")
+				auctioneerConfig.ServerCertFile = path.Join(certsDir, "server.crt")
+				auctioneerConfig.ServerKeyFile = path.Join(certsDir, "server.key")
+				auctioneerConfig.CertificateReloadInterval = durationjson.Duration(100 * time.Millisecond)
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(certsDir)
+			})
+
+			It("serves the new certificates without restarting", func() {
+				Eventually(auctioneerProcess.Ready()).Should(BeClosed())
+				Expect(request("green-certs")).To(Succeed())
+
+				install("blue-certs")
+				Eventually(runner.Buffer()).Should(gbytes.Say("certificate-watcher.reload.reloaded"))
+
+				Expect(request("blue-certs")).To(Succeed())
+				Expect(request("green-certs")).NotTo(Succeed())
+				Consistently(runner).ShouldNot(Exit())
+			})
+		})
 	})
 
 	Context("Auctioneer Client", func() {
@@ -890,6 +1506,24 @@ var _ = Describe("Auctioneer", func() {
 					err = client.RequestTaskAuctions(logger, []*auctioneer.TaskStartRequest{})
 					Expect(err).NotTo(HaveOccurred())
 				})
+
+				Context("and only other client identities are authorized for task auctions", func() {
+					BeforeEach(func() {
+						auctioneerConfig.AuthorizedClients = map[string][]string{
+							auctioneer.CreateTaskAuctionsRoute: {"bbs"},
+							auctioneer.CreateLRPAuctionsRoute:  {"client"},
+						}
+					})
+
+					It("forbids task auctions but accepts LRP auctions", func() {
+						err := client.RequestTaskAuctions(logger, []*auctioneer.TaskStartRequest{})
+						Expect(err).To(MatchError(ContainSubstring("403")))
+						Eventually(runner.Buffer()).Should(gbytes.Say(`authorize.forbidden.*"route":"CreateTaskAuctions"`))
+
+						err = client.RequestLRPAuctions(logger, []*auctioneer.LRPStartRequest{})
+						Expect(err).NotTo(HaveOccurred())
+					})
+				})
 			})
 		})
 
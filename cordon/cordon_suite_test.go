@@ -0,0 +1,13 @@
+package cordon_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestCordon(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cordon Suite")
+}
@@ -0,0 +1,104 @@
+package cordon
+
+import (
+	"sort"
+	"sync"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// Cordons is the set of cells operators have taken out of placement. A
+// cordoned cell keeps the work it already has but is not offered new work.
+// Cordons are held in memory by the auctioneer holding the lock, so they do
+// not survive a restart or a change of leader.
+type Cordons struct {
+	logger lager.Logger
+
+	lock  sync.RWMutex
+	cells map[string]struct{}
+}
+
+func New(logger lager.Logger) *Cordons {
+	return &Cordons{
+		logger: logger.Session("cordons"),
+		cells:  map[string]struct{}{},
+	}
+}
+
+// Cordon takes the cell out of placement. It returns false if the cell was
+// already cordoned.
+func (c *Cordons) Cordon(cellID string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.cells[cellID]; ok {
+		return false
+	}
+
+	c.cells[cellID] = struct{}{}
+	c.logger.Info("cordoned", lager.Data{"cell-id": cellID})
+	return true
+}
+
+// Uncordon returns the cell to placement. It returns false if the cell was
+// not cordoned.
+func (c *Cordons) Uncordon(cellID string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.cells[cellID]; !ok {
+		return false
+	}
+
+	delete(c.cells, cellID)
+	c.logger.Info("uncordoned", lager.Data{"cell-id": cellID})
+	return true
+}
+
+func (c *Cordons) IsCordoned(cellID string) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	_, ok := c.cells[cellID]
+	return ok
+}
+
+// List returns the cordoned cells, sorted by ID.
+func (c *Cordons) List() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	cells := make([]string, 0, len(c.cells))
+	for cellID := range c.cells {
+		cells = append(cells, cellID)
+	}
+	sort.Strings(cells)
+	return cells
+}
+
+// Delegate wraps an auction runner delegate so that cordoned cells are left
+// out of every auction.
+func (c *Cordons) Delegate(delegate auctiontypes.AuctionRunnerDelegate) auctiontypes.AuctionRunnerDelegate {
+	return &cordonedDelegate{AuctionRunnerDelegate: delegate, cordons: c}
+}
+
+type cordonedDelegate struct {
+	auctiontypes.AuctionRunnerDelegate
+	cordons *Cordons
+}
+
+func (d *cordonedDelegate) FetchCellReps() (map[string]rep.Client, error) {
+	cellReps, err := d.AuctionRunnerDelegate.FetchCellReps()
+	if err != nil {
+		return cellReps, err
+	}
+
+	for cellID := range cellReps {
+		if d.cordons.IsCordoned(cellID) {
+			delete(cellReps, cellID)
+		}
+	}
+	return cellReps, nil
+}
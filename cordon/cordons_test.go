@@ -0,0 +1,103 @@
+package cordon_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auctioneer/cordon"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/repfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeDelegate struct {
+	cellReps  map[string]rep.Client
+	err       error
+	completed []auctiontypes.AuctionResults
+}
+
+func (d *fakeDelegate) FetchCellReps() (map[string]rep.Client, error) {
+	cellReps := map[string]rep.Client{}
+	for cellID, client := range d.cellReps {
+		cellReps[cellID] = client
+	}
+	return cellReps, d.err
+}
+
+func (d *fakeDelegate) AuctionCompleted(results auctiontypes.AuctionResults) {
+	d.completed = append(d.completed, results)
+}
+
+var _ = Describe("Cordons", func() {
+	var cordons *cordon.Cordons
+
+	BeforeEach(func() {
+		cordons = cordon.New(lagertest.NewTestLogger("test"))
+	})
+
+	It("starts with no cordoned cells", func() {
+		Expect(cordons.List()).To(BeEmpty())
+		Expect(cordons.IsCordoned("cell-a")).To(BeFalse())
+	})
+
+	It("cordons and uncordons cells", func() {
+		Expect(cordons.Cordon("cell-b")).To(BeTrue())
+		Expect(cordons.Cordon("cell-a")).To(BeTrue())
+		Expect(cordons.Cordon("cell-a")).To(BeFalse())
+		Expect(cordons.IsCordoned("cell-a")).To(BeTrue())
+		Expect(cordons.List()).To(Equal([]string{"cell-a", "cell-b"}))
+
+		Expect(cordons.Uncordon("cell-a")).To(BeTrue())
+		Expect(cordons.Uncordon("cell-a")).To(BeFalse())
+		Expect(cordons.IsCordoned("cell-a")).To(BeFalse())
+		Expect(cordons.List()).To(Equal([]string{"cell-b"}))
+	})
+
+	Describe("Delegate", func() {
+		var (
+			inner    *fakeDelegate
+			delegate auctiontypes.AuctionRunnerDelegate
+		)
+
+		BeforeEach(func() {
+			inner = &fakeDelegate{cellReps: map[string]rep.Client{
+				"cell-a": &repfakes.FakeClient{},
+				"cell-b": &repfakes.FakeClient{},
+			}}
+			delegate = cordons.Delegate(inner)
+		})
+
+		It("leaves cordoned cells out of the auction", func() {
+			cordons.Cordon("cell-a")
+
+			cellReps, err := delegate.FetchCellReps()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cellReps).To(HaveLen(1))
+			Expect(cellReps).To(HaveKey("cell-b"))
+		})
+
+		It("offers uncordoned cells again", func() {
+			cordons.Cordon("cell-a")
+			cordons.Uncordon("cell-a")
+
+			cellReps, err := delegate.FetchCellReps()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cellReps).To(HaveLen(2))
+		})
+
+		It("returns errors from the delegate", func() {
+			inner.err = errors.New("bbs unavailable")
+
+			_, err := delegate.FetchCellReps()
+			Expect(err).To(MatchError("bbs unavailable"))
+		})
+
+		It("passes auction results to the delegate", func() {
+			delegate.AuctionCompleted(auctiontypes.AuctionResults{})
+			Expect(inner.completed).To(HaveLen(1))
+		})
+	})
+})
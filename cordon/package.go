@@ -0,0 +1 @@
+package cordon // import "code.cloudfoundry.org/auctioneer/cordon"
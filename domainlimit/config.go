@@ -0,0 +1,47 @@
+package domainlimit
+
+// Config sets the rate limit and fair-queueing weight of each domain. Domains
+// not listed use Default.
+type Config struct {
+	Default Limit            `json:"default"`
+	Domains map[string]Limit `json:"domains,omitempty"`
+}
+
+// Limit is a token bucket that refills at Rate work items per second and
+// holds up to Burst items, which defaults to one second's worth. A zero Rate
+// means the domain is not rate limited. Weight is the domain's share when
+// work from several domains is interleaved, and defaults to 1.
+type Limit struct {
+	Rate   float64 `json:"rate,omitempty"`
+	Burst  int     `json:"burst,omitempty"`
+	Weight int     `json:"weight,omitempty"`
+}
+
+// Enabled reports whether any domain is rate limited or weighted.
+func (c Config) Enabled() bool {
+	return c.Default != (Limit{}) || len(c.Domains) > 0
+}
+
+func (c Config) limit(domain string) Limit {
+	if limit, ok := c.Domains[domain]; ok {
+		return limit
+	}
+	return c.Default
+}
+
+func (l Limit) burst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	if l.Rate < 1 {
+		return 1
+	}
+	return l.Rate
+}
+
+func (l Limit) weight() int {
+	if l.Weight > 0 {
+		return l.Weight
+	}
+	return 1
+}
@@ -0,0 +1,13 @@
+package domainlimit_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestDomainlimit(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Domain Limit Suite")
+}
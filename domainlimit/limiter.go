@@ -0,0 +1,126 @@
+package domainlimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/metrics"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+)
+
+const (
+	// ThrottledWork counts the work items rejected for a domain, tagged with
+	// the domain.
+	ThrottledWork = "AuctioneerDomainThrottledWork"
+	DomainTag     = "domain"
+)
+
+// Limiter rate limits the work submitted for each domain and orders work so
+// that no domain can starve the others.
+type Limiter struct {
+	logger lager.Logger
+	clock  clock.Clock
+	sink   metrics.Sink
+	config Config
+
+	lock    sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+func New(logger lager.Logger, clock clock.Clock, sink metrics.Sink, config Config) *Limiter {
+	return &Limiter{
+		logger:  logger.Session("domain-limiter"),
+		clock:   clock,
+		sink:    sink,
+		config:  config,
+		buckets: map[string]*bucket{},
+	}
+}
+
+// Take removes tokens for n work items from the domain's bucket. If the bucket
+// cannot cover them, nothing is taken and Take returns false with how long the
+// bucket needs to refill. A domain is charged at most its burst, so work
+// larger than the burst is admitted once the bucket is full.
+func (l *Limiter) Take(domain string, n int) (bool, time.Duration) {
+	limit := l.config.limit(domain)
+	if limit.Rate <= 0 {
+		return true, 0
+	}
+
+	l.lock.Lock()
+	now := l.clock.Now()
+	b, ok := l.buckets[domain]
+	if !ok {
+		b = &bucket{tokens: limit.burst(), updatedAt: now}
+		l.buckets[domain] = b
+	}
+	b.tokens = math.Min(limit.burst(), b.tokens+now.Sub(b.updatedAt).Seconds()*limit.Rate)
+	b.updatedAt = now
+
+	cost := math.Min(float64(n), limit.burst())
+	if b.tokens >= cost {
+		b.tokens -= cost
+		l.lock.Unlock()
+		return true, 0
+	}
+
+	wait := time.Duration((cost - b.tokens) / limit.Rate * float64(time.Second))
+	l.lock.Unlock()
+
+	l.logger.Info("throttled", lager.Data{"domain": domain, "items": n, "retry-after": wait.String()})
+	err := l.sink.IncrementCounter(ThrottledWork, uint64(n), map[string]string{DomainTag: domain})
+	if err != nil {
+		l.logger.Error("failed-to-send-throttled-work", err, lager.Data{"domain": domain})
+	}
+
+	return false, wait
+}
+
+// Interleave returns the positions of work items with the given domains in
+// weighted-fair order: domains take turns in proportion to their weights, and
+// the items of each domain keep their relative order.
+func (l *Limiter) Interleave(domains []string) []int {
+	queues := map[string][]int{}
+	order := []string{}
+	for i, domain := range domains {
+		if _, ok := queues[domain]; !ok {
+			order = append(order, domain)
+		}
+		queues[domain] = append(queues[domain], i)
+	}
+
+	// Smooth weighted round robin: every turn each domain with work left
+	// gains its weight in credit, and the domain with the most credit is
+	// served and pays back the total weight.
+	credit := map[string]int{}
+	positions := make([]int, 0, len(domains))
+	for len(positions) < len(domains) {
+		total := 0
+		next := ""
+		for _, domain := range order {
+			if len(queues[domain]) == 0 {
+				continue
+			}
+
+			weight := l.config.limit(domain).weight()
+			total += weight
+			credit[domain] += weight
+			if next == "" || credit[domain] > credit[next] {
+				next = domain
+			}
+		}
+
+		credit[next] -= total
+		positions = append(positions, queues[next][0])
+		queues[next] = queues[next][1:]
+	}
+
+	return positions
+}
@@ -0,0 +1,121 @@
+package domainlimit_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/domainlimit"
+	"code.cloudfoundry.org/auctioneer/metrics/metricsfakes"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Limiter", func() {
+	var (
+		logger    *lagertest.TestLogger
+		fakeClock *fakeclock.FakeClock
+		fakeSink  *metricsfakes.FakeSink
+		config    domainlimit.Config
+		limiter   *domainlimit.Limiter
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		fakeSink = &metricsfakes.FakeSink{}
+		config = domainlimit.Config{
+			Default: domainlimit.Limit{Rate: 10, Burst: 20},
+			Domains: map[string]domainlimit.Limit{
+				"ci":        {Rate: 1, Burst: 2},
+				"unlimited": {Weight: 3},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		limiter = domainlimit.New(logger, fakeClock, fakeSink, config)
+	})
+
+	Describe("Take", func() {
+		It("allows a burst and then throttles until the bucket refills", func() {
+			Expect(limiter.Take("ci", 2)).To(BeTrue())
+
+			ok, wait := limiter.Take("ci", 1)
+			Expect(ok).To(BeFalse())
+			Expect(wait).To(Equal(time.Second))
+
+			fakeClock.Increment(time.Second)
+			ok, _ = limiter.Take("ci", 1)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("does not take any tokens when throttling", func() {
+			Expect(limiter.Take("ci", 1)).To(BeTrue())
+
+			ok, wait := limiter.Take("ci", 2)
+			Expect(ok).To(BeFalse())
+			Expect(wait).To(Equal(time.Second))
+
+			ok, _ = limiter.Take("ci", 1)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("limits each domain separately", func() {
+			Expect(limiter.Take("ci", 2)).To(BeTrue())
+
+			ok, _ := limiter.Take("other", 20)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("admits work larger than the burst once the bucket is full", func() {
+			ok, _ := limiter.Take("ci", 5)
+			Expect(ok).To(BeTrue())
+
+			ok, wait := limiter.Take("ci", 5)
+			Expect(ok).To(BeFalse())
+			Expect(wait).To(Equal(2 * time.Second))
+
+			fakeClock.Increment(2 * time.Second)
+			ok, _ = limiter.Take("ci", 5)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("does not limit domains without a rate", func() {
+			for i := 0; i < 100; i++ {
+				ok, _ := limiter.Take("unlimited", 100)
+				Expect(ok).To(BeTrue())
+			}
+		})
+
+		It("logs and emits the work throttled for the domain", func() {
+			Expect(limiter.Take("ci", 2)).To(BeTrue())
+			limiter.Take("ci", 1)
+			limiter.Take("ci", 3)
+
+			Expect(logger).To(gbytes.Say(`test.domain-limiter.throttled.*"domain":"ci"`))
+			Expect(fakeSink.IncrementCounterCallCount()).To(Equal(2))
+			name, delta, tags := fakeSink.IncrementCounterArgsForCall(1)
+			Expect(name).To(Equal(domainlimit.ThrottledWork))
+			Expect(delta).To(BeEquivalentTo(3))
+			Expect(tags).To(Equal(map[string]string{domainlimit.DomainTag: "ci"}))
+		})
+	})
+
+	Describe("Interleave", func() {
+		It("alternates between equally weighted domains", func() {
+			Expect(limiter.Interleave([]string{"a", "a", "a", "b", "b", "b"})).To(Equal([]int{0, 3, 1, 4, 2, 5}))
+		})
+
+		It("serves domains in proportion to their weights", func() {
+			domains := []string{"a", "a", "a", "unlimited", "unlimited", "unlimited", "unlimited", "unlimited", "unlimited"}
+			Expect(limiter.Interleave(domains)).To(Equal([]int{3, 0, 4, 5, 6, 1, 7, 8, 2}))
+		})
+
+		It("keeps every item when a domain runs out", func() {
+			Expect(limiter.Interleave([]string{"a", "b", "b", "b"})).To(Equal([]int{0, 1, 2, 3}))
+		})
+	})
+})
@@ -0,0 +1 @@
+package domainlimit // import "code.cloudfoundry.org/auctioneer/domainlimit"
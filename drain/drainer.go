@@ -0,0 +1,105 @@
+package drain
+
+import (
+	"os"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+)
+
+const (
+	DefaultTimeout      = 15 * time.Second
+	DefaultPollInterval = 100 * time.Millisecond
+)
+
+// PendingWork reports how many submitted work items are still waiting for an
+// auction result.
+type PendingWork interface {
+	Pending() int
+}
+
+// Pauser reports whether auctions are paused. Work held while they are will
+// not be auctioned before the drain times out, so there is no point waiting
+// for it.
+type Pauser interface {
+	Paused() bool
+}
+
+// Drainer holds up shutdown until in-flight work has been auctioned. It is
+// meant to be the last member of an ordered group so that it is signalled
+// first: once signalled it reports Draining, so the server can turn away new
+// submissions, and it only exits once no work is pending, the timeout has
+// expired or auctions are paused. Every member started before it, including
+// the locks, keeps running until then.
+type Drainer struct {
+	logger       lager.Logger
+	clock        clock.Clock
+	pending      PendingWork
+	pauser       Pauser
+	timeout      time.Duration
+	pollInterval time.Duration
+
+	draining int32
+}
+
+func New(logger lager.Logger, clock clock.Clock, pending PendingWork, pauser Pauser, timeout time.Duration) *Drainer {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &Drainer{
+		logger:       logger.Session("drain"),
+		clock:        clock,
+		pending:      pending,
+		pauser:       pauser,
+		timeout:      timeout,
+		pollInterval: DefaultPollInterval,
+	}
+}
+
+// Draining returns true once the drainer has been signalled.
+func (d *Drainer) Draining() bool {
+	return atomic.LoadInt32(&d.draining) == 1
+}
+
+func (d *Drainer) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	close(ready)
+
+	<-signals
+	atomic.StoreInt32(&d.draining, 1)
+
+	logger := d.logger
+	logger.Info("started", lager.Data{"pending": d.pending.Pending(), "timeout": d.timeout.String()})
+
+	timer := d.clock.NewTimer(d.timeout)
+	defer timer.Stop()
+
+	ticker := d.clock.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		pending := d.pending.Pending()
+		if pending == 0 {
+			logger.Info("finished")
+			return nil
+		}
+
+		// The held work is left for the BBS to resubmit, as it is when the
+		// drain times out.
+		if d.pauser.Paused() {
+			logger.Info("paused", lager.Data{"pending": pending})
+			return nil
+		}
+
+		select {
+		case <-ticker.C():
+		case <-timer.C():
+			// Work that never reached a cell is left for the BBS to resubmit
+			// to whichever auctioneer holds the lock next.
+			logger.Info("timed-out", lager.Data{"pending": pending})
+			return nil
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package drain_test
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/drain"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+type fakePendingWork struct {
+	lock    sync.Mutex
+	pending int
+}
+
+func (f *fakePendingWork) Pending() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.pending
+}
+
+func (f *fakePendingWork) set(pending int) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.pending = pending
+}
+
+type fakePauser struct {
+	lock   sync.Mutex
+	paused bool
+}
+
+func (f *fakePauser) Paused() bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.paused
+}
+
+func (f *fakePauser) set(paused bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.paused = paused
+}
+
+var _ = Describe("Drainer", func() {
+	var (
+		logger    *lagertest.TestLogger
+		fakeClock *fakeclock.FakeClock
+		pending   *fakePendingWork
+		pauser    *fakePauser
+		drainer   *drain.Drainer
+		process   ifrit.Process
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		pending = &fakePendingWork{}
+		pauser = &fakePauser{}
+		drainer = drain.New(logger, fakeClock, pending, pauser, 10*time.Second)
+		process = ginkgomon.Invoke(drainer)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Kill(process)
+	})
+
+	It("is not draining until it is signalled", func() {
+		Consistently(drainer.Draining).Should(BeFalse())
+		Consistently(process.Wait()).ShouldNot(Receive())
+	})
+
+	Context("when there is no pending work", func() {
+		It("exits as soon as it is signalled", func() {
+			process.Signal(os.Interrupt)
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+			Expect(drainer.Draining()).To(BeTrue())
+			Expect(logger).To(gbytes.Say("test.drain.finished"))
+		})
+	})
+
+	Context("when there is pending work", func() {
+		BeforeEach(func() {
+			pending.set(2)
+			process.Signal(os.Interrupt)
+			Eventually(drainer.Draining).Should(BeTrue())
+		})
+
+		It("waits until the work has been auctioned", func() {
+			Consistently(process.Wait()).ShouldNot(Receive())
+
+			pending.set(0)
+			Eventually(func() <-chan error {
+				fakeClock.Increment(drain.DefaultPollInterval)
+				return process.Wait()
+			}).Should(Receive(BeNil()))
+		})
+
+		It("gives up once the timeout expires", func() {
+			Eventually(fakeClock.WatcherCount).Should(Equal(2))
+			fakeClock.Increment(10 * time.Second)
+
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+			Expect(logger).To(gbytes.Say("test.drain.timed-out"))
+		})
+
+		It("stops waiting once auctions are paused", func() {
+			Consistently(process.Wait()).ShouldNot(Receive())
+
+			pauser.set(true)
+			Eventually(func() <-chan error {
+				fakeClock.Increment(drain.DefaultPollInterval)
+				return process.Wait()
+			}).Should(Receive(BeNil()))
+			Expect(logger).To(gbytes.Say("test.drain.paused"))
+		})
+	})
+})
@@ -0,0 +1 @@
+package drain // import "code.cloudfoundry.org/auctioneer/drain"
@@ -0,0 +1,77 @@
+package failurenotifier
+
+import (
+	"net/http"
+	"time"
+
+	cfhttp "code.cloudfoundry.org/cfhttp/v2"
+	"code.cloudfoundry.org/durationjson"
+	"code.cloudfoundry.org/tlsconfig"
+)
+
+const (
+	DefaultMaxRetries     = 3
+	DefaultRetryInterval  = time.Second
+	DefaultRequestTimeout = 10 * time.Second
+	DefaultQueueSize      = 100
+	DefaultFlushTimeout   = 5 * time.Second
+)
+
+// Config configures the Notifier. MaxRetries is a pointer so that an explicit
+// 0, which disables retries, can be told apart from leaving it unset.
+type Config struct {
+	Endpoints      []EndpointConfig      `json:"endpoints,omitempty"`
+	FlushTimeout   durationjson.Duration `json:"flush_timeout,omitempty"`
+	MaxRetries     *int                  `json:"max_retries,omitempty"`
+	QueueSize      int                   `json:"queue_size,omitempty"`
+	RequestTimeout durationjson.Duration `json:"request_timeout,omitempty"`
+	RetryInterval  durationjson.Duration `json:"retry_interval,omitempty"`
+}
+
+// EndpointConfig describes an HTTP endpoint that receives failure summaries.
+// The CA cert is used to verify the endpoint, and the client cert and key, when
+// set, are presented to it for mutual TLS. RequestTimeout, when set, overrides
+// the Config's request timeout for this endpoint.
+type EndpointConfig struct {
+	URL            string                `json:"url"`
+	CACertFile     string                `json:"ca_cert_file,omitempty"`
+	ClientCertFile string                `json:"client_cert_file,omitempty"`
+	ClientKeyFile  string                `json:"client_key_file,omitempty"`
+	RequestTimeout durationjson.Duration `json:"request_timeout,omitempty"`
+}
+
+type endpoint struct {
+	url    string
+	client *http.Client
+}
+
+func newEndpoint(cfg EndpointConfig, requestTimeout time.Duration) (endpoint, error) {
+	if cfg.RequestTimeout != 0 {
+		requestTimeout = time.Duration(cfg.RequestTimeout)
+	}
+
+	opts := []cfhttp.Option{cfhttp.WithRequestTimeout(requestTimeout)}
+
+	if cfg.CACertFile != "" || cfg.ClientCertFile != "" || cfg.ClientKeyFile != "" {
+		identityOpts := []tlsconfig.TLSOption{tlsconfig.WithExternalServiceDefaults()}
+		if cfg.ClientCertFile != "" || cfg.ClientKeyFile != "" {
+			identityOpts = append(identityOpts, tlsconfig.WithIdentityFromFile(cfg.ClientCertFile, cfg.ClientKeyFile))
+		}
+
+		clientOpts := []tlsconfig.ClientOption{}
+		if cfg.CACertFile != "" {
+			clientOpts = append(clientOpts, tlsconfig.WithAuthorityFromFile(cfg.CACertFile))
+		}
+
+		tlsConfig, err := tlsconfig.Build(identityOpts...).Client(clientOpts...)
+		if err != nil {
+			return endpoint{}, err
+		}
+		opts = append(opts, cfhttp.WithTLSConfig(tlsConfig))
+	}
+
+	return endpoint{
+		url:    cfg.URL,
+		client: cfhttp.NewClient(opts...),
+	}, nil
+}
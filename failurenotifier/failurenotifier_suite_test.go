@@ -0,0 +1,13 @@
+package failurenotifier_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestFailurenotifier(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Failure Notifier Suite")
+}
@@ -0,0 +1,256 @@
+package failurenotifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/auctionrunnerdelegate"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+)
+
+// Notifier POSTs a Summary of every auction with failed work to the
+// configured endpoints, in parallel. AuctionCompleted only queues the summary; delivery
+// happens in Run so that slow endpoints never hold up the auction. When Run is
+// signalled, summaries that are still queued get a single delivery attempt
+// before it exits, for at most the flush timeout.
+type Notifier struct {
+	logger        lager.Logger
+	clock         clock.Clock
+	endpoints     []endpoint
+	maxRetries    int
+	retryInterval time.Duration
+	flushTimeout  time.Duration
+	summaries     chan Summary
+}
+
+func New(logger lager.Logger, clock clock.Clock, cfg Config) (*Notifier, error) {
+	requestTimeout := time.Duration(cfg.RequestTimeout)
+	if requestTimeout == 0 {
+		requestTimeout = DefaultRequestTimeout
+	}
+
+	retryInterval := time.Duration(cfg.RetryInterval)
+	if retryInterval == 0 {
+		retryInterval = DefaultRetryInterval
+	}
+
+	maxRetries := DefaultMaxRetries
+	if cfg.MaxRetries != nil {
+		maxRetries = *cfg.MaxRetries
+	}
+
+	flushTimeout := time.Duration(cfg.FlushTimeout)
+	if flushTimeout == 0 {
+		flushTimeout = DefaultFlushTimeout
+	}
+
+	queueSize := cfg.QueueSize
+	if queueSize == 0 {
+		queueSize = DefaultQueueSize
+	}
+
+	endpoints := make([]endpoint, 0, len(cfg.Endpoints))
+	for _, endpointConfig := range cfg.Endpoints {
+		e, err := newEndpoint(endpointConfig, requestTimeout)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, e)
+	}
+
+	return &Notifier{
+		logger:        logger.Session("failure-notifier"),
+		clock:         clock,
+		endpoints:     endpoints,
+		maxRetries:    maxRetries,
+		retryInterval: retryInterval,
+		flushTimeout:  flushTimeout,
+		summaries:     make(chan Summary, queueSize),
+	}, nil
+}
+
+func (n *Notifier) AuctionCompleted(auction auctionrunnerdelegate.CompletedAuction) {
+	summary, ok := NewSummary(auction)
+	if !ok {
+		return
+	}
+
+	select {
+	case n.summaries <- summary:
+	default:
+		n.logger.Error("dropped-summary", fmt.Errorf("queue is full"), lager.Data{
+			"failed-lrps":  len(summary.FailedLRPs),
+			"failed-tasks": len(summary.FailedTasks),
+		})
+	}
+}
+
+func (n *Notifier) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := n.logger
+	logger.Info("started")
+	defer logger.Info("finished")
+
+	close(ready)
+
+	for {
+		select {
+		case summary := <-n.summaries:
+			payload, err := json.Marshal(summary)
+			if err != nil {
+				logger.Error("failed-to-marshal-summary", err)
+				continue
+			}
+
+			if !n.deliver(logger, payload, signals) {
+				n.flush(logger)
+				return nil
+			}
+
+		case <-signals:
+			n.flush(logger)
+			return nil
+		}
+	}
+}
+
+// deliver POSTs the payload to every endpoint in parallel, so that a slow or
+// failing endpoint does not delay delivery to the others. It returns false if
+// it was interrupted by a signal, in which case the requests in flight are
+// cancelled.
+func (n *Notifier) deliver(logger lager.Logger, payload []byte, signals <-chan os.Signal) bool {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		n.forEachEndpoint(func(e endpoint) {
+			n.deliverTo(ctx, logger, e, payload)
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-signals:
+		cancel()
+		<-done
+		return false
+	}
+}
+
+// deliverTo POSTs the payload to the endpoint, retrying a failed attempt up to
+// maxRetries times. It gives up early once the context is cancelled.
+func (n *Notifier) deliverTo(ctx context.Context, logger lager.Logger, e endpoint, payload []byte) {
+	logger = logger.Session("deliver", lager.Data{"url": e.url})
+
+	for attempt := 0; ; attempt++ {
+		err := post(ctx, e, payload)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+
+		if attempt >= n.maxRetries {
+			logger.Error("failed-to-deliver-summary", err, lager.Data{"attempts": attempt + 1})
+			return
+		}
+
+		logger.Info("retrying", lager.Data{"attempt": attempt + 1, "error": err.Error()})
+
+		timer := n.clock.NewTimer(n.retryInterval)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// flush makes one delivery attempt, without retries, for every summary still
+// in the queue. Requests still in flight when the flush timeout expires are
+// cancelled and the remaining summaries are dropped.
+func (n *Notifier) flush(logger lager.Logger) {
+	logger = logger.Session("flush")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	timer := n.clock.NewTimer(n.flushTimeout)
+	defer timer.Stop()
+	go func() {
+		select {
+		case <-timer.C():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		if ctx.Err() != nil {
+			logger.Info("timed-out", lager.Data{"dropped-summaries": len(n.summaries)})
+			return
+		}
+
+		select {
+		case summary := <-n.summaries:
+			payload, err := json.Marshal(summary)
+			if err != nil {
+				logger.Error("failed-to-marshal-summary", err)
+				continue
+			}
+
+			n.forEachEndpoint(func(e endpoint) {
+				err := post(ctx, e, payload)
+				if err != nil {
+					logger.Error("failed-to-deliver-summary", err, lager.Data{"url": e.url})
+				}
+			})
+
+		default:
+			return
+		}
+	}
+}
+
+// forEachEndpoint calls f for every endpoint concurrently and waits for all
+// the calls to return.
+func (n *Notifier) forEachEndpoint(f func(e endpoint)) {
+	wg := sync.WaitGroup{}
+	for _, e := range n.endpoints {
+		wg.Add(1)
+		go func(e endpoint) {
+			defer wg.Done()
+			f(e)
+		}(e)
+	}
+	wg.Wait()
+}
+
+func post(ctx context.Context, e endpoint, payload []byte) error {
+	req, err := http.NewRequest("POST", e.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("http error: status code %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	return nil
+}
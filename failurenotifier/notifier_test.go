@@ -0,0 +1,314 @@
+package failurenotifier_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auctioneer/auctionrunnerdelegate"
+	"code.cloudfoundry.org/auctioneer/failurenotifier"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/durationjson"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Notifier", func() {
+	var (
+		logger    *lagertest.TestLogger
+		fakeClock *fakeclock.FakeClock
+		server    *httptest.Server
+		cfg       failurenotifier.Config
+
+		lock        sync.Mutex
+		statusCodes []int
+		received    []failurenotifier.Summary
+		hang        chan struct{}
+
+		notifier *failurenotifier.Notifier
+		process  ifrit.Process
+		auction  auctionrunnerdelegate.CompletedAuction
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		statusCodes = nil
+		received = nil
+		hang = nil
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.Method).To(Equal("POST"))
+			Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+
+			body, err := ioutil.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+			summary := failurenotifier.Summary{}
+			Expect(json.Unmarshal(body, &summary)).To(Succeed())
+
+			lock.Lock()
+			received = append(received, summary)
+			statusCode := http.StatusOK
+			if len(statusCodes) > 0 {
+				statusCode, statusCodes = statusCodes[0], statusCodes[1:]
+			}
+			hanging := hang
+			lock.Unlock()
+
+			if hanging != nil {
+				select {
+				case <-hanging:
+				case <-r.Context().Done():
+				}
+			}
+			w.WriteHeader(statusCode)
+		}))
+
+		maxRetries := 2
+		cfg = failurenotifier.Config{
+			Endpoints:     []failurenotifier.EndpointConfig{{URL: server.URL}},
+			MaxRetries:    &maxRetries,
+			RetryInterval: durationjson.Duration(5 * time.Second),
+		}
+
+		resource := rep.NewResource(128, 256, 10)
+		pc := rep.NewPlacementConstraint("linux", []string{}, []string{})
+		auction = auctionrunnerdelegate.CompletedAuction{
+			Results: auctiontypes.AuctionResults{
+				SuccessfulTasks: []auctiontypes.TaskAuction{
+					{Task: rep.NewTask("placed-task", "domain", resource, pc)},
+				},
+				FailedLRPs: []auctiontypes.LRPAuction{{
+					LRP:           rep.NewLRP("", models.NewActualLRPKey("process-guid", 2, "lrp-domain"), resource, pc),
+					AuctionRecord: auctiontypes.AuctionRecord{PlacementError: "insufficient resources: memory"},
+				}},
+				FailedTasks: []auctiontypes.TaskAuction{{
+					Task:          rep.NewTask("failed-task", "task-domain", resource, pc),
+					AuctionRecord: auctiontypes.AuctionRecord{PlacementError: auctiontypes.ErrorCellMismatch.Error()},
+				}},
+			},
+			CompletedAt: time.Unix(1000, 0).UTC(),
+		}
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		notifier, err = failurenotifier.New(logger, fakeClock, cfg)
+		Expect(err).NotTo(HaveOccurred())
+		process = ginkgomon.Invoke(notifier)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+		lock.Lock()
+		if hang != nil {
+			close(hang)
+		}
+		lock.Unlock()
+		server.Close()
+	})
+
+	receivedSummaries := func() []failurenotifier.Summary {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]failurenotifier.Summary{}, received...)
+	}
+
+	It("posts a summary of the failed work, requiring resources only for the work that did not fit", func() {
+		notifier.AuctionCompleted(auction)
+
+		Eventually(receivedSummaries).Should(HaveLen(1))
+		Expect(receivedSummaries()[0]).To(Equal(failurenotifier.Summary{
+			Timestamp: time.Unix(1000, 0).UTC(),
+			FailedLRPs: []failurenotifier.LRP{
+				{ProcessGuid: "process-guid", Index: 2, Domain: "lrp-domain", PlacementError: "insufficient resources: memory"},
+			},
+			FailedTasks: []failurenotifier.Task{
+				{TaskGuid: "failed-task", Domain: "task-domain", PlacementError: auctiontypes.ErrorCellMismatch.Error()},
+			},
+			RequiredResources: failurenotifier.Resources{MemoryMB: 128, DiskMB: 256, Containers: 1},
+		}))
+	})
+
+	Context("when nothing failed", func() {
+		It("does not post anything", func() {
+			auction.Results.FailedLRPs = nil
+			auction.Results.FailedTasks = nil
+			notifier.AuctionCompleted(auction)
+
+			Consistently(receivedSummaries).Should(BeEmpty())
+		})
+	})
+
+	Context("when the endpoint fails", func() {
+		BeforeEach(func() {
+			statusCodes = []int{http.StatusServiceUnavailable, http.StatusInternalServerError}
+		})
+
+		It("retries after the retry interval", func() {
+			notifier.AuctionCompleted(auction)
+
+			Eventually(receivedSummaries).Should(HaveLen(1))
+			fakeClock.WaitForWatcherAndIncrement(5 * time.Second)
+			Eventually(receivedSummaries).Should(HaveLen(2))
+			fakeClock.WaitForWatcherAndIncrement(5 * time.Second)
+			Eventually(receivedSummaries).Should(HaveLen(3))
+		})
+
+		Context("and keeps failing", func() {
+			BeforeEach(func() {
+				statusCodes = []int{500, 500, 500, 500}
+			})
+
+			It("gives up after the maximum number of retries", func() {
+				notifier.AuctionCompleted(auction)
+
+				Eventually(receivedSummaries).Should(HaveLen(1))
+				fakeClock.WaitForWatcherAndIncrement(5 * time.Second)
+				Eventually(receivedSummaries).Should(HaveLen(2))
+				fakeClock.WaitForWatcherAndIncrement(5 * time.Second)
+				Eventually(receivedSummaries).Should(HaveLen(3))
+
+				Eventually(logger).Should(gbytes.Say("failed-to-deliver-summary"))
+				Consistently(receivedSummaries).Should(HaveLen(3))
+			})
+		})
+	})
+
+	Context("when one of the endpoints is slow", func() {
+		var (
+			slowServer *httptest.Server
+			release    chan struct{}
+		)
+
+		BeforeEach(func() {
+			release = make(chan struct{})
+			slowServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-release:
+				case <-r.Context().Done():
+				}
+			}))
+
+			cfg.Endpoints = append([]failurenotifier.EndpointConfig{{
+				URL:            slowServer.URL,
+				RequestTimeout: durationjson.Duration(50 * time.Millisecond),
+			}}, cfg.Endpoints...)
+		})
+
+		AfterEach(func() {
+			close(release)
+			slowServer.Close()
+		})
+
+		It("delivers to the other endpoints without waiting for it", func() {
+			notifier.AuctionCompleted(auction)
+			Eventually(receivedSummaries).Should(HaveLen(1))
+		})
+
+		It("retries once its request timeout expires", func() {
+			notifier.AuctionCompleted(auction)
+			Eventually(logger).Should(gbytes.Say("test.failure-notifier.deliver.retrying"))
+		})
+	})
+
+	Context("when retries are disabled", func() {
+		BeforeEach(func() {
+			noRetries := 0
+			cfg.MaxRetries = &noRetries
+			statusCodes = []int{500}
+		})
+
+		It("makes a single delivery attempt", func() {
+			notifier.AuctionCompleted(auction)
+
+			Eventually(logger).Should(gbytes.Say("failed-to-deliver-summary"))
+			Consistently(receivedSummaries).Should(HaveLen(1))
+			Expect(fakeClock.WatcherCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the queue is full", func() {
+		BeforeEach(func() {
+			cfg.QueueSize = 1
+			statusCodes = []int{500, 500, 500}
+		})
+
+		It("drops the summary and logs", func() {
+			notifier.AuctionCompleted(auction)
+			Eventually(receivedSummaries).Should(HaveLen(1))
+
+			notifier.AuctionCompleted(auction)
+			notifier.AuctionCompleted(auction)
+
+			Eventually(logger).Should(gbytes.Say("test.failure-notifier.dropped-summary"))
+		})
+	})
+
+	Context("when it is signalled with summaries still queued", func() {
+		BeforeEach(func() {
+			statusCodes = []int{500}
+		})
+
+		It("attempts to deliver each of them once before exiting", func() {
+			notifier.AuctionCompleted(auction)
+			Eventually(receivedSummaries).Should(HaveLen(1))
+			Eventually(fakeClock.WatcherCount).Should(Equal(1))
+
+			notifier.AuctionCompleted(auction)
+			notifier.AuctionCompleted(auction)
+			process.Signal(os.Interrupt)
+
+			Eventually(receivedSummaries).Should(HaveLen(3))
+			Consistently(receivedSummaries).Should(HaveLen(3))
+		})
+
+		Context("and the endpoint does not respond within the flush timeout", func() {
+			BeforeEach(func() {
+				cfg.FlushTimeout = durationjson.Duration(2 * time.Second)
+			})
+
+			It("gives up on the remaining summaries and exits", func() {
+				notifier.AuctionCompleted(auction)
+				Eventually(receivedSummaries).Should(HaveLen(1))
+				Eventually(fakeClock.WatcherCount).Should(Equal(1))
+
+				lock.Lock()
+				hang = make(chan struct{})
+				lock.Unlock()
+
+				notifier.AuctionCompleted(auction)
+				notifier.AuctionCompleted(auction)
+				process.Signal(os.Interrupt)
+
+				Eventually(receivedSummaries).Should(HaveLen(2))
+				fakeClock.WaitForWatcherAndIncrement(2 * time.Second)
+
+				Eventually(process.Wait()).Should(Receive())
+				Expect(logger).To(gbytes.Say("test.failure-notifier.flush.timed-out"))
+				Expect(receivedSummaries()).To(HaveLen(2))
+			})
+		})
+	})
+
+	Context("when an endpoint has invalid TLS configuration", func() {
+		It("fails to construct", func() {
+			cfg.Endpoints = []failurenotifier.EndpointConfig{{URL: server.URL, CACertFile: "/does/not/exist"}}
+			_, err := failurenotifier.New(logger, fakeClock, cfg)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
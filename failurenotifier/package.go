@@ -0,0 +1 @@
+package failurenotifier // import "code.cloudfoundry.org/auctioneer/failurenotifier"
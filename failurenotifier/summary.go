@@ -0,0 +1,93 @@
+package failurenotifier
+
+import (
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/auctionrunnerdelegate"
+	"code.cloudfoundry.org/rep"
+)
+
+// Summary is the payload POSTed to every configured endpoint when an auction
+// fails to place some of its work.
+type Summary struct {
+	Timestamp         time.Time `json:"timestamp"`
+	FailedLRPs        []LRP     `json:"failed_lrps"`
+	FailedTasks       []Task    `json:"failed_tasks"`
+	RequiredResources Resources `json:"required_resources"`
+}
+
+type LRP struct {
+	ProcessGuid    string `json:"process_guid"`
+	Index          int32  `json:"index"`
+	Domain         string `json:"domain"`
+	PlacementError string `json:"placement_error"`
+}
+
+type Task struct {
+	TaskGuid       string `json:"task_guid"`
+	Domain         string `json:"domain"`
+	PlacementError string `json:"placement_error"`
+}
+
+// insufficientResources prefixes every placement error of work that failed
+// because no cell had enough free resources, whichever resources were short.
+const insufficientResources = "insufficient resources"
+
+// Resources is the total capacity the work that failed for lack of resources
+// would have needed. Work that failed for any other reason, such as a
+// placement tag or rootfs mismatch, would not fit with more capacity and is
+// not counted.
+type Resources struct {
+	MemoryMB   int64 `json:"memory_mb"`
+	DiskMB     int64 `json:"disk_mb"`
+	Containers int   `json:"containers"`
+}
+
+// NewSummary returns the summary of the failed work of an auction and whether
+// there was any.
+func NewSummary(auction auctionrunnerdelegate.CompletedAuction) (Summary, bool) {
+	results := auction.Results
+	if len(results.FailedLRPs) == 0 && len(results.FailedTasks) == 0 {
+		return Summary{}, false
+	}
+
+	summary := Summary{
+		Timestamp:   auction.CompletedAt,
+		FailedLRPs:  make([]LRP, 0, len(results.FailedLRPs)),
+		FailedTasks: make([]Task, 0, len(results.FailedTasks)),
+	}
+
+	for i := range results.FailedLRPs {
+		lrp := &results.FailedLRPs[i]
+		summary.FailedLRPs = append(summary.FailedLRPs, LRP{
+			ProcessGuid:    lrp.ProcessGuid,
+			Index:          lrp.Index,
+			Domain:         lrp.Domain,
+			PlacementError: lrp.PlacementError,
+		})
+		summary.RequiredResources.add(lrp.PlacementError, lrp.Resource)
+	}
+
+	for i := range results.FailedTasks {
+		task := &results.FailedTasks[i]
+		summary.FailedTasks = append(summary.FailedTasks, Task{
+			TaskGuid:       task.TaskGuid,
+			Domain:         task.Domain,
+			PlacementError: task.PlacementError,
+		})
+		summary.RequiredResources.add(task.PlacementError, task.Resource)
+	}
+
+	return summary, true
+}
+
+func (r *Resources) add(placementError string, resource rep.Resource) {
+	if !strings.HasPrefix(placementError, insufficientResources) {
+		return
+	}
+
+	r.MemoryMB += int64(resource.MemoryMB)
+	r.DiskMB += int64(resource.DiskMB)
+	r.Containers++
+}
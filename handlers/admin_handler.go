@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+
+	"code.cloudfoundry.org/auctioneer/quota"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// PendingWork reports how many submitted work items are waiting for an
+// auction result.
+type PendingWork interface {
+	Pending() int
+}
+
+// CellStateProvider reports the states cells returned in the most recent
+// auction.
+type CellStateProvider interface {
+	CellStates() map[string]rep.CellState
+}
+
+type Cordons interface {
+	Cordon(cellID string) bool
+	Uncordon(cellID string) bool
+	List() []string
+}
+
+// QuotaProvider reports the quotas in effect and the usage counted against
+// them.
+type QuotaProvider interface {
+	Config() quota.Config
+	Usage() quota.Usage
+}
+
+type Pauser interface {
+	Pause() bool
+	Resume() bool
+	Paused() bool
+}
+
+type ConfigReloader interface {
+	Reload() error
+}
+
+// AdminStatus is the response to the status, pause and resume routes.
+type AdminStatus struct {
+	Version       string   `json:"version"`
+	StartedAt     int64    `json:"started_at"`
+	Draining      bool     `json:"draining"`
+	Paused        bool     `json:"paused"`
+	PendingWork   int      `json:"pending_work"`
+	CordonedCells []string `json:"cordoned_cells"`
+}
+
+// AdminQuotas is the response to the quotas route.
+type AdminQuotas struct {
+	Quotas quota.Config `json:"quotas"`
+	Usage  quota.Usage  `json:"usage"`
+}
+
+// AdminCell describes a cell known to the auctioneer, either because it took
+// part in the most recent auction or because it is cordoned.
+type AdminCell struct {
+	CellID   string         `json:"cell_id"`
+	Cordoned bool           `json:"cordoned"`
+	State    *rep.CellState `json:"state,omitempty"`
+}
+
+type AdminHandler struct {
+	version    string
+	startedAt  int64
+	drain      DrainState
+	pending    PendingWork
+	cellStates CellStateProvider
+	cordons    Cordons
+	quotas     QuotaProvider
+	pauser     Pauser
+	reloader   ConfigReloader
+}
+
+func NewAdminHandler(
+	version string,
+	startedAt int64,
+	drain DrainState,
+	pending PendingWork,
+	cellStates CellStateProvider,
+	cordons Cordons,
+	quotas QuotaProvider,
+	pauser Pauser,
+	reloader ConfigReloader,
+) *AdminHandler {
+	return &AdminHandler{
+		version:    version,
+		startedAt:  startedAt,
+		drain:      drain,
+		pending:    pending,
+		cellStates: cellStates,
+		cordons:    cordons,
+		quotas:     quotas,
+		pauser:     pauser,
+		reloader:   reloader,
+	}
+}
+
+func (*AdminHandler) logSession(logger lager.Logger) lager.Logger {
+	return logger.Session("admin-handler")
+}
+
+func (h *AdminHandler) Status(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	writeJSONResponse(w, http.StatusOK, h.status())
+}
+
+func (h *AdminHandler) Cells(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	cells := map[string]*AdminCell{}
+	for cellID, state := range h.cellStates.CellStates() {
+		state := state
+		cells[cellID] = &AdminCell{CellID: cellID, State: &state}
+	}
+	for _, cellID := range h.cordons.List() {
+		if cells[cellID] == nil {
+			cells[cellID] = &AdminCell{CellID: cellID}
+		}
+		cells[cellID].Cordoned = true
+	}
+
+	response := make([]AdminCell, 0, len(cells))
+	for _, cell := range cells {
+		response = append(response, *cell)
+	}
+	sort.Slice(response, func(i, j int) bool { return response[i].CellID < response[j].CellID })
+
+	writeJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *AdminHandler) Cordons(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	writeJSONResponse(w, http.StatusOK, h.cordons.List())
+}
+
+func (h *AdminHandler) Cordon(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = h.logSession(logger).Session("cordon")
+
+	cellID := r.FormValue(":cell_id")
+	changed := h.cordons.Cordon(cellID)
+
+	logger.Info("cordoned", lager.Data{"cell-id": cellID, "changed": changed})
+	writeJSONResponse(w, http.StatusOK, h.cordons.List())
+}
+
+func (h *AdminHandler) Uncordon(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = h.logSession(logger).Session("uncordon")
+
+	cellID := r.FormValue(":cell_id")
+	changed := h.cordons.Uncordon(cellID)
+
+	logger.Info("uncordoned", lager.Data{"cell-id": cellID, "changed": changed})
+	writeJSONResponse(w, http.StatusOK, h.cordons.List())
+}
+
+func (h *AdminHandler) Quotas(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	writeJSONResponse(w, http.StatusOK, AdminQuotas{
+		Quotas: h.quotas.Config(),
+		Usage:  h.quotas.Usage(),
+	})
+}
+
+func (h *AdminHandler) Pause(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = h.logSession(logger).Session("pause")
+
+	changed := h.pauser.Pause()
+
+	logger.Info("paused", lager.Data{"changed": changed})
+	writeJSONResponse(w, http.StatusOK, h.status())
+}
+
+func (h *AdminHandler) Resume(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = h.logSession(logger).Session("resume")
+
+	changed := h.pauser.Resume()
+
+	logger.Info("resumed", lager.Data{"changed": changed})
+	writeJSONResponse(w, http.StatusOK, h.status())
+}
+
+func (h *AdminHandler) ReloadConfig(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = h.logSession(logger).Session("reload-config")
+
+	err := h.reloader.Reload()
+	if err != nil {
+		logger.Error("failed-to-reload", err)
+		writeJSONResponse(w, http.StatusUnprocessableEntity, HandlerError{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, h.status())
+}
+
+func (h *AdminHandler) status() AdminStatus {
+	return AdminStatus{
+		Version:       h.version,
+		StartedAt:     h.startedAt,
+		Draining:      h.drain.Draining(),
+		Paused:        h.pauser.Paused(),
+		PendingWork:   h.pending.Pending(),
+		CordonedCells: h.cordons.List(),
+	}
+}
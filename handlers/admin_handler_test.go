@@ -0,0 +1,294 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/auctioneer/cordon"
+	"code.cloudfoundry.org/auctioneer/handlers"
+	"code.cloudfoundry.org/auctioneer/placementexplainer"
+	"code.cloudfoundry.org/auctioneer/quota"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"github.com/tedsuo/rata"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakePendingWork struct {
+	pending int
+}
+
+func (f *fakePendingWork) Pending() int {
+	return f.pending
+}
+
+type fakeCellStateProvider struct {
+	states map[string]rep.CellState
+}
+
+func (f *fakeCellStateProvider) CellStates() map[string]rep.CellState {
+	return f.states
+}
+
+type fakeQuotaProvider struct {
+	config quota.Config
+	usage  quota.Usage
+}
+
+func (f *fakeQuotaProvider) Config() quota.Config {
+	return f.config
+}
+
+func (f *fakeQuotaProvider) Usage() quota.Usage {
+	return f.usage
+}
+
+type fakePauser struct {
+	paused bool
+}
+
+func (f *fakePauser) Pause() bool {
+	changed := !f.paused
+	f.paused = true
+	return changed
+}
+
+func (f *fakePauser) Resume() bool {
+	changed := f.paused
+	f.paused = false
+	return changed
+}
+
+func (f *fakePauser) Paused() bool {
+	return f.paused
+}
+
+type fakeReloader struct {
+	reloads int
+	err     error
+}
+
+func (f *fakeReloader) Reload() error {
+	f.reloads++
+	return f.err
+}
+
+var _ = Describe("Admin Handlers", func() {
+	var (
+		logger           *lagertest.TestLogger
+		drainState       *fakeDrainState
+		pending          *fakePendingWork
+		cellStates       *fakeCellStateProvider
+		cordons          *cordon.Cordons
+		quotas           *fakeQuotaProvider
+		pauser           *fakePauser
+		reloader         *fakeReloader
+		explanations     *fakeExplanationProvider
+		handler          http.Handler
+		responseRecorder *httptest.ResponseRecorder
+		requestGenerator *rata.RequestGenerator
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		logger.RegisterSink(lager.NewWriterSink(GinkgoWriter, lager.DEBUG))
+
+		drainState = &fakeDrainState{}
+		pending = &fakePendingWork{pending: 3}
+		cellStates = &fakeCellStateProvider{states: map[string]rep.CellState{
+			"cell-b": {Zone: "z2"},
+			"cell-a": {Zone: "z1"},
+		}}
+		cordons = cordon.New(logger)
+		quotas = &fakeQuotaProvider{
+			config: quota.Config{Domains: map[string]quota.Quota{"ci": {MemoryMB: 1024, Containers: 10}}},
+			usage: quota.Usage{
+				Domains:       map[string]quota.Resources{"ci": {MemoryMB: 512, DiskMB: 1024, Containers: 4}},
+				PlacementTags: map[string]quota.Resources{},
+			},
+		}
+		pauser = &fakePauser{}
+		reloader = &fakeReloader{}
+		explanations = &fakeExplanationProvider{
+			explanations: []placementexplainer.Explanation{{Type: placementexplainer.TaskWorkType, TaskGuid: "task-guid"}},
+		}
+
+		adminHandler := handlers.NewAdminHandler("1.2.3", 1000, drainState, pending, cellStates, cordons, quotas, pauser, reloader)
+		handler = handlers.NewAdmin(logger, adminHandler, explanations)
+		responseRecorder = httptest.NewRecorder()
+		requestGenerator = rata.NewRequestGenerator("http://localhost", auctioneer.AdminRoutes)
+	})
+
+	serve := func(route string, params rata.Params) {
+		request, err := requestGenerator.CreateRequest(route, params, nil)
+		Expect(err).NotTo(HaveOccurred())
+		handler.ServeHTTP(responseRecorder, request)
+	}
+
+	decodeStatus := func() handlers.AdminStatus {
+		status := handlers.AdminStatus{}
+		Expect(json.NewDecoder(responseRecorder.Body).Decode(&status)).To(Succeed())
+		return status
+	}
+
+	Describe("Status", func() {
+		BeforeEach(func() {
+			drainState.draining = true
+			cordons.Cordon("cell-a")
+		})
+
+		It("reports the state of the auctioneer", func() {
+			serve(auctioneer.StatusRoute, nil)
+
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(decodeStatus()).To(Equal(handlers.AdminStatus{
+				Version:       "1.2.3",
+				StartedAt:     1000,
+				Draining:      true,
+				Paused:        false,
+				PendingWork:   3,
+				CordonedCells: []string{"cell-a"},
+			}))
+		})
+	})
+
+	Describe("Cells", func() {
+		BeforeEach(func() {
+			cordons.Cordon("cell-b")
+			cordons.Cordon("cell-c")
+		})
+
+		It("lists the cells from the last auction and the cordoned cells", func() {
+			serve(auctioneer.CellsRoute, nil)
+
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			cells := []handlers.AdminCell{}
+			Expect(json.NewDecoder(responseRecorder.Body).Decode(&cells)).To(Succeed())
+
+			Expect(cells).To(HaveLen(3))
+			Expect(cells[0].CellID).To(Equal("cell-a"))
+			Expect(cells[0].Cordoned).To(BeFalse())
+			Expect(cells[0].State.Zone).To(Equal("z1"))
+			Expect(cells[1].CellID).To(Equal("cell-b"))
+			Expect(cells[1].Cordoned).To(BeTrue())
+			Expect(cells[1].State.Zone).To(Equal("z2"))
+			Expect(cells[2]).To(Equal(handlers.AdminCell{CellID: "cell-c", Cordoned: true}))
+		})
+	})
+
+	Describe("Cordons", func() {
+		It("cordons a cell", func() {
+			serve(auctioneer.CordonCellRoute, rata.Params{"cell_id": "cell-a"})
+
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(cordons.IsCordoned("cell-a")).To(BeTrue())
+
+			cordoned := []string{}
+			Expect(json.NewDecoder(responseRecorder.Body).Decode(&cordoned)).To(Succeed())
+			Expect(cordoned).To(Equal([]string{"cell-a"}))
+		})
+
+		It("uncordons a cell", func() {
+			cordons.Cordon("cell-a")
+			serve(auctioneer.UncordonCellRoute, rata.Params{"cell_id": "cell-a"})
+
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(cordons.IsCordoned("cell-a")).To(BeFalse())
+		})
+
+		It("lists the cordoned cells", func() {
+			cordons.Cordon("cell-b")
+			serve(auctioneer.CordonsRoute, nil)
+
+			cordoned := []string{}
+			Expect(json.NewDecoder(responseRecorder.Body).Decode(&cordoned)).To(Succeed())
+			Expect(cordoned).To(Equal([]string{"cell-b"}))
+		})
+	})
+
+	Describe("Quotas", func() {
+		It("reports the quotas and the usage counted against them", func() {
+			serve(auctioneer.QuotasRoute, nil)
+
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			quotas := handlers.AdminQuotas{}
+			Expect(json.NewDecoder(responseRecorder.Body).Decode(&quotas)).To(Succeed())
+			Expect(quotas).To(Equal(handlers.AdminQuotas{
+				Quotas: quota.Config{Domains: map[string]quota.Quota{"ci": {MemoryMB: 1024, Containers: 10}}},
+				Usage: quota.Usage{
+					Domains:       map[string]quota.Resources{"ci": {MemoryMB: 512, DiskMB: 1024, Containers: 4}},
+					PlacementTags: map[string]quota.Resources{},
+				},
+			}))
+		})
+	})
+
+	Describe("Pause and Resume", func() {
+		It("pauses auctions", func() {
+			serve(auctioneer.PauseRoute, nil)
+
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(pauser.paused).To(BeTrue())
+			Expect(decodeStatus().Paused).To(BeTrue())
+		})
+
+		It("resumes auctions", func() {
+			pauser.paused = true
+			serve(auctioneer.ResumeRoute, nil)
+
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(pauser.paused).To(BeFalse())
+			Expect(decodeStatus().Paused).To(BeFalse())
+		})
+	})
+
+	Describe("ReloadConfig", func() {
+		It("reloads the configuration", func() {
+			serve(auctioneer.ReloadConfigRoute, nil)
+
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(reloader.reloads).To(Equal(1))
+		})
+
+		Context("when the configuration is invalid", func() {
+			BeforeEach(func() {
+				reloader.err = errors.New("invalid configuration: bbs_address: is required")
+			})
+
+			It("responds with the error", func() {
+				serve(auctioneer.ReloadConfigRoute, nil)
+
+				Expect(responseRecorder.Code).To(Equal(http.StatusUnprocessableEntity))
+				handlerError := handlers.HandlerError{}
+				Expect(json.NewDecoder(responseRecorder.Body).Decode(&handlerError)).To(Succeed())
+				Expect(handlerError.Error).To(ContainSubstring("bbs_address"))
+			})
+		})
+	})
+
+	Describe("PlacementExplanations", func() {
+		It("serves the placement explanations", func() {
+			serve(auctioneer.PlacementExplanationsRoute, nil)
+
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			served := []placementexplainer.Explanation{}
+			Expect(json.NewDecoder(responseRecorder.Body).Decode(&served)).To(Succeed())
+			Expect(served).To(HaveLen(1))
+			Expect(served[0].TaskGuid).To(Equal("task-guid"))
+		})
+	})
+
+	It("does not serve the auction routes", func() {
+		request, err := http.NewRequest("POST", "http://localhost/v1/tasks", nil)
+		Expect(err).NotTo(HaveOccurred())
+		handler.ServeHTTP(responseRecorder, request)
+
+		Expect(responseRecorder.Code).To(Equal(http.StatusNotFound))
+	})
+})
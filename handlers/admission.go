@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/metrics"
+	"code.cloudfoundry.org/lager"
+)
+
+const (
+	ThrottledRequestCount = "ThrottledRequestCount"
+
+	DefaultRetryAfter = 5 * time.Second
+)
+
+var (
+	errBodyTooLarge       = errors.New("request body too large")
+	errTooManyItems       = errors.New("too many items in request")
+	errTooMuchPendingWork = errors.New("too much pending work")
+)
+
+// AdmissionLimits bounds the work the auction routes accept. A zero limit
+// means no limit. Requests over a limit are answered with 429 and a
+// Retry-After header, so that clients back off instead of growing the queue
+// until the auctioneer runs out of memory.
+type AdmissionLimits struct {
+	MaxRequestBodyBytes int64
+	MaxItemsPerRequest  int
+	MaxPendingWork      int
+	RetryAfter          time.Duration
+}
+
+// countItems returns the number of work items in an auction request body.
+type countItems func(payload []byte) (int, error)
+
+func countTasks(payload []byte) (int, error) {
+	tasks := []json.RawMessage{}
+	err := json.Unmarshal(payload, &tasks)
+	return len(tasks), err
+}
+
+// countLRPInstances counts every requested instance, since each index is
+// auctioned and tracked separately.
+func countLRPInstances(payload []byte) (int, error) {
+	starts := []struct {
+		Indices []int `json:"indices"`
+	}{}
+	err := json.Unmarshal(payload, &starts)
+
+	count := 0
+	for _, start := range starts {
+		count += len(start.Indices)
+	}
+	return count, err
+}
+
+// admit enforces the limits on requests for route before passing them on.
+// Bodies that cannot be counted are passed on for the handler to reject.
+func admit(logger lager.Logger, sink metrics.Sink, route string, limits AdmissionLimits, pending PendingWork, count countItems, handler http.Handler) http.Handler {
+	if limits.MaxRequestBodyBytes <= 0 && limits.MaxItemsPerRequest <= 0 && limits.MaxPendingWork <= 0 {
+		return handler
+	}
+
+	retryAfter := limits.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = DefaultRetryAfter
+	}
+
+	logger = logger.Session("admit", lager.Data{"route": route})
+
+	throttle := func(w http.ResponseWriter, err error, data lager.Data) {
+		logger.Info("throttled", lager.Data{"reason": err.Error(), "details": data})
+		increment(logger, sink, ThrottledRequestCount)
+
+		writeTooManyRequestsResponse(w, retryAfter, err)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, err := readLimited(r.Body, limits.MaxRequestBodyBytes)
+		if err == errBodyTooLarge {
+			throttle(w, errBodyTooLarge, lager.Data{"max-request-body-bytes": limits.MaxRequestBodyBytes})
+			return
+		}
+		if err != nil {
+			logger.Error("failed-to-read-request-body", err)
+			writeInternalErrorJSONResponse(w, err)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(payload))
+
+		items, err := count(payload)
+		if err != nil {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		if limits.MaxItemsPerRequest > 0 && items > limits.MaxItemsPerRequest {
+			throttle(w, errTooManyItems, lager.Data{"items": items, "max-items-per-request": limits.MaxItemsPerRequest})
+			return
+		}
+
+		// Concurrent requests are checked against the same pending count, so
+		// the limit can be overshot by the requests in flight.
+		if limits.MaxPendingWork > 0 && pending != nil {
+			if queued := pending.Pending(); queued+items > limits.MaxPendingWork {
+				throttle(w, errTooMuchPendingWork, lager.Data{"items": items, "pending": queued, "max-pending-work": limits.MaxPendingWork})
+				return
+			}
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// readLimited reads the body, failing with errBodyTooLarge once it exceeds
+// max bytes. A max of zero reads the whole body.
+func readLimited(body io.Reader, max int64) ([]byte, error) {
+	if max <= 0 {
+		return ioutil.ReadAll(body)
+	}
+
+	payload, err := ioutil.ReadAll(io.LimitReader(body, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(payload)) > max {
+		return nil, errBodyTooLarge
+	}
+	return payload, nil
+}
@@ -0,0 +1,173 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	fake_auction_runner "code.cloudfoundry.org/auction/auctiontypes/fakes"
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/auctioneer/handlers"
+	"code.cloudfoundry.org/auctioneer/metrics/metricsfakes"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"github.com/tedsuo/rata"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Admission control", func() {
+	var (
+		logger           *lagertest.TestLogger
+		runner           *fake_auction_runner.FakeAuctionRunner
+		fakeSink         *metricsfakes.FakeSink
+		pending          *fakePendingWork
+		limits           handlers.AdmissionLimits
+		responseRecorder *httptest.ResponseRecorder
+		route            string
+		payload          []byte
+	)
+
+	tasks := func(n int) []byte {
+		resource := rep.NewResource(1, 2, 3)
+		pc := rep.NewPlacementConstraint("rootfs", []string{}, []string{})
+
+		requests := []auctioneer.TaskStartRequest{}
+		for i := 0; i < n; i++ {
+			requests = append(requests, auctioneer.TaskStartRequest{rep.NewTask("task-guid", "test", resource, pc)})
+		}
+
+		payload, err := json.Marshal(requests)
+		Expect(err).NotTo(HaveOccurred())
+		return payload
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		runner = new(fake_auction_runner.FakeAuctionRunner)
+		fakeSink = &metricsfakes.FakeSink{}
+		pending = &fakePendingWork{}
+		limits = handlers.AdmissionLimits{
+			MaxRequestBodyBytes: 4096,
+			MaxItemsPerRequest:  3,
+			MaxPendingWork:      10,
+			RetryAfter:          1500 * time.Millisecond,
+		}
+		responseRecorder = httptest.NewRecorder()
+		route = auctioneer.CreateTaskAuctionsRoute
+		payload = tasks(2)
+	})
+
+	JustBeforeEach(func() {
+		handler := handlers.New(logger, runner, &fakeSubmissionTracker{}, fakeSink, nil, limits, pending, nil)
+
+		reqGen := rata.NewRequestGenerator("http://localhost", auctioneer.Routes)
+		req, err := reqGen.CreateRequest(route, rata.Params{}, bytes.NewBuffer(payload))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(responseRecorder, req)
+	})
+
+	itThrottles := func(reason string) {
+		It("responds 429 with Retry-After", func() {
+			Expect(responseRecorder.Code).To(Equal(http.StatusTooManyRequests))
+			Expect(responseRecorder.Header().Get("Retry-After")).To(Equal("2"))
+
+			var handlerError handlers.HandlerError
+			Expect(json.Unmarshal(responseRecorder.Body.Bytes(), &handlerError)).To(Succeed())
+			Expect(handlerError.Error).To(Equal(reason))
+		})
+
+		It("does not schedule the work", func() {
+			Expect(runner.ScheduleTasksForAuctionsCallCount()).To(Equal(0))
+			Expect(runner.ScheduleLRPsForAuctionsCallCount()).To(Equal(0))
+		})
+
+		It("logs and counts the throttled request", func() {
+			Expect(logger).To(gbytes.Say("test.admit.throttled"))
+
+			names := []string{}
+			for i := 0; i < fakeSink.IncrementCounterCallCount(); i++ {
+				name, _, _ := fakeSink.IncrementCounterArgsForCall(i)
+				names = append(names, name)
+			}
+			Expect(names).To(ContainElement(handlers.ThrottledRequestCount))
+		})
+	}
+
+	Context("when the request is within the limits", func() {
+		It("schedules the work", func() {
+			Expect(responseRecorder.Code).To(Equal(http.StatusAccepted))
+			Expect(runner.ScheduleTasksForAuctionsCallCount()).To(Equal(1))
+			Expect(runner.ScheduleTasksForAuctionsArgsForCall(0)).To(HaveLen(2))
+		})
+	})
+
+	Context("when the request body is too large", func() {
+		BeforeEach(func() {
+			limits.MaxRequestBodyBytes = int64(len(payload) - 1)
+		})
+
+		itThrottles("request body too large")
+	})
+
+	Context("when the request has too many items", func() {
+		BeforeEach(func() {
+			payload = tasks(4)
+		})
+
+		itThrottles("too many items in request")
+	})
+
+	Context("when LRP instances exceed the items per request", func() {
+		BeforeEach(func() {
+			route = auctioneer.CreateLRPAuctionsRoute
+			starts := []auctioneer.LRPStartRequest{{
+				ProcessGuid: "process-guid",
+				Domain:      "domain",
+				Indices:     []int{0, 1, 2, 3},
+			}}
+
+			var err error
+			payload, err = json.Marshal(starts)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		itThrottles("too many items in request")
+	})
+
+	Context("when the request would exceed the pending work limit", func() {
+		BeforeEach(func() {
+			pending.pending = 9
+		})
+
+		itThrottles("too much pending work")
+	})
+
+	Context("when the request body is not valid JSON", func() {
+		BeforeEach(func() {
+			payload = []byte("{{")
+		})
+
+		It("leaves it to the handler to reject", func() {
+			Expect(responseRecorder.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("when no limits are set", func() {
+		BeforeEach(func() {
+			limits = handlers.AdmissionLimits{}
+			payload = tasks(20)
+			pending.pending = 1000
+		})
+
+		It("schedules the work", func() {
+			Expect(responseRecorder.Code).To(Equal(http.StatusAccepted))
+			Expect(runner.ScheduleTasksForAuctionsCallCount()).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.cloudfoundry.org/auctioneer/metrics"
+	"code.cloudfoundry.org/lager"
+)
+
+const (
+	AuthorizedRequestCount = "AuthorizedRequestCount"
+	ForbiddenRequestCount  = "ForbiddenRequestCount"
+)
+
+// AuthorizedClients maps names of routes in auctioneer.Routes to the client
+// identities allowed to call them. An identity matches a client certificate's
+// subject, its common name, or any of its DNS, URI, email or IP SANs. Routes
+// without an entry can be called by any client the server accepts.
+type AuthorizedClients map[string][]string
+
+// authorize responds 403 to requests for route unless the client presented
+// a verified certificate matching one of the identities.
+func authorize(logger lager.Logger, sink metrics.Sink, route string, identities []string, handler http.Handler) http.Handler {
+	if len(identities) == 0 {
+		return handler
+	}
+
+	allowed := map[string]bool{}
+	for _, identity := range identities {
+		allowed[identity] = true
+	}
+
+	logger = logger.Session("authorize", lager.Data{"route": route})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		presented := clientIdentities(r)
+		for _, identity := range presented {
+			if allowed[identity] {
+				logger.Info("authorized", lager.Data{"identity": identity})
+				increment(logger, sink, AuthorizedRequestCount)
+				handler.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		logger.Info("forbidden", lager.Data{"identities": presented})
+		increment(logger, sink, ForbiddenRequestCount)
+		writeJSONResponse(w, http.StatusForbidden, HandlerError{
+			Error: fmt.Sprintf("client is not authorized for %s", route),
+		})
+	})
+}
+
+// clientIdentities returns the identities of the verified client certificate
+// the request was made with, or none if the client presented no certificate.
+func clientIdentities(r *http.Request) []string {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return []string{}
+	}
+	certificate := r.TLS.VerifiedChains[0][0]
+
+	identities := []string{certificate.Subject.String()}
+	if certificate.Subject.CommonName != "" {
+		identities = append(identities, certificate.Subject.CommonName)
+	}
+	identities = append(identities, certificate.DNSNames...)
+	for _, uri := range certificate.URIs {
+		identities = append(identities, uri.String())
+	}
+	identities = append(identities, certificate.EmailAddresses...)
+	for _, ip := range certificate.IPAddresses {
+		identities = append(identities, ip.String())
+	}
+	return identities
+}
+
+func increment(logger lager.Logger, sink metrics.Sink, name string) {
+	err := sink.IncrementCounter(name, 1, nil)
+	if err != nil {
+		logger.Error("failed-to-increment-counter", err, lager.Data{"counter": name})
+	}
+}
@@ -0,0 +1,145 @@
+package handlers_test
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	fake_auction_runner "code.cloudfoundry.org/auction/auctiontypes/fakes"
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/auctioneer/handlers"
+	"code.cloudfoundry.org/auctioneer/metrics/metricsfakes"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/tedsuo/rata"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Client authorization", func() {
+	var (
+		logger           *lagertest.TestLogger
+		runner           *fake_auction_runner.FakeAuctionRunner
+		fakeSink         *metricsfakes.FakeSink
+		handler          http.Handler
+		responseRecorder *httptest.ResponseRecorder
+		request          *http.Request
+	)
+
+	counters := func() []string {
+		names := []string{}
+		for i := 0; i < fakeSink.IncrementCounterCallCount(); i++ {
+			name, _, _ := fakeSink.IncrementCounterArgsForCall(i)
+			names = append(names, name)
+		}
+		return names
+	}
+
+	withCertificate := func(request *http.Request, certificate *x509.Certificate) {
+		request.TLS = &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{certificate},
+			VerifiedChains:   [][]*x509.Certificate{{certificate}},
+		}
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		runner = new(fake_auction_runner.FakeAuctionRunner)
+		fakeSink = &metricsfakes.FakeSink{}
+		responseRecorder = httptest.NewRecorder()
+
+		handler = handlers.New(logger, runner, &fakeSubmissionTracker{}, fakeSink, handlers.AuthorizedClients{
+			auctioneer.CreateTaskAuctionsRoute: {"bbs.service.cf.internal", "spiffe://cf/admin-tooling"},
+		}, handlers.AdmissionLimits{}, nil, nil)
+
+		reqGen := rata.NewRequestGenerator("http://localhost", auctioneer.Routes)
+		payload, err := json.Marshal([]auctioneer.TaskStartRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		request, err = reqGen.CreateRequest(auctioneer.CreateTaskAuctionsRoute, rata.Params{}, bytes.NewBuffer(payload))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		handler.ServeHTTP(responseRecorder, request)
+	})
+
+	Context("when the client certificate has an allowed DNS SAN", func() {
+		BeforeEach(func() {
+			withCertificate(request, &x509.Certificate{
+				Subject:  pkix.Name{CommonName: "bbs"},
+				DNSNames: []string{"bbs.service.cf.internal"},
+			})
+		})
+
+		It("serves the request", func() {
+			Expect(responseRecorder.Code).To(Equal(http.StatusAccepted))
+			Expect(runner.ScheduleTasksForAuctionsCallCount()).To(Equal(1))
+		})
+
+		It("logs and counts the authorized identity", func() {
+			Expect(logger).To(gbytes.Say(`test.authorize.authorized.*"identity":"bbs.service.cf.internal".*"route":"CreateTaskAuctions"`))
+			Expect(counters()).To(ConsistOf(handlers.RequestCount, handlers.AuthorizedRequestCount))
+		})
+	})
+
+	Context("when the client certificate has an allowed URI SAN", func() {
+		BeforeEach(func() {
+			uri, err := url.Parse("spiffe://cf/admin-tooling")
+			Expect(err).NotTo(HaveOccurred())
+			withCertificate(request, &x509.Certificate{URIs: []*url.URL{uri}})
+		})
+
+		It("serves the request", func() {
+			Expect(responseRecorder.Code).To(Equal(http.StatusAccepted))
+		})
+	})
+
+	Context("when the client certificate does not match", func() {
+		BeforeEach(func() {
+			withCertificate(request, &x509.Certificate{
+				Subject:  pkix.Name{CommonName: "cell", Organization: []string{"Cloud Foundry"}},
+				DNSNames: []string{"cell.service.cf.internal"},
+			})
+		})
+
+		It("responds 403 without serving the request", func() {
+			Expect(responseRecorder.Code).To(Equal(http.StatusForbidden))
+			Expect(runner.ScheduleTasksForAuctionsCallCount()).To(Equal(0))
+
+			var handlerError handlers.HandlerError
+			Expect(json.Unmarshal(responseRecorder.Body.Bytes(), &handlerError)).To(Succeed())
+			Expect(handlerError.Error).To(Equal("client is not authorized for CreateTaskAuctions"))
+		})
+
+		It("logs and counts the rejected identities", func() {
+			Expect(logger).To(gbytes.Say(`test.authorize.forbidden.*"identities":\["CN=cell,O=Cloud Foundry","cell","cell.service.cf.internal"\]`))
+			Expect(counters()).To(ConsistOf(handlers.RequestCount, handlers.ForbiddenRequestCount))
+		})
+	})
+
+	Context("when the client presents no certificate", func() {
+		It("responds 403", func() {
+			Expect(responseRecorder.Code).To(Equal(http.StatusForbidden))
+			Expect(runner.ScheduleTasksForAuctionsCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the route has no authorized clients", func() {
+		BeforeEach(func() {
+			reqGen := rata.NewRequestGenerator("http://localhost", auctioneer.Routes)
+			var err error
+			request, err = reqGen.CreateRequest(auctioneer.CreateLRPAuctionsRoute, rata.Params{}, bytes.NewBufferString("[]"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("serves any client", func() {
+			Expect(responseRecorder.Code).To(Equal(http.StatusAccepted))
+		})
+	})
+})
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer"
+)
+
+// DomainLimiter rate limits the work submitted for each domain and orders
+// work fairly across domains. domainlimit.Limiter implements it.
+type DomainLimiter interface {
+	Take(domain string, n int) (bool, time.Duration)
+	Interleave(domains []string) []int
+}
+
+var errDomainRateLimited = errors.New("domain rate limit exceeded")
+
+// limitTasks returns the tasks whose domains are within their rate limits,
+// interleaved across domains, the guids of the throttled tasks, and the
+// shortest wait before a throttled domain can submit again. The tasks of a
+// domain are counted against its limit together, so a domain over its limit
+// only holds back its own tasks.
+func limitTasks(limiter DomainLimiter, tasks []auctioneer.TaskStartRequest) ([]auctioneer.TaskStartRequest, []string, time.Duration) {
+	if limiter == nil {
+		return tasks, nil, 0
+	}
+
+	items := map[string]int{}
+	for i := range tasks {
+		items[tasks[i].Domain]++
+	}
+	allowed, retryAfter := takeDomains(limiter, items)
+
+	admitted := make([]auctioneer.TaskStartRequest, 0, len(tasks))
+	domains := make([]string, 0, len(tasks))
+	throttled := []string{}
+	for i := range tasks {
+		if !allowed[tasks[i].Domain] {
+			throttled = append(throttled, tasks[i].TaskGuid)
+			continue
+		}
+		admitted = append(admitted, tasks[i])
+		domains = append(domains, tasks[i].Domain)
+	}
+
+	ordered := make([]auctioneer.TaskStartRequest, 0, len(admitted))
+	for _, position := range limiter.Interleave(domains) {
+		ordered = append(ordered, admitted[position])
+	}
+	return ordered, throttled, retryAfter
+}
+
+// limitLRPs is limitTasks for LRP starts. Every instance of a start counts
+// towards its domain's limit.
+func limitLRPs(limiter DomainLimiter, starts []auctioneer.LRPStartRequest) ([]auctioneer.LRPStartRequest, []string, time.Duration) {
+	if limiter == nil {
+		return starts, nil, 0
+	}
+
+	items := map[string]int{}
+	for i := range starts {
+		items[starts[i].Domain] += len(starts[i].Indices)
+	}
+	allowed, retryAfter := takeDomains(limiter, items)
+
+	admitted := make([]auctioneer.LRPStartRequest, 0, len(starts))
+	domains := make([]string, 0, len(starts))
+	throttled := []string{}
+	for i := range starts {
+		if !allowed[starts[i].Domain] {
+			throttled = append(throttled, starts[i].ProcessGuid)
+			continue
+		}
+		admitted = append(admitted, starts[i])
+		domains = append(domains, starts[i].Domain)
+	}
+
+	ordered := make([]auctioneer.LRPStartRequest, 0, len(admitted))
+	for _, position := range limiter.Interleave(domains) {
+		ordered = append(ordered, admitted[position])
+	}
+	return ordered, throttled, retryAfter
+}
+
+// takeDomains takes the items of each domain from the limiter and returns the
+// domains that were allowed, along with the shortest wait of those that were
+// not.
+func takeDomains(limiter DomainLimiter, items map[string]int) (map[string]bool, time.Duration) {
+	allowed := make(map[string]bool, len(items))
+	var retryAfter time.Duration
+	for domain, n := range items {
+		ok, wait := limiter.Take(domain, n)
+		if ok {
+			allowed[domain] = true
+			continue
+		}
+		if retryAfter == 0 || wait < retryAfter {
+			retryAfter = wait
+		}
+	}
+	return allowed, retryAfter
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+)
+
+// DrainState reports whether the auctioneer is shutting down.
+type DrainState interface {
+	Draining() bool
+}
+
+var errDraining = errors.New("auctioneer is draining")
+
+// RejectWhileDraining responds 503 to auction submissions once the
+// auctioneer has started draining, so that clients can retry against the
+// instance that takes over the lock. Other requests are still served.
+func RejectWhileDraining(handler http.Handler, state DrainState) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && state.Draining() {
+			writeJSONResponse(w, http.StatusServiceUnavailable, HandlerError{
+				Error: errDraining.Error(),
+			})
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,73 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/auctioneer/handlers"
+	"github.com/tedsuo/rata"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeDrainState struct {
+	draining bool
+}
+
+func (f *fakeDrainState) Draining() bool {
+	return f.draining
+}
+
+var _ = Describe("RejectWhileDraining", func() {
+	var (
+		state            *fakeDrainState
+		served           bool
+		handler          http.Handler
+		responseRecorder *httptest.ResponseRecorder
+		reqGen           *rata.RequestGenerator
+	)
+
+	BeforeEach(func() {
+		state = &fakeDrainState{}
+		served = false
+		handler = handlers.RejectWhileDraining(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			served = true
+			w.WriteHeader(http.StatusAccepted)
+		}), state)
+		responseRecorder = httptest.NewRecorder()
+		reqGen = rata.NewRequestGenerator("http://localhost", auctioneer.Routes)
+	})
+
+	serve := func(route string) {
+		req, err := reqGen.CreateRequest(route, rata.Params{}, bytes.NewBufferString("[]"))
+		Expect(err).NotTo(HaveOccurred())
+		handler.ServeHTTP(responseRecorder, req)
+	}
+
+	It("serves submissions when not draining", func() {
+		serve(auctioneer.CreateTaskAuctionsRoute)
+		Expect(served).To(BeTrue())
+		Expect(responseRecorder.Code).To(Equal(http.StatusAccepted))
+	})
+
+	Context("when draining", func() {
+		BeforeEach(func() {
+			state.draining = true
+		})
+
+		It("rejects task submissions with 503", func() {
+			serve(auctioneer.CreateTaskAuctionsRoute)
+			Expect(served).To(BeFalse())
+			Expect(responseRecorder.Code).To(Equal(http.StatusServiceUnavailable))
+		})
+
+		It("rejects LRP submissions with 503", func() {
+			serve(auctioneer.CreateLRPAuctionsRoute)
+			Expect(served).To(BeFalse())
+			Expect(responseRecorder.Code).To(Equal(http.StatusServiceUnavailable))
+		})
+	})
+})
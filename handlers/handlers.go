@@ -6,10 +6,12 @@ import (
 
 	"code.cloudfoundry.org/auction/auctiontypes"
 	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/auctioneer/metrics"
+	"code.cloudfoundry.org/auctioneer/tracing"
 	"code.cloudfoundry.org/bbs/handlers/middleware"
-	loggingclient "code.cloudfoundry.org/diego-logging-client"
 	"code.cloudfoundry.org/lager"
 	"github.com/tedsuo/rata"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -17,18 +19,31 @@ const (
 	RequestCount           = "RequestCount"
 )
 
-func New(logger lager.Logger, runner auctiontypes.AuctionRunner, metronClient loggingclient.IngressClient) http.Handler {
-	taskAuctionHandler := logWrap(NewTaskAuctionHandler(runner).Create, logger)
-	lrpAuctionHandler := logWrap(NewLRPAuctionHandler(runner).Create, logger)
+func New(
+	logger lager.Logger,
+	runner auctiontypes.AuctionRunner,
+	submissions SubmissionTracker,
+	sink metrics.Sink,
+	authorizedClients AuthorizedClients,
+	limits AdmissionLimits,
+	pending PendingWork,
+	limiter DomainLimiter,
+) http.Handler {
+	taskAuctionHandler := logWrap(NewTaskAuctionHandler(runner, submissions, limiter).Create, logger)
+	lrpAuctionHandler := logWrap(NewLRPAuctionHandler(runner, submissions, limiter).Create, logger)
 
 	emitter := &auctioneerEmitter{
-		logger:       logger,
-		metronClient: metronClient,
+		logger: logger,
+		sink:   sink,
 	}
 
 	actions := rata.Handlers{
-		auctioneer.CreateTaskAuctionsRoute: middleware.RecordLatency(taskAuctionHandler, emitter),
-		auctioneer.CreateLRPAuctionsRoute:  middleware.RecordLatency(lrpAuctionHandler, emitter),
+		auctioneer.CreateTaskAuctionsRoute: admit(logger, sink, auctioneer.CreateTaskAuctionsRoute, limits, pending, countTasks, middleware.RecordLatency(taskAuctionHandler, emitter)),
+		auctioneer.CreateLRPAuctionsRoute:  admit(logger, sink, auctioneer.CreateLRPAuctionsRoute, limits, pending, countLRPInstances, middleware.RecordLatency(lrpAuctionHandler, emitter)),
+	}
+
+	for route, action := range actions {
+		actions[route] = authorize(logger, sink, route, authorizedClients[route], action)
 	}
 
 	handler, err := rata.NewRouter(auctioneer.Routes, actions)
@@ -39,12 +54,46 @@ func New(logger lager.Logger, runner auctiontypes.AuctionRunner, metronClient lo
 	return middleware.RecordRequestCount(handler, emitter)
 }
 
+// NewAdmin returns the handler for AdminRoutes, which is served on its own
+// listener so that clients of the auction routes cannot use it.
+func NewAdmin(logger lager.Logger, adminHandler *AdminHandler, explanations ExplanationProvider) http.Handler {
+	actions := rata.Handlers{
+		auctioneer.StatusRoute: logWrap(adminHandler.Status, logger),
+
+		auctioneer.CellsRoute:        logWrap(adminHandler.Cells, logger),
+		auctioneer.CordonsRoute:      logWrap(adminHandler.Cordons, logger),
+		auctioneer.CordonCellRoute:   logWrap(adminHandler.Cordon, logger),
+		auctioneer.UncordonCellRoute: logWrap(adminHandler.Uncordon, logger),
+
+		auctioneer.QuotasRoute: logWrap(adminHandler.Quotas, logger),
+
+		auctioneer.PauseRoute:  logWrap(adminHandler.Pause, logger),
+		auctioneer.ResumeRoute: logWrap(adminHandler.Resume, logger),
+
+		auctioneer.ReloadConfigRoute: logWrap(adminHandler.ReloadConfig, logger),
+
+		auctioneer.PlacementExplanationsRoute: logWrap(NewPlacementExplanationsHandler(explanations).List, logger),
+	}
+
+	handler, err := rata.NewRouter(auctioneer.AdminRoutes, actions)
+	if err != nil {
+		panic("unable to create admin router: " + err.Error())
+	}
+
+	return handler
+}
+
 func logWrap(loggable func(http.ResponseWriter, *http.Request, lager.Logger), logger lager.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		requestLog := logger.Session("request", lager.Data{
-			"method":  r.Method,
-			"request": r.URL.String(),
-		})
+		ctx := tracing.Extract(r.Context(), r.Header)
+		ctx, span := tracing.Tracer().Start(ctx, r.Method+" "+r.URL.Path, trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+		r = r.WithContext(ctx)
+
+		data := tracing.LoggerData(ctx)
+		data["method"] = r.Method
+		data["request"] = r.URL.String()
+		requestLog := logger.Session("request", data)
 
 		requestLog.Info("serving")
 		loggable(w, r, requestLog)
@@ -53,16 +102,16 @@ func logWrap(loggable func(http.ResponseWriter, *http.Request, lager.Logger), lo
 }
 
 type auctioneerEmitter struct {
-	logger       lager.Logger
-	metronClient loggingclient.IngressClient
+	logger lager.Logger
+	sink   metrics.Sink
 }
 
 func (e *auctioneerEmitter) IncrementRequestCounter(delta int) {
-	e.metronClient.IncrementCounter(RequestCount)
+	e.sink.IncrementCounter(RequestCount, uint64(delta), nil)
 }
 
 func (e *auctioneerEmitter) UpdateLatency(latency time.Duration) {
-	err := e.metronClient.SendDuration(RequestLatencyDuration, latency)
+	err := e.sink.SendDuration(RequestLatencyDuration, latency, nil)
 	if err != nil {
 		e.logger.Error("failed-to-send-latency", err)
 	}
@@ -9,7 +9,7 @@ import (
 	fake_auction_runner "code.cloudfoundry.org/auction/auctiontypes/fakes"
 	"code.cloudfoundry.org/auctioneer"
 	"code.cloudfoundry.org/auctioneer/handlers"
-	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
+	"code.cloudfoundry.org/auctioneer/metrics/metricsfakes"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagertest"
 	"code.cloudfoundry.org/rep"
@@ -17,6 +17,7 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 )
 
 var _ = Describe("Auction Handlers", func() {
@@ -25,7 +26,7 @@ var _ = Describe("Auction Handlers", func() {
 		runner           *fake_auction_runner.FakeAuctionRunner
 		responseRecorder *httptest.ResponseRecorder
 		handler          http.Handler
-		fakeMetronClient *mfakes.FakeIngressClient
+		fakeSink         *metricsfakes.FakeSink
 	)
 
 	BeforeEach(func() {
@@ -34,9 +35,9 @@ var _ = Describe("Auction Handlers", func() {
 		runner = new(fake_auction_runner.FakeAuctionRunner)
 		responseRecorder = httptest.NewRecorder()
 
-		fakeMetronClient = &mfakes.FakeIngressClient{}
+		fakeSink = &metricsfakes.FakeSink{}
 
-		handler = handlers.New(logger, runner, fakeMetronClient)
+		handler = handlers.New(logger, runner, &fakeSubmissionTracker{}, fakeSink, nil, handlers.AdmissionLimits{}, nil, nil)
 	})
 
 	Describe("Task Handler", func() {
@@ -71,18 +72,34 @@ var _ = Describe("Auction Handlers", func() {
 			})
 
 			It("sends the correct metrics", func() {
-				Expect(fakeMetronClient.SendDurationCallCount()).To(Equal(1))
-				name, value, _ := fakeMetronClient.SendDurationArgsForCall(0)
+				Expect(fakeSink.SendDurationCallCount()).To(Equal(1))
+				name, value, _ := fakeSink.SendDurationArgsForCall(0)
 				Expect(name).To(Equal("RequestLatency"))
 				Expect(value).To(BeNumerically(">", 0))
 
-				Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
-				name = fakeMetronClient.IncrementCounterArgsForCall(0)
+				Expect(fakeSink.IncrementCounterCallCount()).To(Equal(1))
+				name, delta, _ := fakeSink.IncrementCounterArgsForCall(0)
 				Expect(name).To(Equal("RequestCount"))
+				Expect(delta).To(BeEquivalentTo(1))
 			})
 		})
 	})
 
+	Describe("trace context", func() {
+		BeforeEach(func() {
+			reqGen := rata.NewRequestGenerator("http://localhost", auctioneer.Routes)
+			req, err := reqGen.CreateRequest(auctioneer.CreateTaskAuctionsRoute, rata.Params{}, bytes.NewBufferString("[]"))
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+			handler.ServeHTTP(responseRecorder, req)
+		})
+
+		It("logs the trace id of the request", func() {
+			Expect(logger).To(gbytes.Say(`"trace-id":"4bf92f3577b34da6a3ce929d0e0e4736"`))
+		})
+	})
+
 	Describe("LRP Handler", func() {
 		Context("with a valid LRPStart", func() {
 			BeforeEach(func() {
@@ -123,14 +140,15 @@ var _ = Describe("Auction Handlers", func() {
 			})
 
 			It("sends the correct metrics", func() {
-				Expect(fakeMetronClient.SendDurationCallCount()).To(Equal(1))
-				name, value, _ := fakeMetronClient.SendDurationArgsForCall(0)
+				Expect(fakeSink.SendDurationCallCount()).To(Equal(1))
+				name, value, _ := fakeSink.SendDurationArgsForCall(0)
 				Expect(name).To(Equal("RequestLatency"))
 				Expect(value).To(BeNumerically(">", 0))
 
-				Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
-				name = fakeMetronClient.IncrementCounterArgsForCall(0)
+				Expect(fakeSink.IncrementCounterCallCount()).To(Equal(1))
+				name, delta, _ := fakeSink.IncrementCounterArgsForCall(0)
 				Expect(name).To(Equal("RequestCount"))
+				Expect(delta).To(BeEquivalentTo(1))
 			})
 		})
 	})
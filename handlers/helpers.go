@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
+	"math"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 func writeInvalidJSONResponse(w http.ResponseWriter, err error) {
@@ -18,6 +20,15 @@ func writeInternalErrorJSONResponse(w http.ResponseWriter, err error) {
 	})
 }
 
+// writeTooManyRequestsResponse responds 429, telling the client to wait
+// retryAfter, rounded up to whole seconds, before trying again.
+func writeTooManyRequestsResponse(w http.ResponseWriter, retryAfter time.Duration, err error) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	writeJSONResponse(w, http.StatusTooManyRequests, HandlerError{
+		Error: err.Error(),
+	})
+}
+
 func writeStatusAcceptedResponse(w http.ResponseWriter) {
 	writeJSONResponse(w, http.StatusAccepted, struct{}{})
 }
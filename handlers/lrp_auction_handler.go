@@ -11,12 +11,21 @@ import (
 )
 
 type LRPAuctionHandler struct {
-	runner auctiontypes.AuctionRunner
+	runner      auctiontypes.AuctionRunner
+	submissions SubmissionTracker
+	limiter     DomainLimiter
 }
 
-func NewLRPAuctionHandler(runner auctiontypes.AuctionRunner) *LRPAuctionHandler {
+// NewLRPAuctionHandler returns a handler that schedules the submitted LRP
+// starts. When limiter is not nil, starts from domains over their rate limit
+// are dropped, to be submitted again by the BBS when it converges, and the
+// rest are interleaved across domains. A request whose starts are all
+// throttled is refused with 429 Too Many Requests.
+func NewLRPAuctionHandler(runner auctiontypes.AuctionRunner, submissions SubmissionTracker, limiter DomainLimiter) *LRPAuctionHandler {
 	return &LRPAuctionHandler{
-		runner: runner,
+		runner:      runner,
+		submissions: submissions,
+		limiter:     limiter,
 	}
 }
 
@@ -43,19 +52,32 @@ func (h *LRPAuctionHandler) Create(w http.ResponseWriter, r *http.Request, logge
 	}
 
 	validStarts := make([]auctioneer.LRPStartRequest, 0, len(starts))
-	lrpGuids := make(map[string][]int)
 	for i := range starts {
 		start := &starts[i]
 		if err := start.Validate(); err == nil {
 			validStarts = append(validStarts, *start)
-			indices := lrpGuids[start.ProcessGuid]
-			indices = append(indices, start.Indices...)
-			lrpGuids[start.ProcessGuid] = indices
 		} else {
 			logger.Error("start-validate-failed", err, lager.Data{"lrp-start": start})
 		}
 	}
 
+	admittedStarts, throttled, retryAfter := limitLRPs(h.limiter, validStarts)
+	if len(throttled) > 0 {
+		logger.Info("throttled", lager.Data{"process-guids": throttled})
+	}
+	if len(validStarts) > 0 && len(admittedStarts) == 0 {
+		writeTooManyRequestsResponse(w, retryAfter, errDomainRateLimited)
+		return
+	}
+	validStarts = admittedStarts
+
+	lrpGuids := make(map[string][]int)
+	for i := range validStarts {
+		start := &validStarts[i]
+		lrpGuids[start.ProcessGuid] = append(lrpGuids[start.ProcessGuid], start.Indices...)
+	}
+
+	h.submissions.LRPsSubmitted(r.Context(), validStarts)
 	h.runner.ScheduleLRPsForAuctions(validStarts)
 
 	logLRPGuids(lrpGuids, logger)
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"time"
 
 	fake_auction_runner "code.cloudfoundry.org/auction/auctiontypes/fakes"
 	"code.cloudfoundry.org/auctioneer"
@@ -23,6 +24,7 @@ var _ = Describe("LRPAuctionHandler", func() {
 		runner           *fake_auction_runner.FakeAuctionRunner
 		responseRecorder *httptest.ResponseRecorder
 		handler          *handlers.LRPAuctionHandler
+		submissions      *fakeSubmissionTracker
 	)
 
 	BeforeEach(func() {
@@ -30,7 +32,8 @@ var _ = Describe("LRPAuctionHandler", func() {
 		logger.RegisterSink(lager.NewWriterSink(GinkgoWriter, lager.DEBUG))
 		runner = new(fake_auction_runner.FakeAuctionRunner)
 		responseRecorder = httptest.NewRecorder()
-		handler = handlers.NewLRPAuctionHandler(runner)
+		submissions = &fakeSubmissionTracker{}
+		handler = handlers.NewLRPAuctionHandler(runner, submissions, nil)
 	})
 
 	Describe("Create", func() {
@@ -69,6 +72,10 @@ var _ = Describe("LRPAuctionHandler", func() {
 				Expect(submittedStart).To(Equal(starts))
 			})
 
+			It("records the submission of the start auction", func() {
+				Expect(submissions.lrps).To(Equal(starts))
+			})
+
 			It("should log the list of lrps as a json object with guid and indices keys", func() {
 				Expect(logger.Buffer()).To(gbytes.Say(`"guid":"some-guid","indices":\[2,3\]`))
 			})
@@ -99,6 +106,68 @@ var _ = Describe("LRPAuctionHandler", func() {
 			})
 		})
 
+		Context("when a domain limiter is given", func() {
+			var (
+				limiter  *fakeDomainLimiter
+				starts   []auctioneer.LRPStartRequest
+				web, api auctioneer.LRPStartRequest
+			)
+
+			start := func(processGuid, domain string, indices ...int) auctioneer.LRPStartRequest {
+				return auctioneer.LRPStartRequest{
+					Indices:     indices,
+					Domain:      domain,
+					ProcessGuid: processGuid,
+					Resource: rep.Resource{
+						MemoryMB: 1024,
+						DiskMB:   512,
+					},
+					PlacementConstraint: rep.PlacementConstraint{
+						RootFs: "docker:///docker.com/docker",
+					},
+				}
+			}
+
+			BeforeEach(func() {
+				limiter = &fakeDomainLimiter{throttled: map[string]time.Duration{"ci": time.Second}}
+				handler = handlers.NewLRPAuctionHandler(runner, submissions, limiter)
+
+				web = start("web-guid", "web", 0, 1, 2)
+				api = start("api-guid", "api", 0)
+				starts = []auctioneer.LRPStartRequest{web, start("ci-guid", "ci", 0), api}
+			})
+
+			JustBeforeEach(func() {
+				handler.Create(responseRecorder, newTestRequest(starts), logger)
+			})
+
+			It("counts every instance towards the domain's limit", func() {
+				Expect(limiter.taken).To(Equal(map[string]int{"web": 3, "api": 1}))
+			})
+
+			It("schedules the starts of domains within their limits in interleaved order", func() {
+				Expect(responseRecorder.Code).To(Equal(http.StatusAccepted))
+				Expect(runner.ScheduleLRPsForAuctionsCallCount()).To(Equal(1))
+				Expect(runner.ScheduleLRPsForAuctionsArgsForCall(0)).To(Equal([]auctioneer.LRPStartRequest{api, web}))
+			})
+
+			It("logs the throttled starts", func() {
+				Expect(logger).To(gbytes.Say(`test.lrp-auction-handler.create.throttled.*"process-guids":\["ci-guid"\]`))
+			})
+
+			Context("when every start is throttled", func() {
+				BeforeEach(func() {
+					starts = starts[1:2]
+				})
+
+				It("responds with 429 and Retry-After", func() {
+					Expect(responseRecorder.Code).To(Equal(http.StatusTooManyRequests))
+					Expect(responseRecorder.Header().Get("Retry-After")).To(Equal("1"))
+					Expect(runner.ScheduleLRPsForAuctionsCallCount()).To(Equal(0))
+				})
+			})
+		})
+
 		Context("when the request body is a not a start auction", func() {
 			BeforeEach(func() {
 				handler.Create(responseRecorder, newTestRequest(`{invalidjson}`), logger)
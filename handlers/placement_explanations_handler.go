@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"code.cloudfoundry.org/auctioneer/placementexplainer"
+	"code.cloudfoundry.org/lager"
+)
+
+type ExplanationProvider interface {
+	Explanations(identifier string) []placementexplainer.Explanation
+}
+
+type PlacementExplanationsHandler struct {
+	provider ExplanationProvider
+}
+
+func NewPlacementExplanationsHandler(provider ExplanationProvider) *PlacementExplanationsHandler {
+	return &PlacementExplanationsHandler{
+		provider: provider,
+	}
+}
+
+func (*PlacementExplanationsHandler) logSession(logger lager.Logger) lager.Logger {
+	return logger.Session("placement-explanations-handler")
+}
+
+func (h *PlacementExplanationsHandler) List(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = h.logSession(logger).Session("list")
+
+	identifier := r.URL.Query().Get("guid")
+	explanations := h.provider.Explanations(identifier)
+
+	logger.Debug("listed", lager.Data{"guid": identifier, "count": len(explanations)})
+	writeJSONResponse(w, http.StatusOK, explanations)
+}
@@ -0,0 +1,74 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"code.cloudfoundry.org/auctioneer/handlers"
+	"code.cloudfoundry.org/auctioneer/placementexplainer"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PlacementExplanationsHandler", func() {
+	var (
+		logger           *lagertest.TestLogger
+		provider         *fakeExplanationProvider
+		responseRecorder *httptest.ResponseRecorder
+		handler          *handlers.PlacementExplanationsHandler
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		logger.RegisterSink(lager.NewWriterSink(GinkgoWriter, lager.DEBUG))
+		provider = &fakeExplanationProvider{
+			explanations: []placementexplainer.Explanation{{
+				Type:           placementexplainer.TaskWorkType,
+				TaskGuid:       "task-guid",
+				PlacementError: "insufficient resources",
+				CellRejections: map[string][]string{"cell-a": {placementexplainer.ReasonInsufficientMemory}},
+				EligibleCells:  []string{},
+			}},
+		}
+		responseRecorder = httptest.NewRecorder()
+		handler = handlers.NewPlacementExplanationsHandler(provider)
+	})
+
+	Describe("List", func() {
+		It("responds with the explanations as JSON", func() {
+			request, err := http.NewRequest("GET", "/v1/placement_explanations", nil)
+			Expect(err).NotTo(HaveOccurred())
+			handler.List(responseRecorder, request, logger)
+
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+
+			explanations := []placementexplainer.Explanation{}
+			Expect(json.NewDecoder(responseRecorder.Body).Decode(&explanations)).To(Succeed())
+			Expect(explanations).To(Equal(provider.explanations))
+			Expect(provider.identifier).To(BeEmpty())
+		})
+
+		It("filters by the guid query parameter", func() {
+			request, err := http.NewRequest("GET", "/v1/placement_explanations?guid=task-guid", nil)
+			Expect(err).NotTo(HaveOccurred())
+			handler.List(responseRecorder, request, logger)
+
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(provider.identifier).To(Equal("task-guid"))
+		})
+	})
+})
+
+type fakeExplanationProvider struct {
+	identifier   string
+	explanations []placementexplainer.Explanation
+}
+
+func (p *fakeExplanationProvider) Explanations(identifier string) []placementexplainer.Explanation {
+	p.identifier = identifier
+	return p.explanations
+}
@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/auctioneer"
+)
+
+// SubmissionTracker is told about every valid work item as it arrives, before
+// it is handed to the auction runner. ctx carries the trace context of the
+// request that submitted the work.
+type SubmissionTracker interface {
+	TasksSubmitted(ctx context.Context, tasks []auctioneer.TaskStartRequest)
+	LRPsSubmitted(ctx context.Context, lrps []auctioneer.LRPStartRequest)
+}
@@ -11,12 +11,21 @@ import (
 )
 
 type TaskAuctionHandler struct {
-	runner auctiontypes.AuctionRunner
+	runner      auctiontypes.AuctionRunner
+	submissions SubmissionTracker
+	limiter     DomainLimiter
 }
 
-func NewTaskAuctionHandler(runner auctiontypes.AuctionRunner) *TaskAuctionHandler {
+// NewTaskAuctionHandler returns a handler that schedules the submitted tasks.
+// When limiter is not nil, tasks from domains over their rate limit are
+// dropped, to be submitted again by the BBS when it converges, and the rest are
+// interleaved across domains. A request whose tasks are all throttled is
+// refused with 429 Too Many Requests.
+func NewTaskAuctionHandler(runner auctiontypes.AuctionRunner, submissions SubmissionTracker, limiter DomainLimiter) *TaskAuctionHandler {
 	return &TaskAuctionHandler{
-		runner: runner,
+		runner:      runner,
+		submissions: submissions,
+		limiter:     limiter,
 	}
 }
 
@@ -43,17 +52,31 @@ func (h *TaskAuctionHandler) Create(w http.ResponseWriter, r *http.Request, logg
 	}
 
 	validTasks := make([]auctioneer.TaskStartRequest, 0, len(tasks))
-	taskGuids := make([]string, 0, len(tasks))
 	for i := range tasks {
 		t := &tasks[i]
 		if err := t.Validate(); err == nil {
 			validTasks = append(validTasks, *t)
-			taskGuids = append(taskGuids, t.TaskGuid)
 		} else {
 			logger.Error("task-validate-failed", err, lager.Data{"task": t})
 		}
 	}
 
+	admittedTasks, throttled, retryAfter := limitTasks(h.limiter, validTasks)
+	if len(throttled) > 0 {
+		logger.Info("throttled", lager.Data{"tasks": throttled})
+	}
+	if len(validTasks) > 0 && len(admittedTasks) == 0 {
+		writeTooManyRequestsResponse(w, retryAfter, errDomainRateLimited)
+		return
+	}
+	validTasks = admittedTasks
+
+	taskGuids := make([]string, 0, len(validTasks))
+	for i := range validTasks {
+		taskGuids = append(taskGuids, validTasks[i].TaskGuid)
+	}
+
+	h.submissions.TasksSubmitted(r.Context(), validTasks)
 	h.runner.ScheduleTasksForAuctions(validTasks)
 
 	logger.Info("submitted", lager.Data{"tasks": taskGuids})
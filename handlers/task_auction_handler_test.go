@@ -1,9 +1,11 @@
 package handlers_test
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"time"
 
 	fake_auction_runner "code.cloudfoundry.org/auction/auctiontypes/fakes"
 	"code.cloudfoundry.org/auctioneer"
@@ -23,6 +25,7 @@ var _ = Describe("TaskAuctionHandler", func() {
 		runner           *fake_auction_runner.FakeAuctionRunner
 		responseRecorder *httptest.ResponseRecorder
 		handler          *handlers.TaskAuctionHandler
+		submissions      *fakeSubmissionTracker
 	)
 
 	BeforeEach(func() {
@@ -30,7 +33,8 @@ var _ = Describe("TaskAuctionHandler", func() {
 		logger.RegisterSink(lager.NewWriterSink(GinkgoWriter, lager.DEBUG))
 		runner = new(fake_auction_runner.FakeAuctionRunner)
 		responseRecorder = httptest.NewRecorder()
-		handler = handlers.NewTaskAuctionHandler(runner)
+		submissions = &fakeSubmissionTracker{}
+		handler = handlers.NewTaskAuctionHandler(runner, submissions, nil)
 	})
 
 	Describe("Create", func() {
@@ -59,6 +63,10 @@ var _ = Describe("TaskAuctionHandler", func() {
 				submittedTasks := runner.ScheduleTasksForAuctionsArgsForCall(0)
 				Expect(submittedTasks).To(Equal(tasks))
 			})
+
+			It("records the submission of the task", func() {
+				Expect(submissions.tasks).To(Equal(tasks))
+			})
 		})
 
 		Context("when the request body is a not a valid task", func() {
@@ -85,6 +93,62 @@ var _ = Describe("TaskAuctionHandler", func() {
 				submittedTasks := runner.ScheduleTasksForAuctionsArgsForCall(0)
 				Expect(submittedTasks).To(BeEmpty())
 			})
+
+			It("does not record the submission of the task", func() {
+				Expect(submissions.tasks).To(BeEmpty())
+			})
+		})
+
+		Context("when a domain limiter is given", func() {
+			var (
+				limiter    *fakeDomainLimiter
+				tasks      []auctioneer.TaskStartRequest
+				web1, web2 auctioneer.TaskStartRequest
+			)
+
+			BeforeEach(func() {
+				limiter = &fakeDomainLimiter{throttled: map[string]time.Duration{"ci": 1500 * time.Millisecond}}
+				handler = handlers.NewTaskAuctionHandler(runner, submissions, limiter)
+
+				resource := rep.NewResource(1, 2, 3)
+				pc := rep.NewPlacementConstraint("rootfs", []string{}, []string{})
+				web1 = auctioneer.NewTaskStartRequest(rep.NewTask("web-1", "web", resource, pc))
+				web2 = auctioneer.NewTaskStartRequest(rep.NewTask("web-2", "web", resource, pc))
+				tasks = []auctioneer.TaskStartRequest{
+					web1,
+					auctioneer.NewTaskStartRequest(rep.NewTask("ci-1", "ci", resource, pc)),
+					web2,
+				}
+			})
+
+			JustBeforeEach(func() {
+				handler.Create(responseRecorder, newTestRequest(tasks), logger)
+			})
+
+			It("schedules the tasks of domains within their limits in interleaved order", func() {
+				Expect(responseRecorder.Code).To(Equal(http.StatusAccepted))
+				Expect(limiter.taken).To(Equal(map[string]int{"web": 2}))
+				Expect(runner.ScheduleTasksForAuctionsCallCount()).To(Equal(1))
+				Expect(runner.ScheduleTasksForAuctionsArgsForCall(0)).To(Equal([]auctioneer.TaskStartRequest{web2, web1}))
+				Expect(submissions.tasks).To(ConsistOf(web1, web2))
+			})
+
+			It("logs the throttled tasks", func() {
+				Expect(logger).To(Say(`test.task-auction-handler.create.throttled.*"tasks":\["ci-1"\]`))
+			})
+
+			Context("when every task is throttled", func() {
+				BeforeEach(func() {
+					tasks = tasks[1:2]
+				})
+
+				It("responds with 429 and Retry-After", func() {
+					Expect(responseRecorder.Code).To(Equal(http.StatusTooManyRequests))
+					Expect(responseRecorder.Header().Get("Retry-After")).To(Equal("2"))
+					Expect(runner.ScheduleTasksForAuctionsCallCount()).To(Equal(0))
+					Expect(submissions.tasks).To(BeEmpty())
+				})
+			})
 		})
 
 		Context("when the request body is a not a task", func() {
@@ -132,3 +196,41 @@ var _ = Describe("TaskAuctionHandler", func() {
 		})
 	})
 })
+
+type fakeSubmissionTracker struct {
+	tasks []auctioneer.TaskStartRequest
+	lrps  []auctioneer.LRPStartRequest
+}
+
+func (f *fakeSubmissionTracker) TasksSubmitted(ctx context.Context, tasks []auctioneer.TaskStartRequest) {
+	f.tasks = append(f.tasks, tasks...)
+}
+
+func (f *fakeSubmissionTracker) LRPsSubmitted(ctx context.Context, lrps []auctioneer.LRPStartRequest) {
+	f.lrps = append(f.lrps, lrps...)
+}
+
+type fakeDomainLimiter struct {
+	throttled map[string]time.Duration
+	taken     map[string]int
+}
+
+func (f *fakeDomainLimiter) Take(domain string, n int) (bool, time.Duration) {
+	if wait, ok := f.throttled[domain]; ok {
+		return false, wait
+	}
+	if f.taken == nil {
+		f.taken = map[string]int{}
+	}
+	f.taken[domain] += n
+	return true, 0
+}
+
+// Interleave reverses the order, so that tests can tell it was applied.
+func (f *fakeDomainLimiter) Interleave(domains []string) []int {
+	positions := make([]int, 0, len(domains))
+	for i := len(domains) - 1; i >= 0; i-- {
+		positions = append(positions, i)
+	}
+	return positions
+}
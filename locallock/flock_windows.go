@@ -0,0 +1,18 @@
+package locallock
+
+import (
+	"errors"
+	"os"
+)
+
+var errLocked = errors.New("lock is held by another process")
+
+var errUnsupported = errors.New("file locks are not supported on windows")
+
+func tryLock(file *os.File) error {
+	return errUnsupported
+}
+
+func unlock(file *os.File) error {
+	return errUnsupported
+}
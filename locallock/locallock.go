@@ -0,0 +1,101 @@
+package locallock
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"github.com/tedsuo/ifrit"
+)
+
+const (
+	FileLockMode         = "file"
+	AlwaysLeaderLockMode = "always_leader"
+
+	DefaultRetryInterval = time.Second
+)
+
+// NewAlwaysLeaderRunner returns a lock runner that becomes ready immediately.
+// It is meant for single-node installs, where there is no other auctioneer to
+// coordinate with.
+func NewAlwaysLeaderRunner(logger lager.Logger) ifrit.Runner {
+	return ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+		logger := logger.Session("always-leader")
+		logger.Info("acquired-lock")
+		close(ready)
+
+		<-signals
+		logger.Info("released-lock")
+		return nil
+	})
+}
+
+// NewFileLockRunner returns a lock runner that holds an exclusive OS lock on
+// the file at path, creating it if necessary. Until the lock is acquired it
+// retries every retryInterval. The lock is released when the runner is
+// signalled or the process exits, so only auctioneers on the same machine can
+// be coordinated this way.
+func NewFileLockRunner(logger lager.Logger, path string, retryInterval time.Duration, clock clock.Clock) ifrit.Runner {
+	if retryInterval <= 0 {
+		retryInterval = DefaultRetryInterval
+	}
+
+	return &fileLockRunner{
+		logger:        logger,
+		path:          path,
+		retryInterval: retryInterval,
+		clock:         clock,
+	}
+}
+
+type fileLockRunner struct {
+	logger        lager.Logger
+	path          string
+	retryInterval time.Duration
+	clock         clock.Clock
+}
+
+func (r *fileLockRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := r.logger.Session("file-lock", lager.Data{"path": r.path})
+
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		logger.Error("failed-to-open-lock-file", err)
+		return err
+	}
+	defer file.Close()
+
+	retry := r.clock.NewTimer(0)
+	defer retry.Stop()
+
+	for {
+		select {
+		case <-signals:
+			return nil
+
+		case <-retry.C():
+			err := tryLock(file)
+			if err == nil {
+				logger.Info("acquired-lock")
+				close(ready)
+
+				<-signals
+				err := unlock(file)
+				if err != nil {
+					logger.Error("failed-to-release-lock", err)
+				}
+				logger.Info("released-lock")
+				return nil
+			}
+
+			if err != errLocked {
+				logger.Error("failed-to-acquire-lock", err)
+				return err
+			}
+
+			logger.Debug("lock-held-elsewhere")
+			retry.Reset(r.retryInterval)
+		}
+	}
+}
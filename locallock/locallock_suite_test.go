@@ -0,0 +1,13 @@
+package locallock_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestLocallock(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Local Lock Suite")
+}
@@ -0,0 +1,85 @@
+package locallock_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/locallock"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Local locks", func() {
+	var logger *lagertest.TestLogger
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+	})
+
+	Describe("AlwaysLeaderRunner", func() {
+		It("becomes ready immediately and exits when signalled", func() {
+			process := ginkgomon.Invoke(locallock.NewAlwaysLeaderRunner(logger))
+			Consistently(process.Wait()).ShouldNot(Receive())
+
+			ginkgomon.Interrupt(process)
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+		})
+	})
+
+	Describe("FileLockRunner", func() {
+		const retryInterval = 5 * time.Second
+
+		var (
+			lockPath  string
+			fakeClock *fakeclock.FakeClock
+			first     ifrit.Process
+		)
+
+		BeforeEach(func() {
+			dir, err := ioutil.TempDir("", "locallock")
+			Expect(err).NotTo(HaveOccurred())
+			lockPath = filepath.Join(dir, "auctioneer.lock")
+			fakeClock = fakeclock.NewFakeClock(time.Now())
+
+			first = ginkgomon.Invoke(locallock.NewFileLockRunner(logger, lockPath, retryInterval, fakeClock))
+		})
+
+		AfterEach(func() {
+			ginkgomon.Interrupt(first)
+			os.RemoveAll(filepath.Dir(lockPath))
+		})
+
+		It("acquires the lock", func() {
+			Expect(logger).To(Say("acquired-lock"))
+			Expect(lockPath).To(BeAnExistingFile())
+		})
+
+		It("waits until the lock is released by its holder", func() {
+			second := ifrit.Background(locallock.NewFileLockRunner(logger, lockPath, retryInterval, fakeClock))
+			defer ginkgomon.Interrupt(second)
+
+			Consistently(second.Ready()).ShouldNot(BeClosed())
+
+			ginkgomon.Interrupt(first)
+			fakeClock.WaitForWatcherAndIncrement(retryInterval)
+
+			Eventually(second.Ready()).Should(BeClosed())
+		})
+
+		Context("when the lock file cannot be opened", func() {
+			It("exits with an error", func() {
+				runner := locallock.NewFileLockRunner(logger, filepath.Join(lockPath, "missing", "auctioneer.lock"), retryInterval, fakeClock)
+				process := ifrit.Background(runner)
+				Eventually(process.Wait()).Should(Receive(HaveOccurred()))
+			})
+		})
+	})
+})
@@ -0,0 +1 @@
+package locallock // import "code.cloudfoundry.org/auctioneer/locallock"
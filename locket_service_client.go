@@ -0,0 +1,97 @@
+package auctioneer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/locket"
+	"code.cloudfoundry.org/locket/lock"
+	locketmodels "code.cloudfoundry.org/locket/models"
+	"github.com/tedsuo/ifrit"
+)
+
+// LocketLockKey is the key of the auctioneer's lock in locket.
+const LocketLockKey = "auctioneer"
+
+var ErrNoPresenceInLock = errors.New("auctioneer lock does not contain a presence")
+
+type locketServiceClient struct {
+	locketClient locketmodels.LocketClient
+	clock        clock.Clock
+}
+
+// NewLocketServiceClient returns a ServiceClient that uses the locket lock
+// for LocketLockKey. The lock runners it creates store the presence as the
+// value of the lock, owned by the presence's ID, so that the current
+// auctioneer can be found without consul.
+func NewLocketServiceClient(locketClient locketmodels.LocketClient, clock clock.Clock) ServiceClient {
+	return locketServiceClient{
+		locketClient: locketClient,
+		clock:        clock,
+	}
+}
+
+func (c locketServiceClient) NewAuctioneerLockRunner(logger lager.Logger, presence Presence, retryInterval, lockTTL time.Duration, metronClient loggingclient.IngressClient) (ifrit.Runner, error) {
+	if err := presence.Validate(); err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(presence)
+	if err != nil {
+		return nil, err
+	}
+
+	ttlInSeconds := int64(lockTTL / time.Second)
+	if ttlInSeconds <= 0 {
+		ttlInSeconds = locket.DefaultSessionTTLInSeconds
+	}
+
+	lockIdentifier := &locketmodels.Resource{
+		Key:      LocketLockKey,
+		Owner:    presence.AuctioneerID,
+		Value:    string(payload),
+		TypeCode: locketmodels.LOCK,
+		Type:     locketmodels.LockType,
+	}
+
+	return lock.NewLockRunner(logger, c.locketClient, lockIdentifier, ttlInSeconds, c.clock, retryInterval), nil
+}
+
+func (c locketServiceClient) CurrentAuctioneer() (Presence, error) {
+	resp, err := c.locketClient.Fetch(context.Background(), &locketmodels.FetchRequest{Key: LocketLockKey})
+	if err != nil {
+		return Presence{}, err
+	}
+
+	if resp.Resource == nil || resp.Resource.Value == "" {
+		return Presence{}, ErrNoPresenceInLock
+	}
+
+	return decodePresence([]byte(resp.Resource.Value))
+}
+
+func (c locketServiceClient) CurrentAuctioneerAddress() (string, error) {
+	presence, err := c.CurrentAuctioneer()
+	return presence.AuctioneerAddress, err
+}
+
+// WatchAuctioneer polls the locket lock every DefaultWatchPollInterval, as
+// locket has no way to wait for a change.
+func (c locketServiceClient) WatchAuctioneer(logger lager.Logger, stop <-chan struct{}) <-chan Presence {
+	fetch := func(lager.Logger) (Presence, error) {
+		return c.CurrentAuctioneer()
+	}
+
+	return presenceWatcher{
+		clock:         c.clock,
+		fetch:         fetch,
+		pollInterval:  DefaultWatchPollInterval,
+		retryInterval: DefaultWatchRetryInterval,
+		debounce:      DefaultWatchDebounce,
+	}.watch(logger.Session("watch-auctioneer"), stop)
+}
@@ -0,0 +1,202 @@
+package auctioneer_test
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
+	"code.cloudfoundry.org/lager/lagertest"
+	locketmodels "code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LocketServiceClient", func() {
+	var (
+		fakeLocketClient *modelsfakes.FakeLocketClient
+		fakeClock        *fakeclock.FakeClock
+		serviceClient    auctioneer.ServiceClient
+		presence         auctioneer.Presence
+	)
+
+	BeforeEach(func() {
+		fakeLocketClient = new(modelsfakes.FakeLocketClient)
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		serviceClient = auctioneer.NewLocketServiceClient(fakeLocketClient, fakeClock)
+		presence = auctioneer.NewPresence("auctioneer-id", "https://auctioneer.example.com:9016")
+	})
+
+	Describe("NewAuctioneerLockRunner", func() {
+		var process ifrit.Process
+
+		BeforeEach(func() {
+			process = nil
+		})
+
+		AfterEach(func() {
+			if process != nil {
+				ginkgomon.Interrupt(process)
+			}
+		})
+
+		It("locks the auctioneer key with the presence as its value", func() {
+			runner, err := serviceClient.NewAuctioneerLockRunner(lagertest.NewTestLogger("test"), presence, time.Second, 10*time.Second, &mfakes.FakeIngressClient{})
+			Expect(err).NotTo(HaveOccurred())
+
+			process = ginkgomon.Invoke(runner)
+			Expect(fakeLocketClient.LockCallCount()).To(BeNumerically(">=", 1))
+
+			_, req, _ := fakeLocketClient.LockArgsForCall(0)
+			Expect(req.Resource.Key).To(Equal(auctioneer.LocketLockKey))
+			Expect(req.Resource.Owner).To(Equal("auctioneer-id"))
+			Expect(req.TtlInSeconds).To(Equal(int64(10)))
+
+			stored := auctioneer.Presence{}
+			Expect(json.Unmarshal([]byte(req.Resource.Value), &stored)).To(Succeed())
+			Expect(stored).To(Equal(presence))
+		})
+
+		It("rejects an invalid presence", func() {
+			_, err := serviceClient.NewAuctioneerLockRunner(lagertest.NewTestLogger("test"), auctioneer.Presence{}, time.Second, 10*time.Second, &mfakes.FakeIngressClient{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("CurrentAuctioneerAddress", func() {
+		Context("when the lock holds a presence", func() {
+			BeforeEach(func() {
+				payload, err := json.Marshal(presence)
+				Expect(err).NotTo(HaveOccurred())
+				fakeLocketClient.FetchReturns(&locketmodels.FetchResponse{
+					Resource: &locketmodels.Resource{Key: auctioneer.LocketLockKey, Owner: "auctioneer-id", Value: string(payload)},
+				}, nil)
+			})
+
+			It("returns the address", func() {
+				address, err := serviceClient.CurrentAuctioneerAddress()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(address).To(Equal(presence.AuctioneerAddress))
+
+				_, req, _ := fakeLocketClient.FetchArgsForCall(0)
+				Expect(req.Key).To(Equal(auctioneer.LocketLockKey))
+			})
+		})
+
+		Context("when the lock has no value", func() {
+			BeforeEach(func() {
+				fakeLocketClient.FetchReturns(&locketmodels.FetchResponse{
+					Resource: &locketmodels.Resource{Key: auctioneer.LocketLockKey, Owner: "auctioneer-id"},
+				}, nil)
+			})
+
+			It("returns ErrNoPresenceInLock", func() {
+				_, err := serviceClient.CurrentAuctioneerAddress()
+				Expect(err).To(Equal(auctioneer.ErrNoPresenceInLock))
+			})
+		})
+
+		Context("when the lock is not held", func() {
+			BeforeEach(func() {
+				fakeLocketClient.FetchReturns(nil, locketmodels.ErrResourceNotFound)
+			})
+
+			It("returns the error", func() {
+				_, err := serviceClient.CurrentAuctioneerAddress()
+				Expect(err).To(Equal(locketmodels.ErrResourceNotFound))
+			})
+		})
+
+		Context("when locket fails", func() {
+			BeforeEach(func() {
+				fakeLocketClient.FetchReturns(nil, errors.New("boom"))
+			})
+
+			It("returns an error", func() {
+				_, err := serviceClient.CurrentAuctioneerAddress()
+				Expect(err).To(MatchError("boom"))
+			})
+		})
+	})
+
+	Describe("WatchAuctioneer", func() {
+		var (
+			stop      chan struct{}
+			presences <-chan auctioneer.Presence
+		)
+
+		lockHolding := func(presence auctioneer.Presence) *locketmodels.FetchResponse {
+			payload, err := json.Marshal(presence)
+			Expect(err).NotTo(HaveOccurred())
+			return &locketmodels.FetchResponse{
+				Resource: &locketmodels.Resource{Key: auctioneer.LocketLockKey, Owner: presence.AuctioneerID, Value: string(payload)},
+			}
+		}
+
+		advance := func() <-chan auctioneer.Presence {
+			fakeClock.Increment(auctioneer.DefaultWatchPollInterval)
+			return presences
+		}
+
+		BeforeEach(func() {
+			stop = make(chan struct{})
+			fakeLocketClient.FetchReturns(lockHolding(presence), nil)
+		})
+
+		JustBeforeEach(func() {
+			presences = serviceClient.WatchAuctioneer(lagertest.NewTestLogger("test"), stop)
+		})
+
+		AfterEach(func() {
+			select {
+			case <-stop:
+			default:
+				close(stop)
+			}
+		})
+
+		It("sends the presence of the current auctioneer", func() {
+			Eventually(advance).Should(Receive(Equal(presence)))
+		})
+
+		It("sends the new presence when the lock changes hands", func() {
+			Eventually(advance).Should(Receive(Equal(presence)))
+
+			newPresence := auctioneer.NewPresence("new-auctioneer-id", "https://new-auctioneer.example.com:9016")
+			fakeLocketClient.FetchReturns(lockHolding(newPresence), nil)
+
+			Eventually(advance).Should(Receive(Equal(newPresence)))
+		})
+
+		It("does not send the presence again while it is unchanged", func() {
+			Eventually(advance).Should(Receive(Equal(presence)))
+
+			for i := 0; i < 5; i++ {
+				fakeClock.Increment(auctioneer.DefaultWatchPollInterval)
+			}
+			Consistently(presences).ShouldNot(Receive())
+		})
+
+		Context("when locket is unavailable at first", func() {
+			BeforeEach(func() {
+				fakeLocketClient.FetchReturnsOnCall(0, nil, errors.New("boom"))
+			})
+
+			It("keeps trying", func() {
+				Eventually(advance).Should(Receive(Equal(presence)))
+				Expect(fakeLocketClient.FetchCallCount()).To(BeNumerically(">", 1))
+			})
+		})
+
+		It("closes the channel once stopped", func() {
+			close(stop)
+			Eventually(presences).Should(BeClosed())
+		})
+	})
+})
@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+
+	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	loggregator "code.cloudfoundry.org/go-loggregator"
+	"google.golang.org/grpc"
+)
+
+// LoggregatorClient is the subset of the go-loggregator ingress client used
+// by the loggregator sink.
+type LoggregatorClient interface {
+	EmitGauge(opts ...loggregator.EmitGaugeOption)
+	EmitCounter(name string, opts ...loggregator.EmitCounterOption)
+}
+
+type loggregatorSink struct {
+	client     LoggregatorClient
+	sourceID   string
+	instanceID string
+}
+
+// NewLoggregatorSink connects to the loggregator agent the same way as
+// loggingclient.NewIngressClient. Metrics are discarded unless the v2 API is
+// enabled. Tags are sent as envelope tags.
+func NewLoggregatorSink(config loggingclient.Config) (Sink, error) {
+	if !config.UseV2API {
+		return NewNoopSink(), nil
+	}
+
+	tlsConfig, err := loggregator.NewIngressTLSConfig(
+		config.CACertPath,
+		config.CertPath,
+		config.KeyPath,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []loggregator.IngressOption{
+		loggregator.WithTag("origin", config.JobOrigin),
+	}
+
+	if config.BatchMaxSize != 0 {
+		opts = append(opts, loggregator.WithBatchMaxSize(config.BatchMaxSize))
+	}
+
+	if config.BatchFlushInterval != time.Duration(0) {
+		opts = append(opts, loggregator.WithBatchFlushInterval(config.BatchFlushInterval))
+	}
+
+	if config.APIPort != 0 {
+		opts = append(opts, loggregator.WithAddr(fmt.Sprintf("127.0.0.1:%d", config.APIPort)))
+	}
+
+	opts = append(opts, loggregator.WithDialOptions(grpc.WithBlock(), grpc.WithTimeout(time.Second)))
+
+	c, err := loggregator.NewIngressClient(tlsConfig, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return WrapLoggregatorClient(c, config.SourceID, config.InstanceID), nil
+}
+
+// WrapLoggregatorClient returns a Sink that emits envelopes through the given
+// go-loggregator client.
+func WrapLoggregatorClient(client LoggregatorClient, sourceID, instanceID string) Sink {
+	return &loggregatorSink{
+		client:     client,
+		sourceID:   sourceID,
+		instanceID: instanceID,
+	}
+}
+
+func (l *loggregatorSink) IncrementCounter(name string, delta uint64, tags map[string]string) error {
+	l.client.EmitCounter(
+		name,
+		loggregator.WithCounterSourceInfo(l.sourceID, l.instanceID),
+		loggregator.WithDelta(delta),
+		loggregator.WithEnvelopeTags(tags),
+	)
+	return nil
+}
+
+func (l *loggregatorSink) SendGauge(name string, value float64, unit string, tags map[string]string) error {
+	l.client.EmitGauge(
+		loggregator.WithGaugeSourceInfo(l.sourceID, l.instanceID),
+		loggregator.WithGaugeValue(name, value, unit),
+		loggregator.WithEnvelopeTags(tags),
+	)
+	return nil
+}
+
+func (l *loggregatorSink) SendDuration(name string, value time.Duration, tags map[string]string) error {
+	return l.SendGauge(name, float64(value), "nanos", tags)
+}
@@ -0,0 +1,97 @@
+package metrics_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/metrics"
+	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	loggregator "code.cloudfoundry.org/go-loggregator"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LoggregatorSink", func() {
+	var (
+		fakeClient *fakeLoggregatorClient
+		sink       metrics.Sink
+	)
+
+	BeforeEach(func() {
+		fakeClient = &fakeLoggregatorClient{}
+		sink = metrics.WrapLoggregatorClient(fakeClient, "source-id", "instance-id")
+	})
+
+	It("emits counters with envelope tags", func() {
+		err := sink.IncrementCounter("AuctioneerLRPAuctionsStartedByPlacement", 3, map[string]string{"domain": "cf-apps"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeClient.envelopes).To(HaveLen(1))
+		envelope := fakeClient.envelopes[0]
+		Expect(envelope.SourceId).To(Equal("source-id"))
+		Expect(envelope.InstanceId).To(Equal("instance-id"))
+		Expect(envelope.GetCounter().GetName()).To(Equal("AuctioneerLRPAuctionsStartedByPlacement"))
+		Expect(envelope.GetCounter().GetDelta()).To(BeEquivalentTo(3))
+		Expect(envelope.Tags).To(Equal(map[string]string{"domain": "cf-apps"}))
+	})
+
+	It("emits gauges with their unit", func() {
+		err := sink.SendGauge("LockHeld", 1, metrics.MetricUnit, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeClient.envelopes).To(HaveLen(1))
+		value := fakeClient.envelopes[0].GetGauge().GetMetrics()["LockHeld"]
+		Expect(value.GetValue()).To(Equal(1.0))
+		Expect(value.GetUnit()).To(Equal("Metric"))
+	})
+
+	It("emits durations as gauges in nanoseconds", func() {
+		err := sink.SendDuration("RequestLatency", time.Second, map[string]string{"route": "tasks"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeClient.envelopes).To(HaveLen(1))
+		value := fakeClient.envelopes[0].GetGauge().GetMetrics()["RequestLatency"]
+		Expect(value.GetValue()).To(Equal(float64(time.Second)))
+		Expect(value.GetUnit()).To(Equal("nanos"))
+		Expect(fakeClient.envelopes[0].Tags).To(Equal(map[string]string{"route": "tasks"}))
+	})
+
+	Context("when the v2 API is disabled", func() {
+		It("discards metrics without connecting to loggregator", func() {
+			sink, err := metrics.NewLoggregatorSink(loggingclient.Config{UseV2API: false})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sink.IncrementCounter("RequestCount", 1, nil)).To(Succeed())
+		})
+	})
+})
+
+type fakeLoggregatorClient struct {
+	envelopes []*loggregator_v2.Envelope
+}
+
+func (f *fakeLoggregatorClient) EmitGauge(opts ...loggregator.EmitGaugeOption) {
+	envelope := &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Gauge{
+			Gauge: &loggregator_v2.Gauge{Metrics: map[string]*loggregator_v2.GaugeValue{}},
+		},
+		Tags: map[string]string{},
+	}
+	for _, opt := range opts {
+		opt(envelope)
+	}
+	f.envelopes = append(f.envelopes, envelope)
+}
+
+func (f *fakeLoggregatorClient) EmitCounter(name string, opts ...loggregator.EmitCounterOption) {
+	envelope := &loggregator_v2.Envelope{
+		Message: &loggregator_v2.Envelope_Counter{
+			Counter: &loggregator_v2.Counter{Name: name},
+		},
+		Tags: map[string]string{},
+	}
+	for _, opt := range opts {
+		opt(envelope)
+	}
+	f.envelopes = append(f.envelopes, envelope)
+}
@@ -0,0 +1,177 @@
+// This file was generated by counterfeiter
+package metricsfakes
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/metrics"
+)
+
+type FakeSink struct {
+	IncrementCounterStub        func(name string, delta uint64, tags map[string]string) error
+	incrementCounterMutex       sync.RWMutex
+	incrementCounterArgsForCall []struct {
+		name  string
+		delta uint64
+		tags  map[string]string
+	}
+	incrementCounterReturns struct {
+		result1 error
+	}
+	SendGaugeStub        func(name string, value float64, unit string, tags map[string]string) error
+	sendGaugeMutex       sync.RWMutex
+	sendGaugeArgsForCall []struct {
+		name  string
+		value float64
+		unit  string
+		tags  map[string]string
+	}
+	sendGaugeReturns struct {
+		result1 error
+	}
+	SendDurationStub        func(name string, value time.Duration, tags map[string]string) error
+	sendDurationMutex       sync.RWMutex
+	sendDurationArgsForCall []struct {
+		name  string
+		value time.Duration
+		tags  map[string]string
+	}
+	sendDurationReturns struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeSink) IncrementCounter(name string, delta uint64, tags map[string]string) error {
+	fake.incrementCounterMutex.Lock()
+	fake.incrementCounterArgsForCall = append(fake.incrementCounterArgsForCall, struct {
+		name  string
+		delta uint64
+		tags  map[string]string
+	}{name, delta, tags})
+	fake.recordInvocation("IncrementCounter", []interface{}{name, delta, tags})
+	fake.incrementCounterMutex.Unlock()
+	if fake.IncrementCounterStub != nil {
+		return fake.IncrementCounterStub(name, delta, tags)
+	} else {
+		return fake.incrementCounterReturns.result1
+	}
+}
+
+func (fake *FakeSink) IncrementCounterCallCount() int {
+	fake.incrementCounterMutex.RLock()
+	defer fake.incrementCounterMutex.RUnlock()
+	return len(fake.incrementCounterArgsForCall)
+}
+
+func (fake *FakeSink) IncrementCounterArgsForCall(i int) (string, uint64, map[string]string) {
+	fake.incrementCounterMutex.RLock()
+	defer fake.incrementCounterMutex.RUnlock()
+	return fake.incrementCounterArgsForCall[i].name, fake.incrementCounterArgsForCall[i].delta, fake.incrementCounterArgsForCall[i].tags
+}
+
+func (fake *FakeSink) IncrementCounterReturns(result1 error) {
+	fake.IncrementCounterStub = nil
+	fake.incrementCounterReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSink) SendGauge(name string, value float64, unit string, tags map[string]string) error {
+	fake.sendGaugeMutex.Lock()
+	fake.sendGaugeArgsForCall = append(fake.sendGaugeArgsForCall, struct {
+		name  string
+		value float64
+		unit  string
+		tags  map[string]string
+	}{name, value, unit, tags})
+	fake.recordInvocation("SendGauge", []interface{}{name, value, unit, tags})
+	fake.sendGaugeMutex.Unlock()
+	if fake.SendGaugeStub != nil {
+		return fake.SendGaugeStub(name, value, unit, tags)
+	} else {
+		return fake.sendGaugeReturns.result1
+	}
+}
+
+func (fake *FakeSink) SendGaugeCallCount() int {
+	fake.sendGaugeMutex.RLock()
+	defer fake.sendGaugeMutex.RUnlock()
+	return len(fake.sendGaugeArgsForCall)
+}
+
+func (fake *FakeSink) SendGaugeArgsForCall(i int) (string, float64, string, map[string]string) {
+	fake.sendGaugeMutex.RLock()
+	defer fake.sendGaugeMutex.RUnlock()
+	return fake.sendGaugeArgsForCall[i].name, fake.sendGaugeArgsForCall[i].value, fake.sendGaugeArgsForCall[i].unit, fake.sendGaugeArgsForCall[i].tags
+}
+
+func (fake *FakeSink) SendGaugeReturns(result1 error) {
+	fake.SendGaugeStub = nil
+	fake.sendGaugeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSink) SendDuration(name string, value time.Duration, tags map[string]string) error {
+	fake.sendDurationMutex.Lock()
+	fake.sendDurationArgsForCall = append(fake.sendDurationArgsForCall, struct {
+		name  string
+		value time.Duration
+		tags  map[string]string
+	}{name, value, tags})
+	fake.recordInvocation("SendDuration", []interface{}{name, value, tags})
+	fake.sendDurationMutex.Unlock()
+	if fake.SendDurationStub != nil {
+		return fake.SendDurationStub(name, value, tags)
+	} else {
+		return fake.sendDurationReturns.result1
+	}
+}
+
+func (fake *FakeSink) SendDurationCallCount() int {
+	fake.sendDurationMutex.RLock()
+	defer fake.sendDurationMutex.RUnlock()
+	return len(fake.sendDurationArgsForCall)
+}
+
+func (fake *FakeSink) SendDurationArgsForCall(i int) (string, time.Duration, map[string]string) {
+	fake.sendDurationMutex.RLock()
+	defer fake.sendDurationMutex.RUnlock()
+	return fake.sendDurationArgsForCall[i].name, fake.sendDurationArgsForCall[i].value, fake.sendDurationArgsForCall[i].tags
+}
+
+func (fake *FakeSink) SendDurationReturns(result1 error) {
+	fake.SendDurationStub = nil
+	fake.sendDurationReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSink) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.incrementCounterMutex.RLock()
+	defer fake.incrementCounterMutex.RUnlock()
+	fake.sendGaugeMutex.RLock()
+	defer fake.sendGaugeMutex.RUnlock()
+	fake.sendDurationMutex.RLock()
+	defer fake.sendDurationMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeSink) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ metrics.Sink = new(FakeSink)
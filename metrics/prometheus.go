@@ -0,0 +1,214 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DurationBuckets are the histogram buckets, in seconds, used for every
+// duration sent to a PrometheusSink.
+var DurationBuckets = []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+// PrometheusSink exposes metrics in the Prometheus text format. Metric names
+// are converted to snake case: counters get a _total suffix, durations become
+// histograms in seconds and gauges get a suffix naming their unit. Tags
+// become labels.
+type PrometheusSink struct {
+	registry *prometheus.Registry
+
+	lock       sync.Mutex
+	counters   map[string]*prometheus.CounterVec
+	gauges     map[string]*prometheus.GaugeVec
+	histograms map[string]*prometheus.HistogramVec
+	labels     map[string][]string
+}
+
+func NewPrometheusSink() *PrometheusSink {
+	return &PrometheusSink{
+		registry:   prometheus.NewRegistry(),
+		counters:   map[string]*prometheus.CounterVec{},
+		gauges:     map[string]*prometheus.GaugeVec{},
+		histograms: map[string]*prometheus.HistogramVec{},
+		labels:     map[string][]string{},
+	}
+}
+
+// Handler serves the collected metrics.
+func (p *PrometheusSink) Handler() http.Handler {
+	return promhttp.HandlerFor(p.registry, promhttp.HandlerOpts{})
+}
+
+func (p *PrometheusSink) IncrementCounter(name string, delta uint64, tags map[string]string) error {
+	labels := prometheusLabels(tags)
+	counter, err := p.counter(PrometheusName(name)+"_total", name, labelNames(labels))
+	if err != nil {
+		return err
+	}
+	counter.With(labels).Add(float64(delta))
+	return nil
+}
+
+func (p *PrometheusSink) SendGauge(name string, value float64, unit string, tags map[string]string) error {
+	labels := prometheusLabels(tags)
+	gauge, err := p.gauge(PrometheusName(name)+unitSuffix(unit), name, labelNames(labels))
+	if err != nil {
+		return err
+	}
+	gauge.With(labels).Set(value)
+	return nil
+}
+
+func (p *PrometheusSink) SendDuration(name string, value time.Duration, tags map[string]string) error {
+	labels := prometheusLabels(tags)
+	histogram, err := p.histogram(PrometheusName(name)+"_seconds", name, labelNames(labels))
+	if err != nil {
+		return err
+	}
+	histogram.With(labels).Observe(value.Seconds())
+	return nil
+}
+
+func (p *PrometheusSink) counter(promName, name string, labels []string) (*prometheus.CounterVec, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if err := p.checkLabels(promName, labels); err != nil {
+		return nil, err
+	}
+
+	counter, ok := p.counters[promName]
+	if !ok {
+		counter = prometheus.NewCounterVec(prometheus.CounterOpts{Name: promName, Help: name}, labels)
+		if err := p.register(promName, labels, counter); err != nil {
+			return nil, err
+		}
+		p.counters[promName] = counter
+	}
+	return counter, nil
+}
+
+func (p *PrometheusSink) gauge(promName, name string, labels []string) (*prometheus.GaugeVec, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if err := p.checkLabels(promName, labels); err != nil {
+		return nil, err
+	}
+
+	gauge, ok := p.gauges[promName]
+	if !ok {
+		gauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: promName, Help: name}, labels)
+		if err := p.register(promName, labels, gauge); err != nil {
+			return nil, err
+		}
+		p.gauges[promName] = gauge
+	}
+	return gauge, nil
+}
+
+func (p *PrometheusSink) histogram(promName, name string, labels []string) (*prometheus.HistogramVec, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if err := p.checkLabels(promName, labels); err != nil {
+		return nil, err
+	}
+
+	histogram, ok := p.histograms[promName]
+	if !ok {
+		histogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: promName, Help: name, Buckets: DurationBuckets}, labels)
+		if err := p.register(promName, labels, histogram); err != nil {
+			return nil, err
+		}
+		p.histograms[promName] = histogram
+	}
+	return histogram, nil
+}
+
+// checkLabels makes sure a metric is always sent with the same set of tags;
+// Prometheus requires a fixed set of labels per metric.
+func (p *PrometheusSink) checkLabels(promName string, labels []string) error {
+	registered, ok := p.labels[promName]
+	if !ok {
+		return nil
+	}
+
+	if strings.Join(registered, ",") != strings.Join(labels, ",") {
+		return fmt.Errorf("metric %s has labels [%s], got [%s]", promName, strings.Join(registered, ","), strings.Join(labels, ","))
+	}
+	return nil
+}
+
+func (p *PrometheusSink) register(promName string, labels []string, collector prometheus.Collector) error {
+	if err := p.registry.Register(collector); err != nil {
+		return err
+	}
+	p.labels[promName] = labels
+	return nil
+}
+
+func unitSuffix(unit string) string {
+	switch unit {
+	case MebiBytesUnit:
+		return "_mebibytes"
+	case BytesPerSecondUnit:
+		return "_bytes_per_second"
+	case RequestsPerSecondUnit:
+		return "_requests_per_second"
+	case SecondsUnit:
+		return "_seconds"
+	default:
+		return ""
+	}
+}
+
+func prometheusLabels(tags map[string]string) prometheus.Labels {
+	labels := prometheus.Labels{}
+	for name, value := range tags {
+		labels[PrometheusName(name)] = value
+	}
+	return labels
+}
+
+func labelNames(labels prometheus.Labels) []string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PrometheusName converts a loggregator metric name such as
+// AuctioneerLRPAuctionsStarted to auctioneer_lrp_auctions_started.
+func PrometheusName(name string) string {
+	runes := []rune(name)
+	converted := make([]rune, 0, len(runes)+5)
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			converted = append(converted, '_')
+			continue
+		}
+
+		if unicode.IsUpper(r) && i > 0 {
+			previous := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+				converted = append(converted, '_')
+			}
+		}
+
+		converted = append(converted, unicode.ToLower(r))
+	}
+
+	return string(converted)
+}
@@ -0,0 +1,80 @@
+package metrics_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/metrics"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PrometheusSink", func() {
+	var sink *metrics.PrometheusSink
+
+	BeforeEach(func() {
+		sink = metrics.NewPrometheusSink()
+	})
+
+	scrape := func() string {
+		recorder := httptest.NewRecorder()
+		sink.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		body, err := ioutil.ReadAll(recorder.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return string(body)
+	}
+
+	It("exposes counters with a _total suffix", func() {
+		Expect(sink.IncrementCounter("AuctioneerLRPAuctionsStarted", 1, nil)).To(Succeed())
+		Expect(sink.IncrementCounter("AuctioneerLRPAuctionsStarted", 4, nil)).To(Succeed())
+
+		Expect(scrape()).To(ContainSubstring("auctioneer_lrp_auctions_started_total 5"))
+	})
+
+	It("exposes durations as histograms in seconds", func() {
+		Expect(sink.SendDuration("RequestLatency", 300*time.Millisecond, nil)).To(Succeed())
+
+		body := scrape()
+		Expect(body).To(ContainSubstring(`request_latency_seconds_bucket{le="0.5"} 1`))
+		Expect(body).To(ContainSubstring(`request_latency_seconds_bucket{le="0.25"} 0`))
+		Expect(body).To(ContainSubstring("request_latency_seconds_count 1"))
+	})
+
+	It("exposes gauges with a suffix naming their unit", func() {
+		Expect(sink.SendGauge("LockHeld", 1, metrics.MetricUnit, nil)).To(Succeed())
+		Expect(sink.SendGauge("AuctioneerCapacityTotalMemory", 2048, metrics.MebiBytesUnit, nil)).To(Succeed())
+		Expect(sink.SendGauge("memoryStats.numBytesAllocated", 2.5, "count", nil)).To(Succeed())
+		Expect(sink.SendGauge("AuctioneerCertificateTimeToExpiry", 3600, metrics.SecondsUnit, nil)).To(Succeed())
+
+		body := scrape()
+		Expect(body).To(ContainSubstring("lock_held 1"))
+		Expect(body).To(ContainSubstring("auctioneer_capacity_total_memory_mebibytes 2048"))
+		Expect(body).To(ContainSubstring("memory_stats_num_bytes_allocated 2.5"))
+		Expect(body).To(ContainSubstring("auctioneer_certificate_time_to_expiry_seconds 3600"))
+	})
+
+	It("turns tags into labels", func() {
+		Expect(sink.IncrementCounter("AuctioneerLRPAuctionsFailedByPlacement", 2, map[string]string{"domain": "cf-apps", "PlacementTags": "isolated"})).To(Succeed())
+
+		Expect(scrape()).To(ContainSubstring(`auctioneer_lrp_auctions_failed_by_placement_total{domain="cf-apps",placement_tags="isolated"} 2`))
+	})
+
+	It("returns an error when a metric is sent with a different set of tags", func() {
+		Expect(sink.SendGauge("RequestCount", 3, metrics.MetricUnit, map[string]string{"RequestType": "LRPAuction"})).To(Succeed())
+
+		Expect(sink.SendGauge("RequestCount", 3, metrics.MetricUnit, nil)).NotTo(Succeed())
+	})
+
+	Describe("PrometheusName", func() {
+		It("converts metric names to snake case", func() {
+			Expect(metrics.PrometheusName("AuctioneerLRPAuctionsStarted")).To(Equal("auctioneer_lrp_auctions_started"))
+			Expect(metrics.PrometheusName("RequestCount")).To(Equal("request_count"))
+			Expect(metrics.PrometheusName("BBSUpdateDuration")).To(Equal("bbs_update_duration"))
+			Expect(metrics.PrometheusName("Request-Type")).To(Equal("request_type"))
+		})
+	})
+})
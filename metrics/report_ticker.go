@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+)
+
+// ReportTicker shares a single ticker between the notifiers that report on
+// the report interval. Each of them subscribes and receives every tick, so
+// they all report on the same ticks. A tick is dropped for a subscriber that
+// has not yet received the previous one, like a clock.Ticker does.
+type ReportTicker struct {
+	ticker clock.Ticker
+
+	lock        sync.Mutex
+	subscribers map[*reportSubscriber]struct{}
+}
+
+func NewReportTicker(ticker clock.Ticker) *ReportTicker {
+	return &ReportTicker{
+		ticker:      ticker,
+		subscribers: map[*reportSubscriber]struct{}{},
+	}
+}
+
+// Subscribe returns a clock.Ticker that receives the ticks of the report
+// ticker. Stopping it only unsubscribes it.
+func (t *ReportTicker) Subscribe() clock.Ticker {
+	subscriber := &reportSubscriber{ticks: make(chan time.Time, 1), ticker: t}
+
+	t.lock.Lock()
+	t.subscribers[subscriber] = struct{}{}
+	t.lock.Unlock()
+
+	return subscriber
+}
+
+func (t *ReportTicker) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	close(ready)
+
+	for {
+		select {
+		case tick := <-t.ticker.C():
+			t.broadcast(tick)
+
+		case <-signals:
+			t.ticker.Stop()
+			return nil
+		}
+	}
+}
+
+func (t *ReportTicker) broadcast(tick time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for subscriber := range t.subscribers {
+		select {
+		case subscriber.ticks <- tick:
+		default:
+		}
+	}
+}
+
+func (t *ReportTicker) unsubscribe(subscriber *reportSubscriber) {
+	t.lock.Lock()
+	delete(t.subscribers, subscriber)
+	t.lock.Unlock()
+}
+
+type reportSubscriber struct {
+	ticks  chan time.Time
+	ticker *ReportTicker
+}
+
+func (s *reportSubscriber) C() <-chan time.Time {
+	return s.ticks
+}
+
+func (s *reportSubscriber) Stop() {
+	s.ticker.unsubscribe(s)
+}
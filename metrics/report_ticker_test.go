@@ -0,0 +1,71 @@
+package metrics_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/metrics"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReportTicker", func() {
+	var (
+		fakeClock    *fakeclock.FakeClock
+		reportTicker *metrics.ReportTicker
+		process      ifrit.Process
+	)
+
+	BeforeEach(func() {
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		reportTicker = metrics.NewReportTicker(fakeClock.NewTicker(time.Minute))
+	})
+
+	JustBeforeEach(func() {
+		process = ginkgomon.Invoke(reportTicker)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+	})
+
+	It("delivers every tick to every subscriber", func() {
+		first := reportTicker.Subscribe()
+		second := reportTicker.Subscribe()
+
+		fakeClock.WaitForWatcherAndIncrement(time.Minute)
+		Eventually(first.C()).Should(Receive())
+		Eventually(second.C()).Should(Receive())
+
+		fakeClock.WaitForWatcherAndIncrement(time.Minute)
+		Eventually(first.C()).Should(Receive())
+		Eventually(second.C()).Should(Receive())
+	})
+
+	It("stops delivering ticks to a stopped subscriber", func() {
+		stopped := reportTicker.Subscribe()
+		running := reportTicker.Subscribe()
+		stopped.Stop()
+
+		fakeClock.WaitForWatcherAndIncrement(time.Minute)
+		Eventually(running.C()).Should(Receive())
+		Consistently(stopped.C()).ShouldNot(Receive())
+	})
+
+	It("does not block on a subscriber that has not received the previous tick", func() {
+		slow := reportTicker.Subscribe()
+		fast := reportTicker.Subscribe()
+
+		fakeClock.WaitForWatcherAndIncrement(time.Minute)
+		Eventually(fast.C()).Should(Receive())
+
+		fakeClock.WaitForWatcherAndIncrement(time.Minute)
+		Eventually(fast.C()).Should(Receive())
+
+		Expect(slow.C()).To(Receive())
+		Expect(slow.C()).NotTo(Receive())
+	})
+})
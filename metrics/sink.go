@@ -0,0 +1,75 @@
+package metrics
+
+import "time"
+
+const (
+	LoggregatorSinkName = "loggregator"
+	StatsdSinkName      = "statsd"
+	PrometheusSinkName  = "prometheus"
+	NoopSinkName        = "none"
+)
+
+// Units of the gauges sent to a Sink. They match the units used by
+// diego-logging-client.
+const (
+	MebiBytesUnit         = "MiB"
+	MetricUnit            = "Metric"
+	BytesPerSecondUnit    = "B/s"
+	RequestsPerSecondUnit = "Req/s"
+	SecondsUnit           = "s"
+)
+
+//go:generate counterfeiter -o metricsfakes/fake_sink.go . Sink
+
+// Sink receives every metric emitted by the auctioneer. Tags may be nil.
+type Sink interface {
+	IncrementCounter(name string, delta uint64, tags map[string]string) error
+	SendGauge(name string, value float64, unit string, tags map[string]string) error
+	SendDuration(name string, value time.Duration, tags map[string]string) error
+}
+
+type noopSink struct{}
+
+// NewNoopSink returns a Sink that discards every metric.
+func NewNoopSink() Sink {
+	return noopSink{}
+}
+
+func (noopSink) IncrementCounter(string, uint64, map[string]string) error    { return nil }
+func (noopSink) SendGauge(string, float64, string, map[string]string) error  { return nil }
+func (noopSink) SendDuration(string, time.Duration, map[string]string) error { return nil }
+
+type fanoutSink struct {
+	sinks []Sink
+}
+
+// NewFanoutSink returns a Sink that sends every metric to each of the sinks.
+// The first error encountered is returned once every sink has been called.
+func NewFanoutSink(sinks ...Sink) Sink {
+	if len(sinks) == 1 {
+		return sinks[0]
+	}
+	return &fanoutSink{sinks: sinks}
+}
+
+func (f *fanoutSink) each(send func(Sink) error) error {
+	var firstErr error
+	for _, sink := range f.sinks {
+		if err := send(sink); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (f *fanoutSink) IncrementCounter(name string, delta uint64, tags map[string]string) error {
+	return f.each(func(s Sink) error { return s.IncrementCounter(name, delta, tags) })
+}
+
+func (f *fanoutSink) SendGauge(name string, value float64, unit string, tags map[string]string) error {
+	return f.each(func(s Sink) error { return s.SendGauge(name, value, unit, tags) })
+}
+
+func (f *fanoutSink) SendDuration(name string, value time.Duration, tags map[string]string) error {
+	return f.each(func(s Sink) error { return s.SendDuration(name, value, tags) })
+}
@@ -0,0 +1,77 @@
+package metrics_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/metrics"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FanoutSink", func() {
+	var first, second *fakeSink
+
+	BeforeEach(func() {
+		first = &fakeSink{}
+		second = &fakeSink{}
+	})
+
+	It("sends every metric to each sink", func() {
+		sink := metrics.NewFanoutSink(first, second)
+
+		Expect(sink.IncrementCounter("AuctioneerLRPAuctionsStarted", 2, nil)).To(Succeed())
+		Expect(sink.SendGauge("LockHeld", 1, metrics.MetricUnit, nil)).To(Succeed())
+		Expect(sink.SendDuration("RequestLatency", time.Second, nil)).To(Succeed())
+
+		for _, s := range []*fakeSink{first, second} {
+			Expect(s.metrics).To(Equal([]sentMetric{
+				{kind: "counter", name: "AuctioneerLRPAuctionsStarted", value: 2},
+				{kind: "gauge", name: "LockHeld", value: 1, unit: "Metric"},
+				{kind: "duration", name: "RequestLatency", value: float64(time.Second)},
+			}))
+		}
+	})
+
+	Context("when a sink fails", func() {
+		BeforeEach(func() {
+			first.err = errors.New("boom")
+		})
+
+		It("still sends the metric to the remaining sinks and returns the error", func() {
+			sink := metrics.NewFanoutSink(first, second)
+
+			Expect(sink.SendGauge("LockHeld", 1, metrics.MetricUnit, nil)).To(MatchError("boom"))
+			Expect(second.metrics).To(HaveLen(1))
+		})
+	})
+})
+
+type sentMetric struct {
+	kind  string
+	name  string
+	value float64
+	unit  string
+	tags  map[string]string
+}
+
+type fakeSink struct {
+	metrics []sentMetric
+	err     error
+}
+
+func (f *fakeSink) IncrementCounter(name string, delta uint64, tags map[string]string) error {
+	f.metrics = append(f.metrics, sentMetric{kind: "counter", name: name, value: float64(delta), tags: tags})
+	return f.err
+}
+
+func (f *fakeSink) SendGauge(name string, value float64, unit string, tags map[string]string) error {
+	f.metrics = append(f.metrics, sentMetric{kind: "gauge", name: name, value: value, unit: unit, tags: tags})
+	return f.err
+}
+
+func (f *fakeSink) SendDuration(name string, value time.Duration, tags map[string]string) error {
+	f.metrics = append(f.metrics, sentMetric{kind: "duration", name: name, value: float64(value), tags: tags})
+	return f.err
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type statsdSink struct {
+	conn   net.Conn
+	prefix string
+}
+
+// NewStatsdSink sends metrics over UDP to the statsd server at address. Metric
+// names are prefixed with prefix followed by a dot, when prefix is not empty.
+// Tags are sent in the DogStatsD format, which plain statsd servers ignore.
+func NewStatsdSink(address, prefix string) (Sink, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	if prefix != "" {
+		prefix += "."
+	}
+
+	return &statsdSink{conn: conn, prefix: prefix}, nil
+}
+
+func (s *statsdSink) IncrementCounter(name string, delta uint64, tags map[string]string) error {
+	return s.send(name, strconv.FormatUint(delta, 10), "c", tags)
+}
+
+func (s *statsdSink) SendGauge(name string, value float64, unit string, tags map[string]string) error {
+	return s.send(name, strconv.FormatFloat(value, 'f', -1, 64), "g", tags)
+}
+
+func (s *statsdSink) SendDuration(name string, value time.Duration, tags map[string]string) error {
+	milliseconds := float64(value) / float64(time.Millisecond)
+	return s.send(name, strconv.FormatFloat(milliseconds, 'f', -1, 64), "ms", tags)
+}
+
+func (s *statsdSink) send(name, value, metricType string, tags map[string]string) error {
+	line := fmt.Sprintf("%s%s:%s|%s", s.prefix, name, value, metricType)
+
+	if len(tags) > 0 {
+		pairs := make([]string, 0, len(tags))
+		for tag, tagValue := range tags {
+			pairs = append(pairs, tag+":"+tagValue)
+		}
+		sort.Strings(pairs)
+		line += "|#" + strings.Join(pairs, ",")
+	}
+
+	_, err := s.conn.Write([]byte(line))
+	return err
+}
@@ -0,0 +1,59 @@
+package metrics_test
+
+import (
+	"net"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/metrics"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StatsdSink", func() {
+	var (
+		server *net.UDPConn
+		sink   metrics.Sink
+	)
+
+	BeforeEach(func() {
+		var err error
+		server, err = net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+		Expect(err).NotTo(HaveOccurred())
+
+		sink, err = metrics.NewStatsdSink(server.LocalAddr().String(), "auctioneer")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	receive := func() string {
+		buffer := make([]byte, 1024)
+		Expect(server.SetReadDeadline(time.Now().Add(time.Second))).To(Succeed())
+		n, err := server.Read(buffer)
+		Expect(err).NotTo(HaveOccurred())
+		return string(buffer[:n])
+	}
+
+	It("sends counters", func() {
+		Expect(sink.IncrementCounter("AuctioneerLRPAuctionsStarted", 3, nil)).To(Succeed())
+		Expect(receive()).To(Equal("auctioneer.AuctioneerLRPAuctionsStarted:3|c"))
+	})
+
+	It("sends gauges", func() {
+		Expect(sink.SendGauge("LockHeld", 1, metrics.MetricUnit, nil)).To(Succeed())
+		Expect(receive()).To(Equal("auctioneer.LockHeld:1|g"))
+	})
+
+	It("sends durations as timers in milliseconds", func() {
+		Expect(sink.SendDuration("RequestLatency", 1500*time.Microsecond, nil)).To(Succeed())
+		Expect(receive()).To(Equal("auctioneer.RequestLatency:1.5|ms"))
+	})
+
+	It("sends tags in the DogStatsD format", func() {
+		Expect(sink.IncrementCounter("AuctioneerLRPAuctionsFailedByPlacement", 1, map[string]string{"rootfs": "docker", "domain": "cf-apps"})).To(Succeed())
+		Expect(receive()).To(Equal("auctioneer.AuctioneerLRPAuctionsFailedByPlacement:1|c|#domain:cf-apps,rootfs:docker"))
+	})
+})
@@ -0,0 +1 @@
+package pause // import "code.cloudfoundry.org/auctioneer/pause"
@@ -0,0 +1,103 @@
+package pause
+
+import (
+	"os"
+	"sync"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/lager"
+)
+
+// Runner is an auctiontypes.AuctionRunner that operators can pause. While it
+// is paused, submitted work is held rather than auctioned; resuming schedules
+// the held work in the order it was submitted.
+type Runner struct {
+	logger lager.Logger
+	runner auctiontypes.AuctionRunner
+
+	lock   sync.Mutex
+	paused bool
+	tasks  []auctioneer.TaskStartRequest
+	lrps   []auctioneer.LRPStartRequest
+}
+
+func New(logger lager.Logger, runner auctiontypes.AuctionRunner) *Runner {
+	return &Runner{
+		logger: logger.Session("pause"),
+		runner: runner,
+	}
+}
+
+func (r *Runner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	return r.runner.Run(signals, ready)
+}
+
+func (r *Runner) ScheduleLRPsForAuctions(lrps []auctioneer.LRPStartRequest) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.paused {
+		r.lrps = append(r.lrps, lrps...)
+		return
+	}
+	r.runner.ScheduleLRPsForAuctions(lrps)
+}
+
+func (r *Runner) ScheduleTasksForAuctions(tasks []auctioneer.TaskStartRequest) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.paused {
+		r.tasks = append(r.tasks, tasks...)
+		return
+	}
+	r.runner.ScheduleTasksForAuctions(tasks)
+}
+
+// Pause holds work submitted from now on. It returns false if the runner was
+// already paused.
+func (r *Runner) Pause() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.paused {
+		return false
+	}
+
+	r.paused = true
+	r.logger.Info("paused")
+	return true
+}
+
+// Resume schedules the held work and stops holding new work. It returns false
+// if the runner was not paused.
+func (r *Runner) Resume() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.paused {
+		return false
+	}
+
+	r.paused = false
+	tasks, lrps := r.tasks, r.lrps
+	r.tasks, r.lrps = nil, nil
+
+	if len(tasks) > 0 {
+		r.runner.ScheduleTasksForAuctions(tasks)
+	}
+	if len(lrps) > 0 {
+		r.runner.ScheduleLRPsForAuctions(lrps)
+	}
+
+	r.logger.Info("resumed", lager.Data{"tasks": len(tasks), "lrps": len(lrps)})
+	return true
+}
+
+func (r *Runner) Paused() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.paused
+}
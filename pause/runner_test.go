@@ -0,0 +1,109 @@
+package pause_test
+
+import (
+	"os"
+
+	fake_auction_runner "code.cloudfoundry.org/auction/auctiontypes/fakes"
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/auctioneer/pause"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"github.com/tedsuo/ifrit/ginkgomon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Runner", func() {
+	var (
+		logger *lagertest.TestLogger
+		inner  *fake_auction_runner.FakeAuctionRunner
+		runner *pause.Runner
+
+		task auctioneer.TaskStartRequest
+		lrp  auctioneer.LRPStartRequest
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		inner = new(fake_auction_runner.FakeAuctionRunner)
+		runner = pause.New(logger, inner)
+
+		resource := rep.NewResource(10, 10, 10)
+		pc := rep.NewPlacementConstraint("preloaded:linux", nil, nil)
+		task = auctioneer.NewTaskStartRequest(rep.NewTask("task-guid", "domain", resource, pc))
+		lrp = auctioneer.NewLRPStartRequest("process-guid", "domain", []int{0}, resource, pc)
+	})
+
+	It("runs the wrapped runner", func() {
+		inner.RunStub = func(signals <-chan os.Signal, ready chan<- struct{}) error {
+			close(ready)
+			<-signals
+			return nil
+		}
+
+		process := ginkgomon.Invoke(runner)
+		ginkgomon.Interrupt(process)
+		Expect(inner.RunCallCount()).To(Equal(1))
+	})
+
+	It("schedules work while it is not paused", func() {
+		Expect(runner.Paused()).To(BeFalse())
+
+		runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{task})
+		runner.ScheduleLRPsForAuctions([]auctioneer.LRPStartRequest{lrp})
+
+		Expect(inner.ScheduleTasksForAuctionsCallCount()).To(Equal(1))
+		Expect(inner.ScheduleLRPsForAuctionsCallCount()).To(Equal(1))
+	})
+
+	Context("when it is paused", func() {
+		BeforeEach(func() {
+			Expect(runner.Pause()).To(BeTrue())
+		})
+
+		It("reports that it is paused", func() {
+			Expect(runner.Paused()).To(BeTrue())
+			Expect(runner.Pause()).To(BeFalse())
+			Expect(logger).To(gbytes.Say("pause.paused"))
+		})
+
+		It("holds submitted work", func() {
+			runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{task})
+			runner.ScheduleLRPsForAuctions([]auctioneer.LRPStartRequest{lrp})
+
+			Expect(inner.ScheduleTasksForAuctionsCallCount()).To(Equal(0))
+			Expect(inner.ScheduleLRPsForAuctionsCallCount()).To(Equal(0))
+		})
+
+		Context("and then resumed", func() {
+			BeforeEach(func() {
+				runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{task})
+				runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{task})
+				runner.ScheduleLRPsForAuctions([]auctioneer.LRPStartRequest{lrp})
+
+				Expect(runner.Resume()).To(BeTrue())
+			})
+
+			It("schedules the held work at once", func() {
+				Expect(inner.ScheduleTasksForAuctionsCallCount()).To(Equal(1))
+				Expect(inner.ScheduleTasksForAuctionsArgsForCall(0)).To(Equal([]auctioneer.TaskStartRequest{task, task}))
+				Expect(inner.ScheduleLRPsForAuctionsCallCount()).To(Equal(1))
+				Expect(inner.ScheduleLRPsForAuctionsArgsForCall(0)).To(Equal([]auctioneer.LRPStartRequest{lrp}))
+				Expect(logger).To(gbytes.Say(`pause.resumed.*"lrps":1,"tasks":2`))
+			})
+
+			It("schedules new work directly", func() {
+				runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{task})
+				Expect(inner.ScheduleTasksForAuctionsCallCount()).To(Equal(2))
+				Expect(runner.Paused()).To(BeFalse())
+			})
+
+			It("does not resume twice", func() {
+				Expect(runner.Resume()).To(BeFalse())
+				Expect(inner.ScheduleTasksForAuctionsCallCount()).To(Equal(1))
+			})
+		})
+	})
+})
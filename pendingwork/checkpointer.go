@@ -0,0 +1,64 @@
+package pendingwork
+
+import (
+	"os"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+)
+
+// Source returns the work that is currently pending.
+type Source interface {
+	Snapshot() Snapshot
+}
+
+// Checkpointer periodically saves the pending work to the store, and once
+// more when it is signalled so that work left over after draining survives
+// the shutdown. It should only run while the lock is held.
+type Checkpointer struct {
+	logger lager.Logger
+	clock  clock.Clock
+	ticker clock.Ticker
+	source Source
+	store  Store
+}
+
+func NewCheckpointer(logger lager.Logger, clock clock.Clock, ticker clock.Ticker, source Source, store Store) *Checkpointer {
+	return &Checkpointer{
+		logger: logger,
+		clock:  clock,
+		ticker: ticker,
+		source: source,
+		store:  store,
+	}
+}
+
+func (c *Checkpointer) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := c.logger.Session("pending-work-checkpointer")
+	close(ready)
+
+	logger.Info("started")
+	defer logger.Info("finished")
+
+	for {
+		select {
+		case <-c.ticker.C():
+			c.save(logger)
+
+		case <-signals:
+			c.ticker.Stop()
+			c.save(logger)
+			return nil
+		}
+	}
+}
+
+func (c *Checkpointer) save(logger lager.Logger) {
+	snapshot := c.source.Snapshot()
+	snapshot.SavedAt = c.clock.Now()
+
+	err := c.store.Save(snapshot)
+	if err != nil {
+		logger.Error("failed-to-save-snapshot", err, lager.Data{"tasks": len(snapshot.Tasks), "lrps": len(snapshot.LRPs)})
+	}
+}
@@ -0,0 +1,111 @@
+package pendingwork_test
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/auctioneer/pendingwork"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+type fakeSource struct {
+	snapshot pendingwork.Snapshot
+}
+
+func (f *fakeSource) Snapshot() pendingwork.Snapshot {
+	return f.snapshot
+}
+
+type fakeStore struct {
+	lock     sync.Mutex
+	saved    []pendingwork.Snapshot
+	saveErr  error
+	snapshot pendingwork.Snapshot
+	loadErr  error
+}
+
+func (f *fakeStore) Save(snapshot pendingwork.Snapshot) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.saved = append(f.saved, snapshot)
+	return f.saveErr
+}
+
+func (f *fakeStore) Load() (pendingwork.Snapshot, error) {
+	return f.snapshot, f.loadErr
+}
+
+func (f *fakeStore) savedSnapshots() []pendingwork.Snapshot {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return append([]pendingwork.Snapshot{}, f.saved...)
+}
+
+var _ = Describe("Checkpointer", func() {
+	var (
+		logger    *lagertest.TestLogger
+		fakeClock *fakeclock.FakeClock
+		source    *fakeSource
+		store     *fakeStore
+		process   ifrit.Process
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		source = &fakeSource{snapshot: pendingwork.Snapshot{
+			Tasks: []pendingwork.Task{{
+				Request: auctioneer.NewTaskStartRequest(rep.NewTask("task-guid", "domain", rep.NewResource(10, 10, 10), rep.NewPlacementConstraint("preloaded:linux", nil, nil))),
+			}},
+		}}
+		store = &fakeStore{}
+	})
+
+	JustBeforeEach(func() {
+		checkpointer := pendingwork.NewCheckpointer(logger, fakeClock, fakeClock.NewTicker(time.Second), source, store)
+		process = ginkgomon.Invoke(checkpointer)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+	})
+
+	It("saves the pending work every tick", func() {
+		Consistently(store.savedSnapshots).Should(BeEmpty())
+
+		fakeClock.WaitForWatcherAndIncrement(time.Second)
+		Eventually(store.savedSnapshots).Should(HaveLen(1))
+
+		saved := store.savedSnapshots()[0]
+		Expect(saved.SavedAt).To(Equal(fakeClock.Now()))
+		Expect(saved.Tasks).To(Equal(source.snapshot.Tasks))
+	})
+
+	It("saves the pending work once more when signalled", func() {
+		process.Signal(os.Interrupt)
+		Eventually(store.savedSnapshots).Should(HaveLen(1))
+	})
+
+	Context("when the store fails", func() {
+		BeforeEach(func() {
+			store.saveErr = errors.New("boom")
+		})
+
+		It("logs and keeps running", func() {
+			fakeClock.WaitForWatcherAndIncrement(time.Second)
+			Eventually(logger).Should(gbytes.Say("test.pending-work-checkpointer.failed-to-save-snapshot"))
+			Consistently(process.Wait()).ShouldNot(Receive())
+		})
+	})
+})
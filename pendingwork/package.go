@@ -0,0 +1 @@
+package pendingwork // import "code.cloudfoundry.org/auctioneer/pendingwork"
@@ -0,0 +1,13 @@
+package pendingwork_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestPendingWork(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pending Work Suite")
+}
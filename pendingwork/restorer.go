@@ -0,0 +1,176 @@
+package pendingwork
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+)
+
+// Tracker is told about restored work so that it is tracked, and checkpointed,
+// like work that was submitted to this auctioneer. Restore returns the work it
+// was not already tracking.
+type Tracker interface {
+	Restore(snapshot Snapshot) Snapshot
+}
+
+// DomainLimiter rate limits the work scheduled for each domain, as it does for
+// the work submitted to the handlers. domainlimit.Limiter implements it.
+type DomainLimiter interface {
+	Take(domain string, n int) (bool, time.Duration)
+}
+
+// Restorer loads the snapshot saved by the previous leader and schedules the
+// work in it for auction. It runs once the lock has been acquired and becomes
+// ready only after the work has been scheduled, so that it is restored before
+// the server starts accepting new work. Work submitted longer than maxAge ago
+// is dropped, as the BBS will have resubmitted it by then.
+//
+// Restored work is counted against the domain limits, if any. Work that a
+// domain cannot take yet is scheduled once the limiter allows it, after the
+// restorer has become ready.
+type Restorer struct {
+	logger  lager.Logger
+	clock   clock.Clock
+	store   Store
+	tracker Tracker
+	runner  auctiontypes.AuctionRunner
+	limiter DomainLimiter
+	maxAge  time.Duration
+}
+
+func NewRestorer(logger lager.Logger, clock clock.Clock, store Store, tracker Tracker, runner auctiontypes.AuctionRunner, limiter DomainLimiter, maxAge time.Duration) *Restorer {
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+
+	return &Restorer{
+		logger:  logger,
+		clock:   clock,
+		store:   store,
+		tracker: tracker,
+		runner:  runner,
+		limiter: limiter,
+		maxAge:  maxAge,
+	}
+}
+
+func (r *Restorer) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := r.logger.Session("pending-work-restorer")
+	throttled, wait := r.schedule(r.restore(logger))
+	close(ready)
+
+	for len(throttled.Tasks) > 0 || len(throttled.LRPs) > 0 {
+		logger.Info("throttled", lager.Data{
+			"tasks":    len(throttled.Tasks),
+			"lrps":     len(throttled.LRPs),
+			"retry-in": wait.String(),
+		})
+
+		timer := r.clock.NewTimer(wait)
+		select {
+		case <-timer.C():
+		case <-signals:
+			timer.Stop()
+			logger.Info("dropped-throttled-work", lager.Data{
+				"tasks": len(throttled.Tasks),
+				"lrps":  len(throttled.LRPs),
+			})
+			return nil
+		}
+
+		throttled, wait = r.schedule(throttled)
+	}
+
+	<-signals
+	return nil
+}
+
+func (r *Restorer) restore(logger lager.Logger) Snapshot {
+	snapshot, err := r.store.Load()
+	if err != nil {
+		logger.Error("failed-to-load-snapshot", err)
+		return Snapshot{}
+	}
+
+	unexpired := r.dropExpired(snapshot)
+	restored := r.tracker.Restore(unexpired)
+
+	logger.Info("restored", lager.Data{
+		"saved-at":         snapshot.SavedAt,
+		"tasks":            len(restored.Tasks),
+		"lrps":             len(restored.LRPs),
+		"expired-tasks":    len(snapshot.Tasks) - len(unexpired.Tasks),
+		"expired-lrps":     len(snapshot.LRPs) - len(unexpired.LRPs),
+		"duplicated-tasks": len(unexpired.Tasks) - len(restored.Tasks),
+		"duplicated-lrps":  len(unexpired.LRPs) - len(restored.LRPs),
+	})
+
+	return restored
+}
+
+// schedule schedules the work the domain limits allow now and returns the
+// rest, along with how long to wait before trying it again.
+func (r *Restorer) schedule(work Snapshot) (Snapshot, time.Duration) {
+	throttled := Snapshot{SavedAt: work.SavedAt}
+	var wait time.Duration
+
+	take := func(domain string, n int) bool {
+		if r.limiter == nil {
+			return true
+		}
+		ok, retryAfter := r.limiter.Take(domain, n)
+		if !ok && (wait == 0 || retryAfter < wait) {
+			wait = retryAfter
+		}
+		return ok
+	}
+
+	tasks := []auctioneer.TaskStartRequest{}
+	for i := range work.Tasks {
+		if take(work.Tasks[i].Request.Domain, 1) {
+			tasks = append(tasks, work.Tasks[i].Request)
+		} else {
+			throttled.Tasks = append(throttled.Tasks, work.Tasks[i])
+		}
+	}
+
+	lrps := []auctioneer.LRPStartRequest{}
+	for i := range work.LRPs {
+		if take(work.LRPs[i].Request.Domain, len(work.LRPs[i].Request.Indices)) {
+			lrps = append(lrps, work.LRPs[i].Request)
+		} else {
+			throttled.LRPs = append(throttled.LRPs, work.LRPs[i])
+		}
+	}
+
+	if len(tasks) > 0 {
+		r.runner.ScheduleTasksForAuctions(tasks)
+	}
+	if len(lrps) > 0 {
+		r.runner.ScheduleLRPsForAuctions(lrps)
+	}
+
+	return throttled, wait
+}
+
+func (r *Restorer) dropExpired(snapshot Snapshot) Snapshot {
+	now := r.clock.Now()
+	unexpired := Snapshot{SavedAt: snapshot.SavedAt}
+
+	for _, task := range snapshot.Tasks {
+		if now.Sub(task.SubmittedAt) <= r.maxAge {
+			unexpired.Tasks = append(unexpired.Tasks, task)
+		}
+	}
+	for _, lrp := range snapshot.LRPs {
+		if now.Sub(lrp.SubmittedAt) <= r.maxAge {
+			unexpired.LRPs = append(unexpired.LRPs, lrp)
+		}
+	}
+
+	return unexpired
+}
@@ -0,0 +1,179 @@
+package pendingwork_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	fake_auction_runner "code.cloudfoundry.org/auction/auctiontypes/fakes"
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/auctioneer/pendingwork"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+type fakeTracker struct {
+	tracked  map[string]bool
+	restored []pendingwork.Snapshot
+}
+
+func (f *fakeTracker) Restore(snapshot pendingwork.Snapshot) pendingwork.Snapshot {
+	f.restored = append(f.restored, snapshot)
+
+	added := pendingwork.Snapshot{SavedAt: snapshot.SavedAt}
+	for _, task := range snapshot.Tasks {
+		if !f.tracked[task.Request.TaskGuid] {
+			f.tracked[task.Request.TaskGuid] = true
+			added.Tasks = append(added.Tasks, task)
+		}
+	}
+	added.LRPs = snapshot.LRPs
+	return added
+}
+
+type fakeDomainLimiter struct {
+	mutex   sync.Mutex
+	allowed map[string]bool
+	taken   []map[string]int
+}
+
+func (f *fakeDomainLimiter) Take(domain string, n int) (bool, time.Duration) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.taken = append(f.taken, map[string]int{domain: n})
+	if !f.allowed[domain] {
+		return false, time.Second
+	}
+	return true, 0
+}
+
+func (f *fakeDomainLimiter) allow(domain string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.allowed[domain] = true
+}
+
+var _ = Describe("Restorer", func() {
+	var (
+		logger    *lagertest.TestLogger
+		fakeClock *fakeclock.FakeClock
+		store     *fakeStore
+		tracker   *fakeTracker
+		runner    *fake_auction_runner.FakeAuctionRunner
+		limiter   pendingwork.DomainLimiter
+		process   ifrit.Process
+
+		freshTask, duplicateTask, expiredTask pendingwork.Task
+		freshLRP, expiredLRP                  pendingwork.LRP
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		runner = new(fake_auction_runner.FakeAuctionRunner)
+		tracker = &fakeTracker{tracked: map[string]bool{"duplicate-task": true}}
+		limiter = nil
+
+		resource := rep.NewResource(10, 10, 10)
+		pc := rep.NewPlacementConstraint("preloaded:linux", nil, nil)
+		now := fakeClock.Now()
+
+		freshTask = pendingwork.Task{Request: auctioneer.NewTaskStartRequest(rep.NewTask("fresh-task", "domain", resource, pc)), SubmittedAt: now.Add(-time.Second)}
+		duplicateTask = pendingwork.Task{Request: auctioneer.NewTaskStartRequest(rep.NewTask("duplicate-task", "domain", resource, pc)), SubmittedAt: now.Add(-time.Second)}
+		expiredTask = pendingwork.Task{Request: auctioneer.NewTaskStartRequest(rep.NewTask("expired-task", "domain", resource, pc)), SubmittedAt: now.Add(-2 * time.Minute)}
+		freshLRP = pendingwork.LRP{Request: auctioneer.NewLRPStartRequest("fresh-lrp", "domain", []int{0}, resource, pc), SubmittedAt: now.Add(-time.Second)}
+		expiredLRP = pendingwork.LRP{Request: auctioneer.NewLRPStartRequest("expired-lrp", "domain", []int{0}, resource, pc), SubmittedAt: now.Add(-2 * time.Minute)}
+
+		store = &fakeStore{snapshot: pendingwork.Snapshot{
+			SavedAt: now.Add(-time.Second),
+			Tasks:   []pendingwork.Task{freshTask, duplicateTask, expiredTask},
+			LRPs:    []pendingwork.LRP{freshLRP, expiredLRP},
+		}}
+	})
+
+	JustBeforeEach(func() {
+		restorer := pendingwork.NewRestorer(logger, fakeClock, store, tracker, runner, limiter, time.Minute)
+		process = ginkgomon.Invoke(restorer)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+	})
+
+	It("hands the unexpired work to the tracker", func() {
+		Expect(tracker.restored).To(HaveLen(1))
+		Expect(tracker.restored[0].Tasks).To(Equal([]pendingwork.Task{freshTask, duplicateTask}))
+		Expect(tracker.restored[0].LRPs).To(Equal([]pendingwork.LRP{freshLRP}))
+	})
+
+	It("schedules the work the tracker was not already tracking before becoming ready", func() {
+		Expect(runner.ScheduleTasksForAuctionsCallCount()).To(Equal(1))
+		Expect(runner.ScheduleTasksForAuctionsArgsForCall(0)).To(Equal([]auctioneer.TaskStartRequest{freshTask.Request}))
+
+		Expect(runner.ScheduleLRPsForAuctionsCallCount()).To(Equal(1))
+		Expect(runner.ScheduleLRPsForAuctionsArgsForCall(0)).To(Equal([]auctioneer.LRPStartRequest{freshLRP.Request}))
+
+		Expect(logger).To(gbytes.Say("test.pending-work-restorer.restored"))
+	})
+
+	Context("when a domain limiter is configured", func() {
+		var domainLimiter *fakeDomainLimiter
+
+		BeforeEach(func() {
+			domainLimiter = &fakeDomainLimiter{allowed: map[string]bool{}}
+			limiter = domainLimiter
+		})
+
+		It("counts the restored work against the domain limits", func() {
+			Expect(domainLimiter.taken).To(ConsistOf(
+				map[string]int{"domain": 1},
+				map[string]int{"domain": 1},
+			))
+			Expect(runner.ScheduleTasksForAuctionsCallCount()).To(Equal(0))
+			Expect(runner.ScheduleLRPsForAuctionsCallCount()).To(Equal(0))
+		})
+
+		It("schedules throttled work once the limiter allows it", func() {
+			Eventually(logger).Should(gbytes.Say("test.pending-work-restorer.throttled"))
+			domainLimiter.allow("domain")
+
+			Eventually(fakeClock.WatcherCount).Should(Equal(1))
+			fakeClock.Increment(time.Second)
+
+			Eventually(runner.ScheduleTasksForAuctionsCallCount).Should(Equal(1))
+			Expect(runner.ScheduleTasksForAuctionsArgsForCall(0)).To(Equal([]auctioneer.TaskStartRequest{freshTask.Request}))
+			Eventually(runner.ScheduleLRPsForAuctionsCallCount).Should(Equal(1))
+			Expect(runner.ScheduleLRPsForAuctionsArgsForCall(0)).To(Equal([]auctioneer.LRPStartRequest{freshLRP.Request}))
+		})
+	})
+
+	Context("when there is nothing to restore", func() {
+		BeforeEach(func() {
+			store.snapshot = pendingwork.Snapshot{}
+		})
+
+		It("does not schedule anything", func() {
+			Expect(runner.ScheduleTasksForAuctionsCallCount()).To(Equal(0))
+			Expect(runner.ScheduleLRPsForAuctionsCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the snapshot cannot be loaded", func() {
+		BeforeEach(func() {
+			store.loadErr = errors.New("boom")
+		})
+
+		It("logs and starts anyway", func() {
+			Expect(logger).To(gbytes.Say("test.pending-work-restorer.failed-to-load-snapshot"))
+			Expect(runner.ScheduleTasksForAuctionsCallCount()).To(Equal(0))
+		})
+	})
+})
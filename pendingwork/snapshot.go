@@ -0,0 +1,100 @@
+package pendingwork
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer"
+)
+
+const (
+	DefaultCheckpointInterval = 5 * time.Second
+
+	// DefaultMaxAge bounds how old restored work may be. Older work will
+	// already have been resubmitted by BBS convergence.
+	DefaultMaxAge = 5 * time.Minute
+)
+
+// Snapshot is a checkpoint of the work that the leader had accepted but not
+// yet auctioned. Every LRP entry holds a single instance index.
+type Snapshot struct {
+	SavedAt time.Time `json:"saved_at"`
+	Tasks   []Task    `json:"tasks"`
+	LRPs    []LRP     `json:"lrps"`
+}
+
+type Task struct {
+	Request     auctioneer.TaskStartRequest `json:"request"`
+	SubmittedAt time.Time                   `json:"submitted_at"`
+}
+
+type LRP struct {
+	Request     auctioneer.LRPStartRequest `json:"request"`
+	SubmittedAt time.Time                  `json:"submitted_at"`
+}
+
+// Store persists the most recent snapshot. Load returns an empty snapshot if
+// none has been saved yet.
+type Store interface {
+	Save(snapshot Snapshot) error
+	Load() (Snapshot, error)
+}
+
+// FileStore keeps the snapshot as JSON in a single file. Saves write a
+// temporary file next to it and rename it into place, so a reader never sees
+// a partially written snapshot.
+type FileStore struct {
+	path string
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (s *FileStore) Save(snapshot Snapshot) error {
+	payload, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(payload); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func (s *FileStore) Load() (Snapshot, error) {
+	payload, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return Snapshot{}, nil
+	}
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	snapshot := Snapshot{}
+	err = json.Unmarshal(payload, &snapshot)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	return snapshot, nil
+}
@@ -0,0 +1,83 @@
+package pendingwork_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/auctioneer/pendingwork"
+	"code.cloudfoundry.org/rep"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileStore", func() {
+	var (
+		dir   string
+		path  string
+		store *pendingwork.FileStore
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "pending-work")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(dir, "snapshot.json")
+		store = pendingwork.NewFileStore(path)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("loads an empty snapshot when nothing has been saved", func() {
+		snapshot, err := store.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapshot).To(Equal(pendingwork.Snapshot{}))
+	})
+
+	It("loads the snapshot that was last saved", func() {
+		resource := rep.NewResource(10, 10, 10)
+		pc := rep.NewPlacementConstraint("preloaded:linux", nil, nil)
+		savedAt := time.Unix(1000, 0).UTC()
+
+		Expect(store.Save(pendingwork.Snapshot{SavedAt: savedAt.Add(-time.Minute)})).To(Succeed())
+
+		snapshot := pendingwork.Snapshot{
+			SavedAt: savedAt,
+			Tasks: []pendingwork.Task{{
+				Request:     auctioneer.NewTaskStartRequest(rep.NewTask("task-guid", "domain", resource, pc)),
+				SubmittedAt: savedAt.Add(-time.Second),
+			}},
+			LRPs: []pendingwork.LRP{{
+				Request:     auctioneer.NewLRPStartRequest("process-guid", "domain", []int{1}, resource, pc),
+				SubmittedAt: savedAt.Add(-2 * time.Second),
+			}},
+		}
+		Expect(store.Save(snapshot)).To(Succeed())
+
+		Expect(pendingwork.NewFileStore(path).Load()).To(Equal(snapshot))
+	})
+
+	It("does not leave temporary files behind", func() {
+		Expect(store.Save(pendingwork.Snapshot{})).To(Succeed())
+
+		entries, err := ioutil.ReadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+	})
+
+	Context("when the snapshot is corrupt", func() {
+		BeforeEach(func() {
+			Expect(ioutil.WriteFile(path, []byte("{"), 0644)).To(Succeed())
+		})
+
+		It("returns an error", func() {
+			_, err := store.Load()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
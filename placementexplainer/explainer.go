@@ -0,0 +1,93 @@
+package placementexplainer
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/auctioneer/auctionrunnerdelegate"
+	"code.cloudfoundry.org/lager"
+)
+
+const DefaultMaxExplanations = 1000
+
+// ExplanationObserver is notified of every completed auction together with
+// the explanations of its failed work items.
+type ExplanationObserver interface {
+	AuctionExplained(auction auctionrunnerdelegate.CompletedAuction, explanations []Explanation)
+}
+
+// Explainer logs an explanation for every work item that failed to be placed
+// and keeps the most recent ones so they can be queried. It hands the
+// explanations on to its observers, which would otherwise have to compute
+// them again.
+type Explainer struct {
+	logger          lager.Logger
+	maxExplanations int
+	observers       []ExplanationObserver
+
+	lock         sync.RWMutex
+	explanations []Explanation
+}
+
+func New(logger lager.Logger, maxExplanations int, observers ...ExplanationObserver) *Explainer {
+	if maxExplanations <= 0 {
+		maxExplanations = DefaultMaxExplanations
+	}
+
+	return &Explainer{
+		logger:          logger.Session("placement-explainer"),
+		maxExplanations: maxExplanations,
+		observers:       observers,
+	}
+}
+
+func (e *Explainer) AuctionCompleted(auction auctionrunnerdelegate.CompletedAuction) {
+	explanations := Explain(auction)
+	e.retain(explanations)
+
+	for _, observer := range e.observers {
+		observer.AuctionExplained(auction, explanations)
+	}
+}
+
+func (e *Explainer) retain(explanations []Explanation) {
+	if len(explanations) == 0 {
+		return
+	}
+
+	for i := range explanations {
+		explanation := &explanations[i]
+		e.logger.Info("placement-failed", lager.Data{
+			"work":            explanation.Identifier(),
+			"domain":          explanation.Domain,
+			"placement-error": explanation.PlacementError,
+			"cell-rejections": explanation.CellRejections,
+			"eligible-cells":  explanation.EligibleCells,
+		})
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.explanations = append(e.explanations, explanations...)
+	if overflow := len(e.explanations) - e.maxExplanations; overflow > 0 {
+		e.explanations = append([]Explanation{}, e.explanations[overflow:]...)
+	}
+}
+
+// Explanations returns the retained explanations, most recent first. When
+// identifier is not empty only explanations for that task guid, process guid
+// or LRP instance (<process-guid>.<index>) are returned.
+func (e *Explainer) Explanations(identifier string) []Explanation {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	explanations := []Explanation{}
+	for i := len(e.explanations) - 1; i >= 0; i-- {
+		explanation := e.explanations[i]
+		if identifier != "" && explanation.Identifier() != identifier && explanation.ProcessGuid != identifier {
+			continue
+		}
+		explanations = append(explanations, explanation)
+	}
+	return explanations
+}
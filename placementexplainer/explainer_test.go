@@ -0,0 +1,113 @@
+package placementexplainer_test
+
+import (
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auctioneer/auctionrunnerdelegate"
+	"code.cloudfoundry.org/auctioneer/placementexplainer"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Explainer", func() {
+	var (
+		logger    *lagertest.TestLogger
+		explainer *placementexplainer.Explainer
+	)
+
+	failedTaskAuction := func(taskGuids ...string) auctionrunnerdelegate.CompletedAuction {
+		resource := rep.NewResource(10, 10, 10)
+		pc := rep.NewPlacementConstraint("preloaded:linux", []string{}, []string{})
+
+		auction := auctionrunnerdelegate.CompletedAuction{}
+		for _, guid := range taskGuids {
+			auction.Results.FailedTasks = append(auction.Results.FailedTasks, auctiontypes.TaskAuction{
+				Task:          rep.NewTask(guid, "domain", resource, pc),
+				AuctionRecord: auctiontypes.AuctionRecord{PlacementError: "insufficient resources"},
+			})
+		}
+		return auction
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		explainer = placementexplainer.New(logger, 3)
+	})
+
+	It("logs an explanation for every failed work item", func() {
+		explainer.AuctionCompleted(failedTaskAuction("task-1"))
+		Expect(logger).To(gbytes.Say(`test.placement-explainer.placement-failed.*"work":"task-1"`))
+	})
+
+	It("returns the most recent explanations first", func() {
+		explainer.AuctionCompleted(failedTaskAuction("task-1", "task-2"))
+		explainer.AuctionCompleted(failedTaskAuction("task-3"))
+
+		explanations := explainer.Explanations("")
+		Expect(explanations).To(HaveLen(3))
+		Expect(explanations[0].TaskGuid).To(Equal("task-3"))
+		Expect(explanations[2].TaskGuid).To(Equal("task-1"))
+	})
+
+	It("only keeps the configured number of explanations", func() {
+		explainer.AuctionCompleted(failedTaskAuction("task-1", "task-2", "task-3"))
+		explainer.AuctionCompleted(failedTaskAuction("task-4"))
+
+		explanations := explainer.Explanations("")
+		Expect(explanations).To(HaveLen(3))
+		Expect(explanations[2].TaskGuid).To(Equal("task-2"))
+	})
+
+	It("filters explanations by identifier", func() {
+		auction := failedTaskAuction("task-1")
+		auction.Results.FailedLRPs = []auctiontypes.LRPAuction{{
+			LRP: rep.NewLRP("", models.NewActualLRPKey("process-guid", 1, "domain"), rep.NewResource(1, 1, 1), rep.NewPlacementConstraint("preloaded:linux", nil, nil)),
+		}}
+		explainer.AuctionCompleted(auction)
+
+		Expect(explainer.Explanations("task-1")).To(HaveLen(1))
+		Expect(explainer.Explanations("process-guid")).To(HaveLen(1))
+		Expect(explainer.Explanations("process-guid.1")).To(HaveLen(1))
+		Expect(explainer.Explanations("process-guid.2")).To(BeEmpty())
+	})
+
+	Context("when observers are registered", func() {
+		var observer *fakeExplanationObserver
+
+		BeforeEach(func() {
+			observer = &fakeExplanationObserver{}
+			explainer = placementexplainer.New(logger, 3, observer)
+		})
+
+		It("hands them the explanations of every completed auction", func() {
+			auction := failedTaskAuction("task-1")
+			explainer.AuctionCompleted(auction)
+
+			Expect(observer.auctions).To(Equal([]auctionrunnerdelegate.CompletedAuction{auction}))
+			Expect(observer.explanations).To(HaveLen(1))
+			Expect(observer.explanations[0]).To(HaveLen(1))
+			Expect(observer.explanations[0][0].TaskGuid).To(Equal("task-1"))
+		})
+
+		It("notifies them of auctions without failures", func() {
+			explainer.AuctionCompleted(auctionrunnerdelegate.CompletedAuction{})
+
+			Expect(observer.auctions).To(HaveLen(1))
+			Expect(observer.explanations[0]).To(BeEmpty())
+		})
+	})
+})
+
+type fakeExplanationObserver struct {
+	auctions     []auctionrunnerdelegate.CompletedAuction
+	explanations [][]placementexplainer.Explanation
+}
+
+func (o *fakeExplanationObserver) AuctionExplained(auction auctionrunnerdelegate.CompletedAuction, explanations []placementexplainer.Explanation) {
+	o.auctions = append(o.auctions, auction)
+	o.explanations = append(o.explanations, explanations)
+}
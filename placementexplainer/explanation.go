@@ -0,0 +1,144 @@
+package placementexplainer
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/auctionrunnerdelegate"
+	"code.cloudfoundry.org/rep"
+)
+
+// Reasons a cell was not a candidate for a work item. Cells do not report
+// their pid capacity, so MaxPids is not taken into account.
+const (
+	ReasonEvacuating             = "evacuating"
+	ReasonRootFSMismatch         = "rootfs mismatch"
+	ReasonPlacementTagMismatch   = "placement tag mismatch"
+	ReasonVolumeDriverMissing    = "volume driver missing"
+	ReasonInsufficientMemory     = "insufficient memory"
+	ReasonInsufficientDisk       = "insufficient disk"
+	ReasonInsufficientContainers = "insufficient containers"
+)
+
+const (
+	TaskWorkType = "task"
+	LRPWorkType  = "lrp"
+)
+
+// Explanation records why a work item could not be placed: for every cell
+// whose state was fetched during the auction, the constraints that ruled it
+// out. Cells that satisfied every constraint at the start of the auction are
+// listed as eligible; they were filled by other work in the same auction or
+// excluded by the auction runner itself.
+type Explanation struct {
+	Timestamp      time.Time           `json:"timestamp"`
+	Type           string              `json:"type"`
+	TaskGuid       string              `json:"task_guid,omitempty"`
+	ProcessGuid    string              `json:"process_guid,omitempty"`
+	Index          int32               `json:"index"`
+	Domain         string              `json:"domain"`
+	PlacementError string              `json:"placement_error"`
+	CellCount      int                 `json:"cell_count"`
+	CellRejections map[string][]string `json:"cell_rejections"`
+	EligibleCells  []string            `json:"eligible_cells"`
+}
+
+// Identifier matches the identifiers used by the rep for the same work.
+func (e *Explanation) Identifier() string {
+	if e.Type == TaskWorkType {
+		return e.TaskGuid
+	}
+	return fmt.Sprintf("%s.%d", e.ProcessGuid, e.Index)
+}
+
+// Explain returns an explanation for every failed work item of the auction.
+func Explain(auction auctionrunnerdelegate.CompletedAuction) []Explanation {
+	results := auction.Results
+	explanations := make([]Explanation, 0, len(results.FailedLRPs)+len(results.FailedTasks))
+
+	for i := range results.FailedLRPs {
+		lrp := &results.FailedLRPs[i]
+		explanation := explain(auction, lrp.PlacementConstraint, lrp.Resource)
+		explanation.Type = LRPWorkType
+		explanation.ProcessGuid = lrp.ProcessGuid
+		explanation.Index = lrp.Index
+		explanation.Domain = lrp.Domain
+		explanation.PlacementError = lrp.PlacementError
+		explanations = append(explanations, explanation)
+	}
+
+	for i := range results.FailedTasks {
+		task := &results.FailedTasks[i]
+		explanation := explain(auction, task.PlacementConstraint, task.Resource)
+		explanation.Type = TaskWorkType
+		explanation.TaskGuid = task.TaskGuid
+		explanation.Domain = task.Domain
+		explanation.PlacementError = task.PlacementError
+		explanations = append(explanations, explanation)
+	}
+
+	return explanations
+}
+
+// RejectionReasons returns the constraints that rule the cell out for work
+// with the given placement constraint and resources.
+func RejectionReasons(state *rep.CellState, pc rep.PlacementConstraint, resource rep.Resource) []string {
+	reasons := ConstraintRejectionReasons(state, pc)
+
+	if state.AvailableResources.MemoryMB < resource.MemoryMB {
+		reasons = append(reasons, ReasonInsufficientMemory)
+	}
+	if state.AvailableResources.DiskMB < resource.DiskMB {
+		reasons = append(reasons, ReasonInsufficientDisk)
+	}
+	if state.AvailableResources.Containers < 1 {
+		reasons = append(reasons, ReasonInsufficientContainers)
+	}
+
+	return reasons
+}
+
+// ConstraintRejectionReasons is RejectionReasons without the resource checks:
+// the reasons the auction runner does not consider the cell a candidate for
+// the work at all.
+func ConstraintRejectionReasons(state *rep.CellState, pc rep.PlacementConstraint) []string {
+	reasons := []string{}
+
+	if state.Evacuating {
+		reasons = append(reasons, ReasonEvacuating)
+	}
+	if !state.MatchRootFS(pc.RootFs) {
+		reasons = append(reasons, ReasonRootFSMismatch)
+	}
+	if !state.MatchPlacementTags(pc.PlacementTags) {
+		reasons = append(reasons, ReasonPlacementTagMismatch)
+	}
+	if !state.MatchVolumeDrivers(pc.VolumeDrivers) {
+		reasons = append(reasons, ReasonVolumeDriverMissing)
+	}
+
+	return reasons
+}
+
+func explain(auction auctionrunnerdelegate.CompletedAuction, pc rep.PlacementConstraint, resource rep.Resource) Explanation {
+	explanation := Explanation{
+		Timestamp:      auction.CompletedAt,
+		CellCount:      auction.CellCount,
+		CellRejections: map[string][]string{},
+		EligibleCells:  []string{},
+	}
+
+	for cellID := range auction.CellStates {
+		state := auction.CellStates[cellID]
+		reasons := RejectionReasons(&state, pc, resource)
+		if len(reasons) == 0 {
+			explanation.EligibleCells = append(explanation.EligibleCells, cellID)
+			continue
+		}
+		explanation.CellRejections[cellID] = reasons
+	}
+
+	sort.Strings(explanation.EligibleCells)
+	return explanation
+}
@@ -0,0 +1,122 @@
+package placementexplainer_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auctioneer/auctionrunnerdelegate"
+	"code.cloudfoundry.org/auctioneer/placementexplainer"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/rep"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Explanation", func() {
+	var (
+		state    rep.CellState
+		pc       rep.PlacementConstraint
+		resource rep.Resource
+	)
+
+	BeforeEach(func() {
+		state = rep.CellState{
+			RootFSProviders:    rep.RootFSProviders{"preloaded": rep.NewFixedSetRootFSProvider("linux")},
+			AvailableResources: rep.NewResources(1024, 2048, 10),
+			TotalResources:     rep.NewResources(1024, 2048, 10),
+			PlacementTags:      []string{"segment-a"},
+			VolumeDrivers:      []string{"nfs"},
+		}
+		pc = rep.NewPlacementConstraint("preloaded:linux", []string{"segment-a"}, []string{"nfs"})
+		resource = rep.NewResource(512, 1024, 100)
+	})
+
+	Describe("RejectionReasons", func() {
+		It("returns no reasons when the cell satisfies every constraint", func() {
+			Expect(placementexplainer.RejectionReasons(&state, pc, resource)).To(BeEmpty())
+		})
+
+		It("reports a rootfs mismatch", func() {
+			pc.RootFs = "preloaded:windows"
+			Expect(placementexplainer.RejectionReasons(&state, pc, resource)).To(ConsistOf(placementexplainer.ReasonRootFSMismatch))
+		})
+
+		It("reports a placement tag mismatch", func() {
+			pc.PlacementTags = []string{"segment-b"}
+			Expect(placementexplainer.RejectionReasons(&state, pc, resource)).To(ConsistOf(placementexplainer.ReasonPlacementTagMismatch))
+		})
+
+		It("reports a missing volume driver", func() {
+			pc.VolumeDrivers = []string{"smb"}
+			Expect(placementexplainer.RejectionReasons(&state, pc, resource)).To(ConsistOf(placementexplainer.ReasonVolumeDriverMissing))
+		})
+
+		It("reports every missing resource", func() {
+			state.AvailableResources = rep.NewResources(100, 100, 0)
+			Expect(placementexplainer.RejectionReasons(&state, pc, resource)).To(ConsistOf(
+				placementexplainer.ReasonInsufficientMemory,
+				placementexplainer.ReasonInsufficientDisk,
+				placementexplainer.ReasonInsufficientContainers,
+			))
+		})
+
+		It("reports evacuating cells", func() {
+			state.Evacuating = true
+			Expect(placementexplainer.RejectionReasons(&state, pc, resource)).To(ConsistOf(placementexplainer.ReasonEvacuating))
+		})
+	})
+
+	Describe("Explain", func() {
+		It("explains every failed work item against every cell", func() {
+			smallCell := state
+			smallCell.AvailableResources = rep.NewResources(256, 2048, 10)
+			windowsCell := state
+			windowsCell.RootFSProviders = rep.RootFSProviders{"preloaded": rep.NewFixedSetRootFSProvider("windows")}
+
+			completedAt := time.Unix(1000, 0)
+			auction := auctionrunnerdelegate.CompletedAuction{
+				Results: auctiontypes.AuctionResults{
+					FailedLRPs: []auctiontypes.LRPAuction{{
+						LRP:           rep.NewLRP("", models.NewActualLRPKey("process-guid", 3, "lrp-domain"), resource, pc),
+						AuctionRecord: auctiontypes.AuctionRecord{PlacementError: "insufficient resources"},
+					}},
+					FailedTasks: []auctiontypes.TaskAuction{{
+						Task:          rep.NewTask("task-guid", "task-domain", resource, pc),
+						AuctionRecord: auctiontypes.AuctionRecord{PlacementError: "insufficient resources"},
+					}},
+				},
+				CellCount: 4,
+				CellStates: map[string]rep.CellState{
+					"cell-a": state,
+					"cell-b": smallCell,
+					"cell-c": windowsCell,
+				},
+				CompletedAt: completedAt,
+			}
+
+			explanations := placementexplainer.Explain(auction)
+			Expect(explanations).To(HaveLen(2))
+
+			Expect(explanations[0]).To(Equal(placementexplainer.Explanation{
+				Timestamp:      completedAt,
+				Type:           placementexplainer.LRPWorkType,
+				ProcessGuid:    "process-guid",
+				Index:          3,
+				Domain:         "lrp-domain",
+				PlacementError: "insufficient resources",
+				CellCount:      4,
+				CellRejections: map[string][]string{
+					"cell-b": {placementexplainer.ReasonInsufficientMemory},
+					"cell-c": {placementexplainer.ReasonRootFSMismatch},
+				},
+				EligibleCells: []string{"cell-a"},
+			}))
+			Expect(explanations[0].Identifier()).To(Equal("process-guid.3"))
+
+			Expect(explanations[1].Type).To(Equal(placementexplainer.TaskWorkType))
+			Expect(explanations[1].TaskGuid).To(Equal("task-guid"))
+			Expect(explanations[1].Identifier()).To(Equal("task-guid"))
+		})
+	})
+})
@@ -0,0 +1,123 @@
+package auctioneer
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	SchemeHTTP  = "http"
+	SchemeHTTPS = "https"
+
+	APIVersionV1 = "v1"
+)
+
+// SupportedAPIVersions lists the versions of the auctioneer API served by
+// this version of the auctioneer.
+var SupportedAPIVersions = []string{APIVersionV1}
+
+// Presence is published by the auctioneer holding the lock so that clients
+// can find it. Auctioneers that predate the scheme, version, start time, zone
+// and API version fields publish only the ID and address; the helper methods
+// below fall back to what those auctioneers supported.
+type Presence struct {
+	AuctioneerID      string   `json:"auctioneer_id"`
+	AuctioneerAddress string   `json:"auctioneer_address"`
+	Scheme            string   `json:"scheme,omitempty"`
+	Version           string   `json:"version,omitempty"`
+	StartedAt         int64    `json:"started_at,omitempty"`
+	Zone              string   `json:"zone,omitempty"`
+	APIVersions       []string `json:"api_versions,omitempty"`
+}
+
+func NewPresence(id, address string) Presence {
+	return Presence{
+		AuctioneerID:      id,
+		AuctioneerAddress: address,
+	}
+}
+
+func (a Presence) Validate() error {
+	if a.AuctioneerID == "" {
+		return errors.New("auctioneer_id cannot be blank")
+	}
+
+	if a.AuctioneerAddress == "" {
+		return errors.New("auctioneer_address cannot be blank")
+	}
+
+	path, unix := unixSocketPath(a.AuctioneerAddress)
+	if unix && path == "" {
+		return errors.New("auctioneer_address must include the unix socket path")
+	}
+
+	if a.Scheme != "" {
+		if a.Scheme != SchemeHTTP && a.Scheme != SchemeHTTPS {
+			return fmt.Errorf("scheme must be %q or %q", SchemeHTTP, SchemeHTTPS)
+		}
+
+		// The scheme of an auctioneer on a unix socket is the protocol it
+		// serves on the socket, so the address cannot state it.
+		if !unix && !strings.HasPrefix(a.AuctioneerAddress, a.Scheme+"://") {
+			return fmt.Errorf("auctioneer_address does not use the %q scheme", a.Scheme)
+		}
+	}
+
+	if a.StartedAt < 0 {
+		return errors.New("started_at cannot be negative")
+	}
+
+	for _, version := range a.APIVersions {
+		if version == "" {
+			return errors.New("api_versions cannot contain a blank version")
+		}
+	}
+
+	return nil
+}
+
+// URLScheme returns the scheme clients should use to reach the auctioneer,
+// taken from the address when the presence does not state it. Auctioneers on
+// a unix socket that do not state it serve plain HTTP.
+func (a Presence) URLScheme() string {
+	if a.Scheme != "" {
+		return a.Scheme
+	}
+
+	u, err := url.Parse(a.AuctioneerAddress)
+	if err != nil || u.Scheme == "" || u.Scheme == SchemeUnix {
+		return SchemeHTTP
+	}
+
+	return u.Scheme
+}
+
+// RequiresTLS returns true if the auctioneer only serves HTTPS.
+func (a Presence) RequiresTLS() bool {
+	return a.URLScheme() == SchemeHTTPS
+}
+
+// IsUnixSocket returns true if the auctioneer listens on a unix socket, in
+// which case it can only be reached from its own host.
+func (a Presence) IsUnixSocket() bool {
+	_, unix := unixSocketPath(a.AuctioneerAddress)
+	return unix
+}
+
+// SupportsAPIVersion returns true if the auctioneer serves the given version
+// of the API. Auctioneers that do not list their API versions serve only v1.
+func (a Presence) SupportsAPIVersion(version string) bool {
+	if len(a.APIVersions) == 0 {
+		return version == APIVersionV1
+	}
+
+	for _, v := range a.APIVersions {
+		if v == version {
+			return true
+		}
+	}
+
+	return false
+}
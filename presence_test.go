@@ -0,0 +1,122 @@
+package auctioneer_test
+
+import (
+	"encoding/json"
+
+	"code.cloudfoundry.org/auctioneer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Presence", func() {
+	var presence auctioneer.Presence
+
+	BeforeEach(func() {
+		presence = auctioneer.Presence{
+			AuctioneerID:      "auctioneer-id",
+			AuctioneerAddress: "https://auctioneer.example.com:9016",
+			Scheme:            auctioneer.SchemeHTTPS,
+			Version:           "1.2.3",
+			StartedAt:         1000,
+			Zone:              "z1",
+			APIVersions:       []string{auctioneer.APIVersionV1},
+		}
+	})
+
+	Describe("Validate", func() {
+		It("accepts a complete presence", func() {
+			Expect(presence.Validate()).To(Succeed())
+		})
+
+		It("accepts a presence with only an ID and address", func() {
+			Expect(auctioneer.NewPresence("auctioneer-id", "http://auctioneer.example.com:9016").Validate()).To(Succeed())
+		})
+
+		It("rejects a blank ID", func() {
+			presence.AuctioneerID = ""
+			Expect(presence.Validate()).To(MatchError(ContainSubstring("auctioneer_id")))
+		})
+
+		It("rejects a blank address", func() {
+			presence.AuctioneerAddress = ""
+			Expect(presence.Validate()).To(MatchError(ContainSubstring("auctioneer_address")))
+		})
+
+		It("rejects an unknown scheme", func() {
+			presence.Scheme = "ftp"
+			Expect(presence.Validate()).To(MatchError(ContainSubstring("scheme")))
+		})
+
+		It("rejects a scheme that does not match the address", func() {
+			presence.Scheme = auctioneer.SchemeHTTP
+			Expect(presence.Validate()).To(MatchError(ContainSubstring("scheme")))
+		})
+
+		It("accepts a unix socket address with either scheme", func() {
+			presence.AuctioneerAddress = "unix:///var/vcap/data/auctioneer/auctioneer.sock"
+			Expect(presence.Validate()).To(Succeed())
+
+			presence.Scheme = auctioneer.SchemeHTTP
+			Expect(presence.Validate()).To(Succeed())
+		})
+
+		It("rejects a unix socket address without a path", func() {
+			presence.AuctioneerAddress = "unix://"
+			Expect(presence.Validate()).To(MatchError(ContainSubstring("unix socket path")))
+		})
+
+		It("rejects a negative start time", func() {
+			presence.StartedAt = -1
+			Expect(presence.Validate()).To(MatchError(ContainSubstring("started_at")))
+		})
+
+		It("rejects a blank API version", func() {
+			presence.APIVersions = []string{""}
+			Expect(presence.Validate()).To(MatchError(ContainSubstring("api_versions")))
+		})
+	})
+
+	Describe("URLScheme", func() {
+		It("returns the scheme", func() {
+			Expect(presence.URLScheme()).To(Equal("https"))
+			Expect(presence.RequiresTLS()).To(BeTrue())
+		})
+
+		It("falls back to the scheme of the address", func() {
+			presence = auctioneer.NewPresence("auctioneer-id", "https://auctioneer.example.com:9016")
+			Expect(presence.URLScheme()).To(Equal("https"))
+
+			presence = auctioneer.NewPresence("auctioneer-id", "http://auctioneer.example.com:9016")
+			Expect(presence.URLScheme()).To(Equal("http"))
+			Expect(presence.RequiresTLS()).To(BeFalse())
+		})
+
+		It("assumes plain HTTP for a unix socket address without a scheme", func() {
+			presence = auctioneer.NewPresence("auctioneer-id", "unix:///var/vcap/data/auctioneer/auctioneer.sock")
+			Expect(presence.URLScheme()).To(Equal("http"))
+			Expect(presence.IsUnixSocket()).To(BeTrue())
+		})
+	})
+
+	Describe("SupportsAPIVersion", func() {
+		It("checks the listed API versions", func() {
+			presence.APIVersions = []string{"v2"}
+			Expect(presence.SupportsAPIVersion("v2")).To(BeTrue())
+			Expect(presence.SupportsAPIVersion(auctioneer.APIVersionV1)).To(BeFalse())
+		})
+
+		It("assumes only v1 when no versions are listed", func() {
+			presence.APIVersions = nil
+			Expect(presence.SupportsAPIVersion(auctioneer.APIVersionV1)).To(BeTrue())
+			Expect(presence.SupportsAPIVersion("v2")).To(BeFalse())
+		})
+	})
+
+	It("is compatible with presences written by older auctioneers", func() {
+		decoded := auctioneer.Presence{}
+		Expect(json.Unmarshal([]byte(`{"auctioneer_id":"id","auctioneer_address":"http://a:1"}`), &decoded)).To(Succeed())
+		Expect(decoded).To(Equal(auctioneer.NewPresence("id", "http://a:1")))
+		Expect(decoded.Validate()).To(Succeed())
+	})
+})
@@ -0,0 +1,70 @@
+package quota
+
+import "code.cloudfoundry.org/rep"
+
+// Config caps the resources the work of each domain, and the work requiring
+// each placement tag, may have placed across the cells. Domains and tags that
+// are not listed are unlimited.
+type Config struct {
+	Domains       map[string]Quota `json:"domains,omitempty"`
+	PlacementTags map[string]Quota `json:"placement_tags,omitempty"`
+}
+
+// Quota is the most memory, disk and containers that may be placed. A zero
+// field means that resource is unlimited.
+type Quota struct {
+	MemoryMB   int64 `json:"memory_mb,omitempty"`
+	DiskMB     int64 `json:"disk_mb,omitempty"`
+	Containers int   `json:"containers,omitempty"`
+}
+
+// Resources is an amount of placed or requested work.
+type Resources struct {
+	MemoryMB   int64 `json:"memory_mb"`
+	DiskMB     int64 `json:"disk_mb"`
+	Containers int   `json:"containers"`
+}
+
+// Enabled reports whether any domain or placement tag has a quota.
+func (c Config) Enabled() bool {
+	return len(c.Domains) > 0 || len(c.PlacementTags) > 0
+}
+
+// exceeded returns the resources of which usage is over the quota.
+func (q Quota) exceeded(usage Resources) []string {
+	exceeded := []string{}
+	if q.MemoryMB > 0 && usage.MemoryMB > q.MemoryMB {
+		exceeded = append(exceeded, "memory")
+	}
+	if q.DiskMB > 0 && usage.DiskMB > q.DiskMB {
+		exceeded = append(exceeded, "disk")
+	}
+	if q.Containers > 0 && usage.Containers > q.Containers {
+		exceeded = append(exceeded, "containers")
+	}
+	return exceeded
+}
+
+func container(resource rep.Resource) Resources {
+	return Resources{
+		MemoryMB:   int64(resource.MemoryMB),
+		DiskMB:     int64(resource.DiskMB),
+		Containers: 1,
+	}
+}
+
+func (r Resources) plus(other Resources) Resources {
+	return Resources{
+		MemoryMB:   r.MemoryMB + other.MemoryMB,
+		DiskMB:     r.DiskMB + other.DiskMB,
+		Containers: r.Containers + other.Containers,
+	}
+}
+
+func (r Resources) minus(other Resources) Resources {
+	return Resources{
+		MemoryMB:   r.MemoryMB - other.MemoryMB,
+		DiskMB:     r.DiskMB - other.DiskMB,
+		Containers: r.Containers - other.Containers,
+	}
+}
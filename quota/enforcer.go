@@ -0,0 +1,324 @@
+package quota
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/auctioneer/auctionrunnerdelegate"
+	"code.cloudfoundry.org/auctioneer/worktracker"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// PlacementError is recorded with the BBS for starts refused because they
+// would exceed a quota.
+const PlacementError = "quota exceeded"
+
+// Usage is the resources placed for each domain and placement tag.
+type Usage struct {
+	Domains       map[string]Resources `json:"domains"`
+	PlacementTags map[string]Resources `json:"placement_tags"`
+}
+
+// Enforcer decides which starts fit within the configured quotas.
+//
+// Usage is totalled from the state each cell last reported, plus the starts
+// admitted since then that the cells have not yet reported. A cell keeps its
+// last reported usage while it is cordoned or fails to report, until it
+// leaves the cluster. Resources freed on the cells are therefore only counted
+// once the cell next reports.
+type Enforcer struct {
+	logger lager.Logger
+	clock  clock.Clock
+
+	lock                 sync.Mutex
+	config               Config
+	cells                map[string]Usage
+	placed               Usage
+	admitted             Usage
+	admissions           map[string]admission
+	lastAuctionStartedAt time.Time
+}
+
+type admission struct {
+	domain     string
+	tags       []string
+	resources  Resources
+	admittedAt time.Time
+}
+
+func New(logger lager.Logger, clock clock.Clock, config Config) *Enforcer {
+	return &Enforcer{
+		logger:     logger.Session("quota-enforcer"),
+		clock:      clock,
+		config:     config,
+		cells:      map[string]Usage{},
+		placed:     newUsage(),
+		admitted:   newUsage(),
+		admissions: map[string]admission{},
+	}
+}
+
+// SetConfig replaces the quotas checked by subsequent admissions.
+func (e *Enforcer) SetConfig(config Config) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.config = config
+	e.logger.Info("updated", lager.Data{"domains": len(config.Domains), "placement-tags": len(config.PlacementTags)})
+}
+
+func (e *Enforcer) Config() Config {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.config
+}
+
+// Usage returns the resources currently counted against each domain and
+// placement tag.
+func (e *Enforcer) Usage() Usage {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	usage := newUsage()
+	for domain, resources := range e.placed.Domains {
+		usage.Domains[domain] = resources.plus(e.admitted.Domains[domain])
+	}
+	for domain, resources := range e.admitted.Domains {
+		if _, ok := usage.Domains[domain]; !ok {
+			usage.Domains[domain] = resources
+		}
+	}
+	for tag, resources := range e.placed.PlacementTags {
+		usage.PlacementTags[tag] = resources.plus(e.admitted.PlacementTags[tag])
+	}
+	for tag, resources := range e.admitted.PlacementTags {
+		if _, ok := usage.PlacementTags[tag]; !ok {
+			usage.PlacementTags[tag] = resources
+		}
+	}
+	return usage
+}
+
+// AdmitTasks splits the tasks into those that fit within their quotas, which
+// are counted against them, and those that do not.
+func (e *Enforcer) AdmitTasks(tasks []auctioneer.TaskStartRequest) ([]auctioneer.TaskStartRequest, []auctioneer.TaskStartRequest) {
+	logger := e.logger.Session("admit-tasks")
+	admitted := make([]auctioneer.TaskStartRequest, 0, len(tasks))
+	refused := []auctioneer.TaskStartRequest{}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	now := e.clock.Now()
+	for _, task := range tasks {
+		exceeded := e.admit(task.TaskGuid, task.Domain, task.PlacementTags, container(task.Resource), now)
+		if exceeded != "" {
+			logger.Info("refused", lager.Data{"task-guid": task.TaskGuid, "domain": task.Domain, "exceeded": exceeded})
+			refused = append(refused, task)
+			continue
+		}
+		admitted = append(admitted, task)
+	}
+
+	return admitted, refused
+}
+
+// AdmitLRPs splits the LRP starts into those that fit within their quotas,
+// which are counted against them, and those that do not. A request whose
+// indices are only partly admitted appears in both, each with the indices
+// that apply.
+func (e *Enforcer) AdmitLRPs(lrps []auctioneer.LRPStartRequest) ([]auctioneer.LRPStartRequest, []auctioneer.LRPStartRequest) {
+	logger := e.logger.Session("admit-lrps")
+	admitted := make([]auctioneer.LRPStartRequest, 0, len(lrps))
+	refused := []auctioneer.LRPStartRequest{}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	now := e.clock.Now()
+	for _, lrp := range lrps {
+		admittedIndices := []int{}
+		refusedIndices := []int{}
+
+		for _, index := range lrp.Indices {
+			identifier := worktracker.LRPIdentifier(lrp.ProcessGuid, index)
+			exceeded := e.admit(identifier, lrp.Domain, lrp.PlacementTags, container(lrp.Resource), now)
+			if exceeded != "" {
+				logger.Info("refused", lager.Data{"process-guid": lrp.ProcessGuid, "index": index, "domain": lrp.Domain, "exceeded": exceeded})
+				refusedIndices = append(refusedIndices, index)
+				continue
+			}
+			admittedIndices = append(admittedIndices, index)
+		}
+
+		if len(admittedIndices) > 0 {
+			lrp := lrp
+			lrp.Indices = admittedIndices
+			admitted = append(admitted, lrp)
+		}
+		if len(refusedIndices) > 0 {
+			lrp := lrp
+			lrp.Indices = refusedIndices
+			refused = append(refused, lrp)
+		}
+	}
+
+	return admitted, refused
+}
+
+// AuctionCompleted replaces the placed resources of each cell that reported
+// a state during the auction, forgets cells that are no longer registered,
+// and stops counting admitted starts separately once the cells report them,
+// the auction fails to place them, or they have been through an auction
+// without appearing in the cell states that followed it.
+func (e *Enforcer) AuctionCompleted(auction auctionrunnerdelegate.CompletedAuction) {
+	registered := make(map[string]bool, len(auction.CellIDs))
+	for _, cellID := range auction.CellIDs {
+		registered[cellID] = true
+	}
+
+	identifiers := map[string]bool{}
+	reported := make(map[string]Usage, len(auction.CellStates))
+	for cellID, state := range auction.CellStates {
+		reported[cellID] = usageOf(state, identifiers)
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	for cellID := range e.cells {
+		if !registered[cellID] {
+			delete(e.cells, cellID)
+		}
+	}
+	for cellID, usage := range reported {
+		e.cells[cellID] = usage
+	}
+
+	e.placed = newUsage()
+	for _, usage := range e.cells {
+		for domain, resources := range usage.Domains {
+			e.placed.Domains[domain] = e.placed.Domains[domain].plus(resources)
+		}
+		for tag, resources := range usage.PlacementTags {
+			e.placed.PlacementTags[tag] = e.placed.PlacementTags[tag].plus(resources)
+		}
+	}
+
+	for i := range auction.Results.FailedTasks {
+		e.release(auction.Results.FailedTasks[i].TaskGuid)
+	}
+	for i := range auction.Results.FailedLRPs {
+		e.release(auction.Results.FailedLRPs[i].Identifier())
+	}
+
+	for identifier := range e.admissions {
+		if identifiers[identifier] {
+			e.release(identifier)
+		}
+	}
+
+	// Only a new auction ages out admissions.
+	if !auction.StartedAt.After(e.lastAuctionStartedAt) {
+		return
+	}
+
+	for identifier, admission := range e.admissions {
+		if admission.admittedAt.Before(e.lastAuctionStartedAt) {
+			e.release(identifier)
+		}
+	}
+	e.lastAuctionStartedAt = auction.StartedAt
+}
+
+// admit counts the resources against the quotas of the domain and placement
+// tags if they fit, returning a description of the quota exceeded if not.
+// Starts that are already counted are admitted again without being counted
+// twice.
+func (e *Enforcer) admit(identifier, domain string, tags []string, resources Resources, now time.Time) string {
+	if _, ok := e.admissions[identifier]; ok {
+		return ""
+	}
+
+	if quota, ok := e.config.Domains[domain]; ok {
+		usage := e.placed.Domains[domain].plus(e.admitted.Domains[domain]).plus(resources)
+		if exceeded := quota.exceeded(usage); len(exceeded) > 0 {
+			return fmt.Sprintf("domain %q %s", domain, strings.Join(exceeded, ", "))
+		}
+	}
+
+	for _, tag := range tags {
+		if quota, ok := e.config.PlacementTags[tag]; ok {
+			usage := e.placed.PlacementTags[tag].plus(e.admitted.PlacementTags[tag]).plus(resources)
+			if exceeded := quota.exceeded(usage); len(exceeded) > 0 {
+				return fmt.Sprintf("placement tag %q %s", tag, strings.Join(exceeded, ", "))
+			}
+		}
+	}
+
+	e.admissions[identifier] = admission{domain: domain, tags: tags, resources: resources, admittedAt: now}
+	e.admitted.add(domain, tags, resources)
+	return ""
+}
+
+func (e *Enforcer) release(identifier string) {
+	admission, ok := e.admissions[identifier]
+	if !ok {
+		return
+	}
+
+	delete(e.admissions, identifier)
+	e.admitted.subtract(admission.domain, admission.tags, admission.resources)
+}
+
+func newUsage() Usage {
+	return Usage{
+		Domains:       map[string]Resources{},
+		PlacementTags: map[string]Resources{},
+	}
+}
+
+// usageOf totals the resources of the LRPs and tasks on a cell, adding the
+// identifiers of that work to identifiers.
+func usageOf(state rep.CellState, identifiers map[string]bool) Usage {
+	usage := newUsage()
+
+	for i := range state.LRPs {
+		lrp := &state.LRPs[i]
+		usage.add(lrp.Domain, lrp.PlacementTags, container(lrp.Resource))
+		identifiers[lrp.Identifier()] = true
+	}
+	for i := range state.Tasks {
+		task := &state.Tasks[i]
+		usage.add(task.Domain, task.PlacementTags, container(task.Resource))
+		identifiers[task.Identifier()] = true
+	}
+
+	return usage
+}
+
+func (u Usage) add(domain string, tags []string, resources Resources) {
+	u.Domains[domain] = u.Domains[domain].plus(resources)
+	for _, tag := range tags {
+		u.PlacementTags[tag] = u.PlacementTags[tag].plus(resources)
+	}
+}
+
+func (u Usage) subtract(domain string, tags []string, resources Resources) {
+	u.Domains[domain] = u.Domains[domain].minus(resources)
+	if u.Domains[domain].Containers <= 0 {
+		delete(u.Domains, domain)
+	}
+	for _, tag := range tags {
+		u.PlacementTags[tag] = u.PlacementTags[tag].minus(resources)
+		if u.PlacementTags[tag].Containers <= 0 {
+			delete(u.PlacementTags, tag)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package quota_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/auctioneer/auctionrunnerdelegate"
+	"code.cloudfoundry.org/auctioneer/quota"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Enforcer", func() {
+	var (
+		logger    *lagertest.TestLogger
+		fakeClock *fakeclock.FakeClock
+		config    quota.Config
+		enforcer  *quota.Enforcer
+
+		resource rep.Resource
+		linux    rep.PlacementConstraint
+		gpu      rep.PlacementConstraint
+	)
+
+	task := func(guid, domain string, pc rep.PlacementConstraint) auctioneer.TaskStartRequest {
+		return auctioneer.NewTaskStartRequest(rep.NewTask(guid, domain, resource, pc))
+	}
+
+	guids := func(tasks []auctioneer.TaskStartRequest) []string {
+		result := []string{}
+		for _, task := range tasks {
+			result = append(result, task.TaskGuid)
+		}
+		return result
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		config = quota.Config{
+			Domains: map[string]quota.Quota{
+				"ci": {MemoryMB: 256, Containers: 3},
+			},
+			PlacementTags: map[string]quota.Quota{
+				"gpu": {DiskMB: 200},
+			},
+		}
+
+		resource = rep.NewResource(100, 100, 10)
+		linux = rep.NewPlacementConstraint("preloaded:linux", nil, nil)
+		gpu = rep.NewPlacementConstraint("preloaded:linux", []string{"gpu"}, nil)
+	})
+
+	JustBeforeEach(func() {
+		enforcer = quota.New(logger, fakeClock, config)
+	})
+
+	Describe("AdmitTasks", func() {
+		It("admits tasks until the domain quota would be exceeded", func() {
+			admitted, refused := enforcer.AdmitTasks([]auctioneer.TaskStartRequest{
+				task("task-1", "ci", linux),
+				task("task-2", "ci", linux),
+				task("task-3", "ci", linux),
+				task("task-4", "other", linux),
+			})
+
+			Expect(guids(admitted)).To(Equal([]string{"task-1", "task-2", "task-4"}))
+			Expect(guids(refused)).To(Equal([]string{"task-3"}))
+			Expect(logger).To(gbytes.Say(`quota-enforcer.admit-tasks.refused.*"domain":"ci","exceeded":"domain \\"ci\\" memory".*"task-guid":"task-3"`))
+		})
+
+		It("applies the quota of each placement tag", func() {
+			admitted, refused := enforcer.AdmitTasks([]auctioneer.TaskStartRequest{
+				task("task-1", "other", gpu),
+				task("task-2", "other", gpu),
+				task("task-3", "other", gpu),
+				task("task-4", "other", linux),
+			})
+
+			Expect(guids(admitted)).To(Equal([]string{"task-1", "task-2", "task-4"}))
+			Expect(guids(refused)).To(Equal([]string{"task-3"}))
+		})
+
+		It("does not count resubmitted tasks twice", func() {
+			enforcer.AdmitTasks([]auctioneer.TaskStartRequest{task("task-1", "ci", linux), task("task-2", "ci", linux)})
+
+			admitted, refused := enforcer.AdmitTasks([]auctioneer.TaskStartRequest{task("task-1", "ci", linux)})
+			Expect(guids(admitted)).To(Equal([]string{"task-1"}))
+			Expect(refused).To(BeEmpty())
+		})
+
+		It("counts the work the cells reported in the most recent auction", func() {
+			enforcer.AuctionCompleted(auctionrunnerdelegate.CompletedAuction{
+				StartedAt: fakeClock.Now(),
+				CellStates: map[string]rep.CellState{
+					"cell-1": {
+						LRPs: []rep.LRP{
+							rep.NewLRP("instance", models.NewActualLRPKey("process-guid", 0, "ci"), resource, linux),
+						},
+						Tasks: []rep.Task{rep.NewTask("running-task", "ci", resource, linux)},
+					},
+				},
+			})
+
+			admitted, refused := enforcer.AdmitTasks([]auctioneer.TaskStartRequest{task("task-1", "ci", linux)})
+			Expect(admitted).To(BeEmpty())
+			Expect(guids(refused)).To(Equal([]string{"task-1"}))
+		})
+	})
+
+	Describe("cell usage", func() {
+		running := func(guids ...string) rep.CellState {
+			state := rep.CellState{}
+			for _, guid := range guids {
+				state.Tasks = append(state.Tasks, rep.NewTask(guid, "ci", resource, linux))
+			}
+			return state
+		}
+
+		JustBeforeEach(func() {
+			enforcer.AuctionCompleted(auctionrunnerdelegate.CompletedAuction{
+				StartedAt:  fakeClock.Now(),
+				CellIDs:    []string{"cell-1", "cell-2"},
+				CellStates: map[string]rep.CellState{"cell-1": running("task-1"), "cell-2": running("task-2")},
+			})
+		})
+
+		It("keeps the last usage of registered cells that did not report a state", func() {
+			fakeClock.Increment(time.Second)
+			enforcer.AuctionCompleted(auctionrunnerdelegate.CompletedAuction{
+				StartedAt:  fakeClock.Now(),
+				CellIDs:    []string{"cell-1", "cell-2"},
+				CellStates: map[string]rep.CellState{"cell-1": running()},
+			})
+
+			Expect(enforcer.Usage().Domains["ci"].Containers).To(Equal(1))
+		})
+
+		It("replaces the usage of cells that report a new state", func() {
+			fakeClock.Increment(time.Second)
+			enforcer.AuctionCompleted(auctionrunnerdelegate.CompletedAuction{
+				StartedAt:  fakeClock.Now(),
+				CellIDs:    []string{"cell-1", "cell-2"},
+				CellStates: map[string]rep.CellState{"cell-1": running("task-1", "task-3")},
+			})
+
+			Expect(enforcer.Usage().Domains["ci"].Containers).To(Equal(3))
+		})
+
+		It("forgets cells that are no longer registered", func() {
+			fakeClock.Increment(time.Second)
+			enforcer.AuctionCompleted(auctionrunnerdelegate.CompletedAuction{
+				StartedAt:  fakeClock.Now(),
+				CellIDs:    []string{"cell-1"},
+				CellStates: map[string]rep.CellState{},
+			})
+
+			Expect(enforcer.Usage().Domains["ci"].Containers).To(Equal(1))
+		})
+	})
+
+	Describe("AdmitLRPs", func() {
+		It("splits the indices of a request that only partly fits", func() {
+			lrp := auctioneer.NewLRPStartRequest("process-guid", "ci", []int{0, 1, 2}, resource, linux)
+
+			admitted, refused := enforcer.AdmitLRPs([]auctioneer.LRPStartRequest{lrp})
+			Expect(admitted).To(HaveLen(1))
+			Expect(admitted[0].Indices).To(Equal([]int{0, 1}))
+			Expect(refused).To(HaveLen(1))
+			Expect(refused[0].Indices).To(Equal([]int{2}))
+			Expect(refused[0].ProcessGuid).To(Equal("process-guid"))
+		})
+	})
+
+	Describe("AuctionCompleted", func() {
+		admitOne := func(guid string) []auctioneer.TaskStartRequest {
+			admitted, _ := enforcer.AdmitTasks([]auctioneer.TaskStartRequest{task(guid, "ci", linux)})
+			return admitted
+		}
+
+		JustBeforeEach(func() {
+			Expect(admitOne("task-1")).To(HaveLen(1))
+			Expect(admitOne("task-2")).To(HaveLen(1))
+			Expect(admitOne("task-3")).To(BeEmpty())
+		})
+
+		It("stops counting starts the auction failed to place", func() {
+			enforcer.AuctionCompleted(auctionrunnerdelegate.CompletedAuction{
+				StartedAt: fakeClock.Now(),
+				Results: auctiontypes.AuctionResults{
+					FailedTasks: []auctiontypes.TaskAuction{{Task: rep.NewTask("task-1", "ci", resource, linux)}},
+				},
+			})
+
+			Expect(admitOne("task-3")).To(HaveLen(1))
+		})
+
+		It("counts admitted starts once, after the cells report them", func() {
+			enforcer.AuctionCompleted(auctionrunnerdelegate.CompletedAuction{
+				StartedAt: fakeClock.Now(),
+				CellStates: map[string]rep.CellState{
+					"cell-1": {Tasks: []rep.Task{rep.NewTask("task-1", "ci", resource, linux)}},
+				},
+			})
+
+			Expect(enforcer.Usage().Domains["ci"]).To(Equal(quota.Resources{MemoryMB: 200, DiskMB: 200, Containers: 2}))
+		})
+
+		It("stops counting starts the cells never reported after a further auction", func() {
+			fakeClock.Increment(time.Second)
+			enforcer.AuctionCompleted(auctionrunnerdelegate.CompletedAuction{StartedAt: fakeClock.Now()})
+			Expect(enforcer.Usage().Domains["ci"].Containers).To(Equal(2))
+
+			fakeClock.Increment(time.Second)
+			enforcer.AuctionCompleted(auctionrunnerdelegate.CompletedAuction{StartedAt: fakeClock.Now()})
+			Expect(enforcer.Usage().Domains).NotTo(HaveKey("ci"))
+		})
+	})
+
+	Describe("SetConfig", func() {
+		It("applies the new quotas to subsequent admissions", func() {
+			enforcer.SetConfig(quota.Config{})
+			Expect(enforcer.Config()).To(Equal(quota.Config{}))
+
+			admitted, refused := enforcer.AdmitTasks([]auctioneer.TaskStartRequest{
+				task("task-1", "ci", linux),
+				task("task-2", "ci", linux),
+				task("task-3", "ci", linux),
+			})
+			Expect(admitted).To(HaveLen(3))
+			Expect(refused).To(BeEmpty())
+			Expect(logger).To(gbytes.Say("quota-enforcer.updated"))
+		})
+	})
+})
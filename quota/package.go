@@ -0,0 +1 @@
+package quota // import "code.cloudfoundry.org/auctioneer/quota"
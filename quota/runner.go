@@ -0,0 +1,137 @@
+package quota
+
+import (
+	"os"
+	"sync"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/rep"
+)
+
+// DefaultReportQueueSize is how many reports of refused work may wait for the
+// reporters before scheduling more work blocks.
+const DefaultReportQueueSize = 100
+
+// Reporter is told about the work a Runner refused. The auction runner
+// delegate implements it to update the BBS, and the work tracker to stop
+// tracking the work.
+type Reporter interface {
+	WorkRefused(results auctiontypes.AuctionResults)
+}
+
+// Runner is an auctiontypes.AuctionRunner that only schedules the starts that
+// fit within their quotas. Refused starts are reported as failed with
+// PlacementError, so the BBS rejects the tasks and records the error on the
+// actual LRPs.
+//
+// Reports are queued and made one at a time by a single worker while the
+// runner runs, so that scheduling does not wait on the BBS unless the queue is
+// full. Once the wrapped runner exits, Run makes the reports still queued
+// before returning; work refused after that is reported straight away.
+type Runner struct {
+	logger    lager.Logger
+	runner    auctiontypes.AuctionRunner
+	enforcer  *Enforcer
+	reporters []Reporter
+	reports   chan auctiontypes.AuctionResults
+
+	lock    sync.RWMutex
+	stopped bool
+}
+
+func NewRunner(logger lager.Logger, runner auctiontypes.AuctionRunner, enforcer *Enforcer, reporters ...Reporter) *Runner {
+	return &Runner{
+		logger:    logger.Session("quota"),
+		runner:    runner,
+		enforcer:  enforcer,
+		reporters: reporters,
+		reports:   make(chan auctiontypes.AuctionResults, DefaultReportQueueSize),
+	}
+}
+
+func (r *Runner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for results := range r.reports {
+			r.report(results)
+		}
+	}()
+
+	err := r.runner.Run(signals, ready)
+
+	r.lock.Lock()
+	r.stopped = true
+	close(r.reports)
+	r.lock.Unlock()
+
+	<-done
+	return err
+}
+
+func (r *Runner) ScheduleLRPsForAuctions(lrps []auctioneer.LRPStartRequest) {
+	admitted, refused := r.enforcer.AdmitLRPs(lrps)
+	if len(admitted) > 0 {
+		r.runner.ScheduleLRPsForAuctions(admitted)
+	}
+	if len(refused) == 0 {
+		return
+	}
+
+	failed := []auctiontypes.LRPAuction{}
+	for _, lrp := range refused {
+		for _, index := range lrp.Indices {
+			failed = append(failed, auctiontypes.LRPAuction{
+				LRP:           rep.NewLRP("", models.NewActualLRPKey(lrp.ProcessGuid, int32(index), lrp.Domain), lrp.Resource, lrp.PlacementConstraint),
+				AuctionRecord: auctiontypes.AuctionRecord{PlacementError: PlacementError},
+			})
+		}
+	}
+
+	r.logger.Info("refused-lrps", lager.Data{"count": len(failed)})
+	r.enqueue(auctiontypes.AuctionResults{FailedLRPs: failed})
+}
+
+func (r *Runner) ScheduleTasksForAuctions(tasks []auctioneer.TaskStartRequest) {
+	admitted, refused := r.enforcer.AdmitTasks(tasks)
+	if len(admitted) > 0 {
+		r.runner.ScheduleTasksForAuctions(admitted)
+	}
+	if len(refused) == 0 {
+		return
+	}
+
+	failed := make([]auctiontypes.TaskAuction, 0, len(refused))
+	for _, task := range refused {
+		failed = append(failed, auctiontypes.TaskAuction{
+			Task:          task.Task,
+			AuctionRecord: auctiontypes.AuctionRecord{PlacementError: PlacementError},
+		})
+	}
+
+	r.logger.Info("refused-tasks", lager.Data{"count": len(failed)})
+	r.enqueue(auctiontypes.AuctionResults{FailedTasks: failed})
+}
+
+// enqueue queues the report for the worker, waiting for room in the queue if
+// it is full.
+func (r *Runner) enqueue(results auctiontypes.AuctionResults) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if r.stopped {
+		r.report(results)
+		return
+	}
+
+	r.reports <- results
+}
+
+func (r *Runner) report(results auctiontypes.AuctionResults) {
+	for _, reporter := range r.reporters {
+		reporter.WorkRefused(results)
+	}
+}
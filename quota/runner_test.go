@@ -0,0 +1,168 @@
+package quota_test
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	fake_auction_runner "code.cloudfoundry.org/auction/auctiontypes/fakes"
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/auctioneer/quota"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeReporter struct {
+	lock    sync.Mutex
+	results []auctiontypes.AuctionResults
+	block   chan struct{}
+}
+
+func (r *fakeReporter) WorkRefused(results auctiontypes.AuctionResults) {
+	if r.block != nil {
+		<-r.block
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.results = append(r.results, results)
+}
+
+func (r *fakeReporter) Results() []auctiontypes.AuctionResults {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]auctiontypes.AuctionResults{}, r.results...)
+}
+
+var _ = Describe("Runner", func() {
+	var (
+		logger   *lagertest.TestLogger
+		inner    *fake_auction_runner.FakeAuctionRunner
+		reporter *fakeReporter
+		runner   *quota.Runner
+		process  ifrit.Process
+
+		resource rep.Resource
+		pc       rep.PlacementConstraint
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		inner = new(fake_auction_runner.FakeAuctionRunner)
+		reporter = &fakeReporter{}
+
+		enforcer := quota.New(logger, fakeclock.NewFakeClock(time.Now()), quota.Config{
+			Domains: map[string]quota.Quota{"ci": {Containers: 1}},
+		})
+		runner = quota.NewRunner(logger, inner, enforcer, reporter)
+
+		inner.RunStub = func(signals <-chan os.Signal, ready chan<- struct{}) error {
+			close(ready)
+			<-signals
+			return nil
+		}
+
+		resource = rep.NewResource(10, 10, 10)
+		pc = rep.NewPlacementConstraint("preloaded:linux", nil, nil)
+	})
+
+	JustBeforeEach(func() {
+		process = ginkgomon.Invoke(runner)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+	})
+
+	It("runs the wrapped runner", func() {
+		ginkgomon.Interrupt(process)
+		Expect(inner.RunCallCount()).To(Equal(1))
+	})
+
+	It("schedules the admitted tasks and reports the refused ones as failed", func() {
+		runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{
+			auctioneer.NewTaskStartRequest(rep.NewTask("task-1", "ci", resource, pc)),
+			auctioneer.NewTaskStartRequest(rep.NewTask("task-2", "ci", resource, pc)),
+		})
+
+		Expect(inner.ScheduleTasksForAuctionsCallCount()).To(Equal(1))
+		scheduled := inner.ScheduleTasksForAuctionsArgsForCall(0)
+		Expect(scheduled).To(HaveLen(1))
+		Expect(scheduled[0].TaskGuid).To(Equal("task-1"))
+
+		Eventually(reporter.Results).Should(HaveLen(1))
+		results := reporter.Results()[0]
+		Expect(results.FailedTasks).To(HaveLen(1))
+		Expect(results.FailedTasks[0].TaskGuid).To(Equal("task-2"))
+		Expect(results.FailedTasks[0].PlacementError).To(Equal(quota.PlacementError))
+	})
+
+	It("schedules the admitted LRP indices and reports the refused ones as failed", func() {
+		runner.ScheduleLRPsForAuctions([]auctioneer.LRPStartRequest{
+			auctioneer.NewLRPStartRequest("process-guid", "ci", []int{0, 1}, resource, pc),
+		})
+
+		Expect(inner.ScheduleLRPsForAuctionsCallCount()).To(Equal(1))
+		scheduled := inner.ScheduleLRPsForAuctionsArgsForCall(0)
+		Expect(scheduled).To(HaveLen(1))
+		Expect(scheduled[0].Indices).To(Equal([]int{0}))
+
+		Eventually(reporter.Results).Should(HaveLen(1))
+		Expect(reporter.Results()[0].FailedLRPs).To(HaveLen(1))
+		failed := reporter.Results()[0].FailedLRPs[0]
+		Expect(failed.ActualLRPKey).To(Equal(models.NewActualLRPKey("process-guid", 1, "ci")))
+		Expect(failed.PlacementError).To(Equal(quota.PlacementError))
+	})
+
+	It("does not report anything when all the work is admitted", func() {
+		runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{
+			auctioneer.NewTaskStartRequest(rep.NewTask("task-1", "other", resource, pc)),
+			auctioneer.NewTaskStartRequest(rep.NewTask("task-2", "other", resource, pc)),
+		})
+
+		Expect(inner.ScheduleTasksForAuctionsArgsForCall(0)).To(HaveLen(2))
+		Consistently(reporter.Results).Should(BeEmpty())
+	})
+
+	Context("when it is signalled with reports still queued", func() {
+		BeforeEach(func() {
+			reporter.block = make(chan struct{})
+		})
+
+		It("makes them before exiting", func() {
+			for _, guid := range []string{"task-1", "task-2", "task-3"} {
+				runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{
+					auctioneer.NewTaskStartRequest(rep.NewTask(guid, "ci", resource, pc)),
+				})
+			}
+
+			process.Signal(os.Interrupt)
+			Consistently(process.Wait()).ShouldNot(Receive())
+
+			close(reporter.block)
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+			Expect(reporter.Results()).To(HaveLen(2))
+		})
+	})
+
+	Context("when work is refused after it has exited", func() {
+		It("reports it straight away", func() {
+			ginkgomon.Interrupt(process)
+
+			runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{
+				auctioneer.NewTaskStartRequest(rep.NewTask("task-1", "ci", resource, pc)),
+				auctioneer.NewTaskStartRequest(rep.NewTask("task-2", "ci", resource, pc)),
+			})
+
+			Expect(reporter.Results()).To(HaveLen(1))
+		})
+	})
+})
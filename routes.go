@@ -11,3 +11,36 @@ var Routes = rata.Routes{
 	{Path: "/v1/tasks", Method: "POST", Name: CreateTaskAuctionsRoute},
 	{Path: "/v1/lrps", Method: "POST", Name: CreateLRPAuctionsRoute},
 }
+
+const (
+	StatusRoute       = "Status"
+	CellsRoute        = "Cells"
+	CordonsRoute      = "Cordons"
+	CordonCellRoute   = "CordonCell"
+	UncordonCellRoute = "UncordonCell"
+	QuotasRoute       = "Quotas"
+	PauseRoute        = "Pause"
+	ResumeRoute       = "Resume"
+	ReloadConfigRoute = "ReloadConfig"
+
+	PlacementExplanationsRoute = "PlacementExplanations"
+)
+
+// AdminRoutes are served on the admin listener, separately from Routes.
+var AdminRoutes = rata.Routes{
+	{Path: "/v1/status", Method: "GET", Name: StatusRoute},
+
+	{Path: "/v1/cells", Method: "GET", Name: CellsRoute},
+	{Path: "/v1/cordons", Method: "GET", Name: CordonsRoute},
+	{Path: "/v1/cordons/:cell_id", Method: "PUT", Name: CordonCellRoute},
+	{Path: "/v1/cordons/:cell_id", Method: "DELETE", Name: UncordonCellRoute},
+
+	{Path: "/v1/quotas", Method: "GET", Name: QuotasRoute},
+
+	{Path: "/v1/pause", Method: "POST", Name: PauseRoute},
+	{Path: "/v1/resume", Method: "POST", Name: ResumeRoute},
+
+	{Path: "/v1/config/reload", Method: "POST", Name: ReloadConfigRoute},
+
+	{Path: "/v1/placement_explanations", Method: "GET", Name: PlacementExplanationsRoute},
+}
@@ -2,7 +2,6 @@ package auctioneer
 
 import (
 	"encoding/json"
-	"errors"
 	"time"
 
 	"code.cloudfoundry.org/clock"
@@ -10,6 +9,7 @@ import (
 	loggingclient "code.cloudfoundry.org/diego-logging-client"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/locket"
+	"github.com/hashicorp/consul/api"
 	"github.com/tedsuo/ifrit"
 )
 
@@ -19,34 +19,14 @@ func LockSchemaPath() string {
 	return locket.LockSchemaPath(LockSchemaKey)
 }
 
-type Presence struct {
-	AuctioneerID      string `json:"auctioneer_id"`
-	AuctioneerAddress string `json:"auctioneer_address"`
-}
-
-func NewPresence(id, address string) Presence {
-	return Presence{
-		AuctioneerID:      id,
-		AuctioneerAddress: address,
-	}
-}
-
-func (a Presence) Validate() error {
-	if a.AuctioneerID == "" {
-		return errors.New("auctioneer_id cannot be blank")
-	}
-
-	if a.AuctioneerAddress == "" {
-		return errors.New("auctioneer_address cannot be blank")
-	}
-
-	return nil
-}
-
 type ServiceClient interface {
 	NewAuctioneerLockRunner(logger lager.Logger, presence Presence, retryInterval, lockTTL time.Duration, metronClient loggingclient.IngressClient) (ifrit.Runner, error)
 	CurrentAuctioneer() (Presence, error)
 	CurrentAuctioneerAddress() (string, error)
+
+	// WatchAuctioneer sends the presence of the auctioneer holding the lock
+	// whenever it changes, until stop is closed.
+	WatchAuctioneer(logger lager.Logger, stop <-chan struct{}) <-chan Presence
 }
 
 type serviceClient struct {
@@ -74,22 +54,12 @@ func (c serviceClient) NewAuctioneerLockRunner(logger lager.Logger, presence Pre
 }
 
 func (c serviceClient) CurrentAuctioneer() (Presence, error) {
-	presence := Presence{}
-
 	value, err := c.getAcquiredValue(LockSchemaPath())
 	if err != nil {
-		return presence, err
-	}
-
-	if err := json.Unmarshal(value, &presence); err != nil {
-		return presence, err
+		return Presence{}, err
 	}
 
-	if err := presence.Validate(); err != nil {
-		return presence, err
-	}
-
-	return presence, nil
+	return decodePresence(value)
 }
 
 func (c serviceClient) CurrentAuctioneerAddress() (string, error) {
@@ -97,6 +67,42 @@ func (c serviceClient) CurrentAuctioneerAddress() (string, error) {
 	return presence.AuctioneerAddress, err
 }
 
+// WatchAuctioneer uses consul blocking queries on the lock, so a change is
+// seen as soon as consul records it.
+func (c serviceClient) WatchAuctioneer(logger lager.Logger, stop <-chan struct{}) <-chan Presence {
+	var index uint64
+
+	fetch := func(logger lager.Logger) (Presence, error) {
+		key := LockSchemaPath()
+		kvPair, meta, err := c.consulClient.KV().Get(key, &api.QueryOptions{WaitIndex: index, WaitTime: DefaultWatchWaitTime})
+		if err != nil {
+			index = 0
+			return Presence{}, err
+		}
+
+		// The index can go backwards if the consul cluster is rebuilt, in
+		// which case blocking on it would never return until the wait time.
+		if meta.LastIndex < index {
+			index = 0
+		} else {
+			index = meta.LastIndex
+		}
+
+		if kvPair == nil || kvPair.Session == "" {
+			return Presence{}, consuladapter.NewKeyNotFoundError(key)
+		}
+
+		return decodePresence(kvPair.Value)
+	}
+
+	return presenceWatcher{
+		clock:         c.clock,
+		fetch:         fetch,
+		retryInterval: DefaultWatchRetryInterval,
+		debounce:      DefaultWatchDebounce,
+	}.watch(logger.Session("watch-auctioneer"), stop)
+}
+
 func (c serviceClient) getAcquiredValue(key string) ([]byte, error) {
 	kvPair, _, err := c.consulClient.KV().Get(key, nil)
 	if err != nil {
@@ -109,3 +115,17 @@ func (c serviceClient) getAcquiredValue(key string) ([]byte, error) {
 
 	return kvPair.Value, nil
 }
+
+func decodePresence(value []byte) (Presence, error) {
+	presence := Presence{}
+
+	if err := json.Unmarshal(value, &presence); err != nil {
+		return presence, err
+	}
+
+	if err := presence.Validate(); err != nil {
+		return presence, err
+	}
+
+	return presence, nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/tedsuo/ifrit/ginkgomon"
 
 	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/clock/fakeclock"
 	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
 )
@@ -17,15 +18,15 @@ import (
 var _ = Describe("ServiceClient", func() {
 	var serviceClient auctioneer.ServiceClient
 
-	var clock *fakeclock.FakeClock
+	var fakeClock *fakeclock.FakeClock
 	var logger *lagertest.TestLogger
 
 	BeforeEach(func() {
-		clock = fakeclock.NewFakeClock(time.Now())
+		fakeClock = fakeclock.NewFakeClock(time.Now())
 		logger = lagertest.NewTestLogger("test")
 
 		consulClient := consulRunner.NewClient()
-		serviceClient = auctioneer.NewServiceClient(consulClient, clock)
+		serviceClient = auctioneer.NewServiceClient(consulClient, fakeClock)
 	})
 
 	Describe("AuctioneerAddress", func() {
@@ -60,4 +61,34 @@ var _ = Describe("ServiceClient", func() {
 			})
 		})
 	})
+
+	Describe("WatchAuctioneer", func() {
+		var (
+			watchingClient auctioneer.ServiceClient
+			stop           chan struct{}
+			presences      <-chan auctioneer.Presence
+		)
+
+		BeforeEach(func() {
+			watchingClient = auctioneer.NewServiceClient(consulRunner.NewClient(), clock.NewClock())
+			stop = make(chan struct{})
+			presences = watchingClient.WatchAuctioneer(logger, stop)
+		})
+
+		AfterEach(func() {
+			close(stop)
+		})
+
+		It("sends the presence once an auctioneer acquires the lock", func() {
+			Consistently(presences).ShouldNot(Receive())
+
+			presence := auctioneer.NewPresence("auctioneer-id", "http://auctioneer.example.com:9016")
+			auctioneerLock, err := watchingClient.NewAuctioneerLockRunner(logger, presence, 100*time.Millisecond, 10*time.Second, &mfakes.FakeIngressClient{})
+			Expect(err).NotTo(HaveOccurred())
+			heartbeater := ginkgomon.Invoke(auctioneerLock)
+			defer ginkgomon.Interrupt(heartbeater)
+
+			Eventually(presences, 5*time.Second).Should(Receive(Equal(presence)))
+		})
+	})
 })
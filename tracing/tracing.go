@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/tedsuo/ifrit"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	InstrumentationName = "code.cloudfoundry.org/auctioneer"
+	ServiceName         = "auctioneer"
+
+	shutdownTimeout = 5 * time.Second
+)
+
+// Propagator propagates trace context using the W3C traceparent and
+// tracestate headers.
+var Propagator propagation.TextMapPropagator = propagation.TraceContext{}
+
+// Tracer returns the tracer used for every span created by the auctioneer.
+// Spans are dropped unless a provider has been installed with NewProvider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// Inject adds the trace context in ctx to the request headers. Only requests
+// to the auctioneer carry it; the rep and BBS clients take no context, so
+// their requests are not traced beyond the auctioneer's own spans.
+func Inject(ctx context.Context, header http.Header) {
+	Propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// Extract returns ctx with the trace context found in the request headers.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return Propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// NewProvider installs a global tracer provider that exports spans over
+// OTLP/HTTP to the collector at collectorAddress (host:port). The returned
+// runner flushes and shuts down the provider when signalled; it should be
+// started before, and therefore stopped after, every other member.
+func NewProvider(logger lager.Logger, collectorAddress string, insecure bool) (ifrit.Runner, error) {
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(collectorAddress)}
+	if insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(Propagator)
+
+	return ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+		logger := logger.Session("tracing")
+		close(ready)
+
+		<-signals
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		err := provider.Shutdown(ctx)
+		if err != nil {
+			logger.Error("failed-to-shutdown-tracer-provider", err)
+		}
+		return nil
+	}), nil
+}
+
+// LoggerData returns the trace and span ids of the span in ctx, for
+// correlating log lines with traces.
+func LoggerData(ctx context.Context) lager.Data {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return lager.Data{}
+	}
+
+	return lager.Data{
+		"trace-id": spanContext.TraceID().String(),
+		"span-id":  spanContext.SpanID().String(),
+	}
+}
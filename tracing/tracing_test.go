@@ -0,0 +1,96 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http"
+
+	"code.cloudfoundry.org/auctioneer/tracing"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/tedsuo/ifrit/ginkgomon"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tracing", func() {
+	var spanContext trace.SpanContext
+
+	BeforeEach(func() {
+		traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+		Expect(err).NotTo(HaveOccurred())
+		spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+		Expect(err).NotTo(HaveOccurred())
+		spanContext = trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled})
+	})
+
+	Describe("Inject", func() {
+		It("adds a traceparent header for the span in the context", func() {
+			header := http.Header{}
+			tracing.Inject(trace.ContextWithSpanContext(context.Background(), spanContext), header)
+
+			Expect(header.Get("traceparent")).To(Equal("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+		})
+
+		It("adds nothing when the context has no span", func() {
+			header := http.Header{}
+			tracing.Inject(context.Background(), header)
+
+			Expect(header).To(BeEmpty())
+		})
+	})
+
+	Describe("Extract", func() {
+		It("returns a context with the remote span of the traceparent header", func() {
+			header := http.Header{}
+			header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+			ctx := tracing.Extract(context.Background(), header)
+			Expect(trace.SpanContextFromContext(ctx)).To(Equal(spanContext.WithRemote(true)))
+		})
+
+		It("returns a context without a span when the header is missing or malformed", func() {
+			header := http.Header{}
+			Expect(trace.SpanContextFromContext(tracing.Extract(context.Background(), header)).IsValid()).To(BeFalse())
+
+			header.Set("traceparent", "not-a-traceparent")
+			Expect(trace.SpanContextFromContext(tracing.Extract(context.Background(), header)).IsValid()).To(BeFalse())
+		})
+	})
+
+	Describe("LoggerData", func() {
+		It("returns the trace and span ids of the span in the context", func() {
+			ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+
+			Expect(tracing.LoggerData(ctx)).To(Equal(lager.Data{
+				"trace-id": "4bf92f3577b34da6a3ce929d0e0e4736",
+				"span-id":  "00f067aa0ba902b7",
+			}))
+		})
+
+		It("returns no data when the context has no span", func() {
+			Expect(tracing.LoggerData(context.Background())).To(BeEmpty())
+		})
+	})
+
+	Describe("NewProvider", func() {
+		AfterEach(func() {
+			otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		})
+
+		It("installs a global tracer provider that is shut down when signalled", func() {
+			runner, err := tracing.NewProvider(lagertest.NewTestLogger("test"), "127.0.0.1:4318", true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(otel.GetTracerProvider()).To(BeAssignableToTypeOf(&sdktrace.TracerProvider{}))
+
+			_, span := tracing.Tracer().Start(context.Background(), "test")
+			Expect(span.SpanContext().IsValid()).To(BeTrue())
+
+			process := ginkgomon.Invoke(runner)
+			ginkgomon.Interrupt(process)
+		})
+	})
+})
@@ -0,0 +1,298 @@
+package tuning
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/auctioneer/worktracker"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"github.com/tedsuo/ifrit"
+)
+
+// RunnerFactory builds an auction runner with the given parameters that
+// reports to the given delegate, along with a function that releases its
+// resources once it has exited.
+type RunnerFactory func(params Parameters, delegate auctiontypes.AuctionRunnerDelegate) (auctiontypes.AuctionRunner, func(), error)
+
+// AuctionRunner is an auctiontypes.AuctionRunner whose parameters can be
+// changed while it runs. The auction runner cannot change its parameters, so
+// an update builds a new runner and hands new work to it. The old runner
+// finishes the auction it is running, if any, and is then stopped. Work that
+// was still queued in it is lost with it, so the work handed to the old runner
+// that no auction has reported on is scheduled again on the new runner, unless
+// it was scheduled longer than maxAge ago.
+type AuctionRunner struct {
+	logger   lager.Logger
+	clock    clock.Clock
+	maxAge   time.Duration
+	factory  RunnerFactory
+	delegate auctiontypes.AuctionRunnerDelegate
+
+	lock    sync.RWMutex
+	params  Parameters
+	running runnerParameters
+	current auctiontypes.AuctionRunner
+	cleanup func()
+	updated chan struct{}
+
+	workLock   sync.Mutex
+	generation int
+	scheduled  map[string]scheduledWork
+}
+
+// scheduledWork is a task or a single LRP instance handed to the runner of
+// the given generation.
+type scheduledWork struct {
+	generation  int
+	scheduledAt time.Time
+	task        *auctioneer.TaskStartRequest
+	lrp         *auctioneer.LRPStartRequest
+}
+
+func NewAuctionRunner(logger lager.Logger, clock clock.Clock, factory RunnerFactory, delegate auctiontypes.AuctionRunnerDelegate, params Parameters, maxAge time.Duration) (*AuctionRunner, error) {
+	if maxAge <= 0 {
+		maxAge = worktracker.DefaultMaxAge
+	}
+
+	a := &AuctionRunner{
+		logger:    logger.Session("tunable-auction-runner"),
+		clock:     clock,
+		maxAge:    maxAge,
+		factory:   factory,
+		params:    params,
+		running:   params.runner(),
+		updated:   make(chan struct{}, 1),
+		scheduled: map[string]scheduledWork{},
+	}
+	a.delegate = &completionDelegate{AuctionRunnerDelegate: delegate, runner: a}
+
+	current, cleanup, err := factory(params, a.delegate)
+	if err != nil {
+		return nil, err
+	}
+	a.current, a.cleanup = current, cleanup
+
+	return a, nil
+}
+
+// Update records new parameters. If they change how the runner is built, the
+// runner is replaced as soon as it is not in the middle of an auction.
+func (a *AuctionRunner) Update(params Parameters) {
+	a.lock.Lock()
+	a.params = params
+	a.lock.Unlock()
+
+	select {
+	case a.updated <- struct{}{}:
+	default:
+	}
+}
+
+func (a *AuctionRunner) ScheduleLRPsForAuctions(lrps []auctioneer.LRPStartRequest) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	a.workLock.Lock()
+	now := a.clock.Now()
+	for i := range lrps {
+		for _, index := range lrps[i].Indices {
+			lrp := lrps[i]
+			lrp.Indices = []int{index}
+			a.track(worktracker.LRPIdentifier(lrp.ProcessGuid, index), scheduledWork{generation: a.generation, scheduledAt: now, lrp: &lrp})
+		}
+	}
+	a.workLock.Unlock()
+
+	a.current.ScheduleLRPsForAuctions(lrps)
+}
+
+func (a *AuctionRunner) ScheduleTasksForAuctions(tasks []auctioneer.TaskStartRequest) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	a.workLock.Lock()
+	now := a.clock.Now()
+	for i := range tasks {
+		task := tasks[i]
+		a.track(task.TaskGuid, scheduledWork{generation: a.generation, scheduledAt: now, task: &task})
+	}
+	a.workLock.Unlock()
+
+	a.current.ScheduleTasksForAuctions(tasks)
+}
+
+// track records work handed to the current runner. Work that is scheduled
+// again, for example when it is rescheduled after a replacement, keeps the
+// time it was first scheduled at so that it still expires. The caller must
+// hold workLock.
+func (a *AuctionRunner) track(identifier string, work scheduledWork) {
+	if previous, ok := a.scheduled[identifier]; ok {
+		work.scheduledAt = previous.scheduledAt
+	}
+	a.scheduled[identifier] = work
+}
+
+func (a *AuctionRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	a.lock.RLock()
+	process := ifrit.Background(a.current)
+	a.lock.RUnlock()
+
+	select {
+	case <-process.Ready():
+	case err := <-process.Wait():
+		a.release()
+		return err
+	}
+
+	close(ready)
+
+	for {
+		select {
+		case signal := <-signals:
+			process.Signal(signal)
+			err := <-process.Wait()
+			a.release()
+			return err
+
+		case err := <-process.Wait():
+			a.release()
+			return err
+
+		case <-a.updated:
+			process = a.replace(process)
+		}
+	}
+}
+
+// replace switches to a runner built with the latest parameters and returns
+// its process, or returns the current process if nothing needs to change.
+func (a *AuctionRunner) replace(process ifrit.Process) ifrit.Process {
+	a.lock.Lock()
+	params := a.params
+	if params.runner() == a.running {
+		a.lock.Unlock()
+		return process
+	}
+
+	logger := a.logger.Session("replace", lager.Data{
+		"auction-runner-workers":           params.AuctionRunnerWorkers,
+		"starting-container-count-maximum": params.StartingContainerCountMaximum,
+		"starting-container-weight":        params.StartingContainerWeight,
+	})
+
+	next, nextCleanup, err := a.factory(params, a.delegate)
+	if err != nil {
+		a.lock.Unlock()
+		logger.Error("failed-to-build-auction-runner", err)
+		return process
+	}
+
+	previousCleanup := a.cleanup
+	nextProcess := ifrit.Background(next)
+	a.current, a.cleanup, a.running = next, nextCleanup, params.runner()
+
+	a.workLock.Lock()
+	previousGeneration := a.generation
+	a.generation++
+	a.workLock.Unlock()
+	a.lock.Unlock()
+
+	process.Signal(os.Interrupt)
+	err = <-process.Wait()
+	if err != nil {
+		logger.Error("previous-auction-runner-failed", err)
+	}
+	previousCleanup()
+
+	a.reschedule(logger, previousGeneration)
+	logger.Info("replaced")
+
+	return nextProcess
+}
+
+// reschedule hands the new runner the work the previous runner was given and
+// did not auction before it stopped.
+func (a *AuctionRunner) reschedule(logger lager.Logger, generation int) {
+	tasks := []auctioneer.TaskStartRequest{}
+	lrps := []auctioneer.LRPStartRequest{}
+
+	a.workLock.Lock()
+	for _, work := range a.scheduled {
+		if work.generation != generation {
+			continue
+		}
+		switch {
+		case work.task != nil:
+			tasks = append(tasks, *work.task)
+		case work.lrp != nil:
+			lrps = append(lrps, *work.lrp)
+		}
+	}
+	a.workLock.Unlock()
+
+	if len(tasks) > 0 {
+		a.ScheduleTasksForAuctions(tasks)
+	}
+	if len(lrps) > 0 {
+		a.ScheduleLRPsForAuctions(lrps)
+	}
+
+	logger.Info("rescheduled", lager.Data{"tasks": len(tasks), "lrps": len(lrps)})
+}
+
+// completed stops tracking the work an auction reported on, and the work that
+// has been scheduled for longer than maxAge without an auction reporting on
+// it.
+func (a *AuctionRunner) completed(results auctiontypes.AuctionResults) {
+	a.workLock.Lock()
+	defer a.workLock.Unlock()
+
+	now := a.clock.Now()
+	expired := 0
+	for identifier, work := range a.scheduled {
+		if now.Sub(work.scheduledAt) > a.maxAge {
+			delete(a.scheduled, identifier)
+			expired++
+		}
+	}
+	if expired > 0 {
+		a.logger.Info("expired-work", lager.Data{"count": expired, "max-age": a.maxAge.String()})
+	}
+
+	for i := range results.SuccessfulTasks {
+		delete(a.scheduled, results.SuccessfulTasks[i].TaskGuid)
+	}
+	for i := range results.FailedTasks {
+		delete(a.scheduled, results.FailedTasks[i].TaskGuid)
+	}
+	for i := range results.SuccessfulLRPs {
+		delete(a.scheduled, results.SuccessfulLRPs[i].Identifier())
+	}
+	for i := range results.FailedLRPs {
+		delete(a.scheduled, results.FailedLRPs[i].Identifier())
+	}
+}
+
+func (a *AuctionRunner) release() {
+	a.lock.RLock()
+	cleanup := a.cleanup
+	a.lock.RUnlock()
+
+	cleanup()
+}
+
+// completionDelegate tells the runner which work each auction reported on
+// before passing the results on.
+type completionDelegate struct {
+	auctiontypes.AuctionRunnerDelegate
+	runner *AuctionRunner
+}
+
+func (d *completionDelegate) AuctionCompleted(results auctiontypes.AuctionResults) {
+	d.AuctionRunnerDelegate.AuctionCompleted(results)
+	d.runner.completed(results)
+}
@@ -0,0 +1,266 @@
+package tuning_test
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	fake_auction_runner "code.cloudfoundry.org/auction/auctiontypes/fakes"
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/auctioneer/tuning"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+type fakeDelegate struct {
+	lock    sync.Mutex
+	results []auctiontypes.AuctionResults
+}
+
+func (d *fakeDelegate) FetchCellReps() (map[string]rep.Client, error) {
+	return map[string]rep.Client{}, nil
+}
+
+func (d *fakeDelegate) AuctionCompleted(results auctiontypes.AuctionResults) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.results = append(d.results, results)
+}
+
+type fakeFactory struct {
+	lock      sync.Mutex
+	built     []tuning.Parameters
+	runners   []*fake_auction_runner.FakeAuctionRunner
+	delegates []auctiontypes.AuctionRunnerDelegate
+	cleanups  int
+	err       error
+}
+
+func (f *fakeFactory) build(params tuning.Parameters, delegate auctiontypes.AuctionRunnerDelegate) (auctiontypes.AuctionRunner, func(), error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.err != nil {
+		return nil, nil, f.err
+	}
+
+	runner := new(fake_auction_runner.FakeAuctionRunner)
+	runner.RunStub = func(signals <-chan os.Signal, ready chan<- struct{}) error {
+		close(ready)
+		<-signals
+		return nil
+	}
+
+	f.built = append(f.built, params)
+	f.runners = append(f.runners, runner)
+	f.delegates = append(f.delegates, delegate)
+	return runner, f.cleanup, nil
+}
+
+func (f *fakeFactory) cleanup() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.cleanups++
+}
+
+func (f *fakeFactory) runner(i int) *fake_auction_runner.FakeAuctionRunner {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.runners[i]
+}
+
+func (f *fakeFactory) delegate(i int) auctiontypes.AuctionRunnerDelegate {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.delegates[i]
+}
+
+func (f *fakeFactory) builtCount() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return len(f.built)
+}
+
+func (f *fakeFactory) cleanupCount() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.cleanups
+}
+
+var _ = Describe("AuctionRunner", func() {
+	var (
+		logger    *lagertest.TestLogger
+		fakeClock *fakeclock.FakeClock
+		delegate  *fakeDelegate
+		factory   *fakeFactory
+		params    tuning.Parameters
+		runner    *tuning.AuctionRunner
+		process   ifrit.Process
+
+		oldTask, doneTask, newTask auctioneer.TaskStartRequest
+		oldLRP                     auctioneer.LRPStartRequest
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		delegate = &fakeDelegate{}
+		factory = &fakeFactory{}
+
+		resource := rep.NewResource(10, 10, 10)
+		pc := rep.NewPlacementConstraint("preloaded:linux", nil, nil)
+		oldTask = auctioneer.NewTaskStartRequest(rep.NewTask("old-task", "domain", resource, pc))
+		doneTask = auctioneer.NewTaskStartRequest(rep.NewTask("done-task", "domain", resource, pc))
+		newTask = auctioneer.NewTaskStartRequest(rep.NewTask("new-task", "domain", resource, pc))
+		oldLRP = auctioneer.NewLRPStartRequest("old-lrp", "domain", []int{0}, resource, pc)
+
+		params = tuning.Parameters{
+			AuctionRunnerWorkers:          10,
+			StartingContainerCountMaximum: 5,
+			StartingContainerWeight:       0.25,
+			CellStateTimeout:              time.Second,
+			CommunicationTimeout:          10 * time.Second,
+		}
+
+		var err error
+		runner, err = tuning.NewAuctionRunner(logger, fakeClock, factory.build, delegate, params, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		process = ginkgomon.Invoke(runner)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+	})
+
+	It("builds a runner with the initial parameters", func() {
+		Expect(factory.built).To(Equal([]tuning.Parameters{params}))
+		Eventually(factory.runner(0).RunCallCount).Should(Equal(1))
+	})
+
+	It("schedules work on the runner", func() {
+		runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{newTask})
+		runner.ScheduleLRPsForAuctions([]auctioneer.LRPStartRequest{oldLRP})
+
+		Expect(factory.runner(0).ScheduleTasksForAuctionsCallCount()).To(Equal(1))
+		Expect(factory.runner(0).ScheduleLRPsForAuctionsCallCount()).To(Equal(1))
+	})
+
+	It("passes the auction results on to the delegate", func() {
+		results := auctiontypes.AuctionResults{SuccessfulTasks: []auctiontypes.TaskAuction{{Task: doneTask.Task}}}
+		factory.delegate(0).AuctionCompleted(results)
+		Expect(delegate.results).To(Equal([]auctiontypes.AuctionResults{results}))
+	})
+
+	It("stops the runner and releases its resources when signalled", func() {
+		ginkgomon.Interrupt(process)
+		Expect(factory.cleanupCount()).To(Equal(1))
+	})
+
+	Context("when the parameters the runner is built with change", func() {
+		JustBeforeEach(func() {
+			runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{oldTask, doneTask})
+			runner.ScheduleLRPsForAuctions([]auctioneer.LRPStartRequest{oldLRP})
+			factory.delegate(0).AuctionCompleted(auctiontypes.AuctionResults{
+				SuccessfulTasks: []auctiontypes.TaskAuction{{Task: doneTask.Task}},
+			})
+
+			params.StartingContainerWeight = 0.75
+			runner.Update(params)
+			Eventually(logger).Should(gbytes.Say("replace.replaced"))
+		})
+
+		It("replaces the runner", func() {
+			Expect(factory.builtCount()).To(Equal(2))
+			Expect(factory.built[1]).To(Equal(params))
+			Eventually(factory.runner(1).RunCallCount).Should(Equal(1))
+		})
+
+		It("releases the previous runner once it has stopped", func() {
+			Expect(factory.cleanupCount()).To(Equal(1))
+		})
+
+		It("schedules the work the previous runner did not auction on the new runner", func() {
+			newRunner := factory.runner(1)
+			Expect(newRunner.ScheduleTasksForAuctionsCallCount()).To(Equal(1))
+			Expect(newRunner.ScheduleTasksForAuctionsArgsForCall(0)).To(ConsistOf(oldTask))
+			Expect(newRunner.ScheduleLRPsForAuctionsCallCount()).To(Equal(1))
+			Expect(newRunner.ScheduleLRPsForAuctionsArgsForCall(0)).To(ConsistOf(oldLRP))
+		})
+
+		It("schedules new work on the new runner", func() {
+			runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{newTask})
+			Expect(factory.runner(0).ScheduleTasksForAuctionsCallCount()).To(Equal(0))
+			Expect(factory.runner(1).ScheduleTasksForAuctionsCallCount()).To(Equal(2))
+		})
+
+		It("keeps tracking rescheduled work until an auction reports on it", func() {
+			params.StartingContainerWeight = 0.5
+			runner.Update(params)
+			Eventually(logger).Should(gbytes.Say("replace.rescheduled.*\"lrps\":1,.*\"tasks\":1"))
+			Eventually(logger).Should(gbytes.Say("replace.replaced"))
+
+			Expect(factory.runner(2).ScheduleTasksForAuctionsArgsForCall(0)).To(ConsistOf(oldTask))
+		})
+	})
+
+	Context("when scheduled work is not reported on within the max age", func() {
+		JustBeforeEach(func() {
+			runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{oldTask})
+			fakeClock.Increment(2 * time.Minute)
+			runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{newTask})
+			factory.delegate(0).AuctionCompleted(auctiontypes.AuctionResults{})
+		})
+
+		It("stops tracking it", func() {
+			Expect(logger).To(gbytes.Say("tunable-auction-runner.expired-work.*\"count\":1"))
+
+			params.StartingContainerWeight = 0.75
+			runner.Update(params)
+			Eventually(logger).Should(gbytes.Say("replace.replaced"))
+
+			Expect(factory.runner(1).ScheduleTasksForAuctionsCallCount()).To(Equal(1))
+			Expect(factory.runner(1).ScheduleTasksForAuctionsArgsForCall(0)).To(ConsistOf(newTask))
+		})
+	})
+
+	Context("when only the timeouts change", func() {
+		JustBeforeEach(func() {
+			params.CommunicationTimeout = time.Minute
+			runner.Update(params)
+		})
+
+		It("keeps the runner", func() {
+			Consistently(factory.builtCount).Should(Equal(1))
+		})
+	})
+
+	Context("when the new runner cannot be built", func() {
+		JustBeforeEach(func() {
+			factory.lock.Lock()
+			factory.err = errors.New("boom")
+			factory.lock.Unlock()
+
+			params.AuctionRunnerWorkers = 20
+			runner.Update(params)
+		})
+
+		It("logs the error and keeps the runner", func() {
+			Eventually(logger).Should(gbytes.Say("failed-to-build-auction-runner"))
+
+			runner.ScheduleTasksForAuctions([]auctioneer.TaskStartRequest{newTask})
+			Expect(factory.runner(0).ScheduleTasksForAuctionsCallCount()).To(Equal(1))
+		})
+	})
+})
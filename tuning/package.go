@@ -0,0 +1 @@
+package tuning // import "code.cloudfoundry.org/auctioneer/tuning"
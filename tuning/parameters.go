@@ -0,0 +1,44 @@
+package tuning
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/quota"
+)
+
+// ReloadableFields are the JSON names of the configuration properties that
+// take effect without a restart.
+var ReloadableFields = []string{
+	"auction_runner_workers",
+	"cell_state_timeout",
+	"communication_timeout",
+	"quotas",
+	"starting_container_count_maximum",
+	"starting_container_weight",
+}
+
+// Parameters are the auction tuning values that can be changed while the
+// auctioneer is running.
+type Parameters struct {
+	AuctionRunnerWorkers          int
+	StartingContainerCountMaximum int
+	StartingContainerWeight       float64
+	CellStateTimeout              time.Duration
+	CommunicationTimeout          time.Duration
+	Quotas                        quota.Config
+}
+
+// runnerParameters are the parameters the auction runner is built with.
+type runnerParameters struct {
+	auctionRunnerWorkers          int
+	startingContainerCountMaximum int
+	startingContainerWeight       float64
+}
+
+func (p Parameters) runner() runnerParameters {
+	return runnerParameters{
+		auctionRunnerWorkers:          p.AuctionRunnerWorkers,
+		startingContainerCountMaximum: p.StartingContainerCountMaximum,
+		startingContainerWeight:       p.StartingContainerWeight,
+	}
+}
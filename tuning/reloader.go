@@ -0,0 +1,117 @@
+package tuning
+
+import (
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"code.cloudfoundry.org/auctioneer/quota"
+	"code.cloudfoundry.org/lager"
+)
+
+// LoadFunc reads and validates the configuration, returning the tuning
+// parameters it sets and the names of every property that differs from the
+// configuration the auctioneer was started with.
+type LoadFunc func() (Parameters, []string, error)
+
+// Quotas applies the resource quotas from the configuration.
+type Quotas interface {
+	SetConfig(config quota.Config)
+}
+
+// Reloader applies the tuning parameters from the configuration each time the
+// auctioneer receives SIGHUP. Changes to other properties are logged as
+// requiring a restart and otherwise ignored.
+//
+// Reloader listens for SIGHUP itself rather than through the process group,
+// which treats every signal it receives as a request to shut down.
+type Reloader struct {
+	logger               lager.Logger
+	load                 LoadFunc
+	runner               *AuctionRunner
+	communicationTimeout *Timeout
+	cellStateTimeout     *Timeout
+	quotas               Quotas
+}
+
+func NewReloader(logger lager.Logger, load LoadFunc, runner *AuctionRunner, communicationTimeout, cellStateTimeout *Timeout, quotas Quotas) *Reloader {
+	return &Reloader{
+		logger:               logger.Session("reloader"),
+		load:                 load,
+		runner:               runner,
+		communicationTimeout: communicationTimeout,
+		cellStateTimeout:     cellStateTimeout,
+		quotas:               quotas,
+	}
+}
+
+func (r *Reloader) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	defer signal.Stop(hangups)
+
+	close(ready)
+
+	r.logger.Info("started")
+	defer r.logger.Info("finished")
+
+	for {
+		select {
+		case <-signals:
+			return nil
+		case <-hangups:
+			r.Reload()
+		}
+	}
+}
+
+// Reload loads the configuration and applies its tuning parameters. An
+// invalid configuration leaves the current parameters in place and is
+// returned.
+func (r *Reloader) Reload() error {
+	logger := r.logger.Session("reload")
+
+	params, changed, err := r.load()
+	if err != nil {
+		logger.Error("failed-to-reload", err)
+		return err
+	}
+
+	if restartRequired := nonReloadable(changed); len(restartRequired) > 0 {
+		logger.Info("restart-required", lager.Data{"fields": restartRequired})
+	}
+
+	r.communicationTimeout.Set(params.CommunicationTimeout)
+	r.cellStateTimeout.Set(params.CellStateTimeout)
+	r.runner.Update(params)
+	r.quotas.SetConfig(params.Quotas)
+
+	logger.Info("reloaded", lager.Data{
+		"auction-runner-workers":           params.AuctionRunnerWorkers,
+		"cell-state-timeout":               params.CellStateTimeout.String(),
+		"communication-timeout":            params.CommunicationTimeout.String(),
+		"quota-domains":                    len(params.Quotas.Domains),
+		"quota-placement-tags":             len(params.Quotas.PlacementTags),
+		"starting-container-count-maximum": params.StartingContainerCountMaximum,
+		"starting-container-weight":        params.StartingContainerWeight,
+	})
+
+	return nil
+}
+
+func nonReloadable(fields []string) []string {
+	reloadable := map[string]bool{}
+	for _, field := range ReloadableFields {
+		reloadable[field] = true
+	}
+
+	restartRequired := []string{}
+	for _, field := range fields {
+		if !reloadable[field] {
+			restartRequired = append(restartRequired, field)
+		}
+	}
+	sort.Strings(restartRequired)
+	return restartRequired
+}
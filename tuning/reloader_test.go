@@ -0,0 +1,149 @@
+package tuning_test
+
+import (
+	"errors"
+	"syscall"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/quota"
+	"code.cloudfoundry.org/auctioneer/tuning"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Reloader", func() {
+	var (
+		logger               *lagertest.TestLogger
+		factory              *fakeFactory
+		runner               *tuning.AuctionRunner
+		runnerProcess        ifrit.Process
+		communicationTimeout *tuning.Timeout
+		cellStateTimeout     *tuning.Timeout
+		quotas               *quota.Enforcer
+
+		params   tuning.Parameters
+		changed  []string
+		loadErr  error
+		reloader *tuning.Reloader
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		factory = &fakeFactory{}
+		communicationTimeout = tuning.NewTimeout(10 * time.Second)
+		cellStateTimeout = tuning.NewTimeout(time.Second)
+		quotas = quota.New(logger, fakeclock.NewFakeClock(time.Now()), quota.Config{})
+
+		params = tuning.Parameters{
+			AuctionRunnerWorkers:          10,
+			StartingContainerCountMaximum: 5,
+			StartingContainerWeight:       0.25,
+			CellStateTimeout:              time.Second,
+			CommunicationTimeout:          10 * time.Second,
+		}
+
+		var err error
+		runner, err = tuning.NewAuctionRunner(logger, fakeclock.NewFakeClock(time.Now()), factory.build, &fakeDelegate{}, params, 0)
+		Expect(err).NotTo(HaveOccurred())
+		runnerProcess = ginkgomon.Invoke(runner)
+
+		params.AuctionRunnerWorkers = 20
+		params.CellStateTimeout = 2 * time.Second
+		params.CommunicationTimeout = 20 * time.Second
+		params.Quotas = quota.Config{Domains: map[string]quota.Quota{"ci": {MemoryMB: 1024}}}
+		changed = []string{"auction_runner_workers", "cell_state_timeout", "communication_timeout", "quotas"}
+		loadErr = nil
+	})
+
+	JustBeforeEach(func() {
+		load := func() (tuning.Parameters, []string, error) {
+			return params, changed, loadErr
+		}
+		reloader = tuning.NewReloader(logger, load, runner, communicationTimeout, cellStateTimeout, quotas)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(runnerProcess)
+	})
+
+	Describe("Reload", func() {
+		var reloadErr error
+
+		JustBeforeEach(func() {
+			reloadErr = reloader.Reload()
+		})
+
+		It("applies the timeouts", func() {
+			Expect(reloadErr).NotTo(HaveOccurred())
+			Expect(communicationTimeout.Get()).To(Equal(20 * time.Second))
+			Expect(cellStateTimeout.Get()).To(Equal(2 * time.Second))
+		})
+
+		It("applies the auction runner parameters", func() {
+			Eventually(factory.builtCount).Should(Equal(2))
+			Expect(factory.built[1]).To(Equal(params))
+		})
+
+		It("applies the quotas", func() {
+			Expect(quotas.Config()).To(Equal(params.Quotas))
+		})
+
+		It("logs the parameters in effect", func() {
+			Expect(logger).To(gbytes.Say("reloader.reload.reloaded"))
+			Expect(logger).NotTo(gbytes.Say("restart-required"))
+		})
+
+		Context("when properties that cannot be reloaded changed", func() {
+			BeforeEach(func() {
+				changed = append(changed, "listen_address", "bbs_address")
+			})
+
+			It("logs that they require a restart", func() {
+				Expect(logger).To(gbytes.Say(`reloader.reload.restart-required.*"fields":\["bbs_address","listen_address"\]`))
+			})
+
+			It("still applies the tuning parameters", func() {
+				Expect(communicationTimeout.Get()).To(Equal(20 * time.Second))
+			})
+		})
+
+		Context("when the configuration cannot be loaded", func() {
+			BeforeEach(func() {
+				loadErr = errors.New("invalid configuration: bbs_address: is required")
+			})
+
+			It("logs the error and keeps the current parameters", func() {
+				Expect(reloadErr).To(MatchError(loadErr))
+				Expect(logger).To(gbytes.Say("reloader.reload.failed-to-reload.*bbs_address"))
+				Expect(communicationTimeout.Get()).To(Equal(10 * time.Second))
+				Expect(cellStateTimeout.Get()).To(Equal(time.Second))
+				Expect(quotas.Config()).To(Equal(quota.Config{}))
+				Consistently(factory.builtCount).Should(Equal(1))
+			})
+		})
+	})
+
+	Describe("Run", func() {
+		var process ifrit.Process
+
+		JustBeforeEach(func() {
+			process = ginkgomon.Invoke(reloader)
+		})
+
+		AfterEach(func() {
+			ginkgomon.Interrupt(process)
+		})
+
+		It("reloads when the process receives SIGHUP", func() {
+			Expect(syscall.Kill(syscall.Getpid(), syscall.SIGHUP)).To(Succeed())
+			Eventually(logger).Should(gbytes.Say("reloader.reload.reloaded"))
+			Expect(communicationTimeout.Get()).To(Equal(20 * time.Second))
+		})
+	})
+})
@@ -0,0 +1,71 @@
+package tuning
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Timeout is a request timeout that can be changed while requests are in
+// flight. It takes the place of http.Client.Timeout, which cannot safely be
+// changed once the client is in use.
+type Timeout struct {
+	nanos int64
+}
+
+func NewTimeout(timeout time.Duration) *Timeout {
+	return &Timeout{nanos: int64(timeout)}
+}
+
+func (t *Timeout) Get() time.Duration {
+	return time.Duration(atomic.LoadInt64(&t.nanos))
+}
+
+// Set changes the timeout of requests made from now on.
+func (t *Timeout) Set(timeout time.Duration) {
+	atomic.StoreInt64(&t.nanos, int64(timeout))
+}
+
+// Transport wraps base so that every request, including reading its response
+// body, is bounded by the current timeout. A timeout of zero means no limit.
+func (t *Timeout) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &timeoutTransport{base: base, timeout: t}
+}
+
+type timeoutTransport struct {
+	base    http.RoundTripper
+	timeout *Timeout
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	timeout := t.timeout.Get()
+	if timeout <= 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
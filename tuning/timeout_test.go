@@ -0,0 +1,89 @@
+package tuning_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer/tuning"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Timeout", func() {
+	var (
+		server  *httptest.Server
+		delay   chan time.Duration
+		timeout *tuning.Timeout
+		client  *http.Client
+	)
+
+	BeforeEach(func() {
+		delay = make(chan time.Duration, 1)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case d := <-delay:
+				time.Sleep(d)
+			default:
+			}
+			w.Write([]byte("ok"))
+		}))
+
+		timeout = tuning.NewTimeout(100 * time.Millisecond)
+		client = &http.Client{Transport: timeout.Transport(&http.Transport{})}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("returns the timeout it was created with", func() {
+		Expect(timeout.Get()).To(Equal(100 * time.Millisecond))
+	})
+
+	It("allows requests that finish within the timeout", func() {
+		resp, err := client.Get(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		body, err := ioutil.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("ok"))
+	})
+
+	It("fails requests that take longer than the timeout", func() {
+		delay <- 500 * time.Millisecond
+		_, err := client.Get(server.URL)
+		Expect(err).To(HaveOccurred())
+	})
+
+	Context("when the timeout is changed", func() {
+		BeforeEach(func() {
+			timeout.Set(time.Second)
+		})
+
+		It("applies the new timeout to later requests", func() {
+			Expect(timeout.Get()).To(Equal(time.Second))
+
+			delay <- 500 * time.Millisecond
+			resp, err := client.Get(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			resp.Body.Close()
+		})
+	})
+
+	Context("when the timeout is zero", func() {
+		BeforeEach(func() {
+			timeout.Set(0)
+		})
+
+		It("does not limit requests", func() {
+			delay <- 200 * time.Millisecond
+			resp, err := client.Get(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			resp.Body.Close()
+		})
+	})
+})
@@ -0,0 +1,134 @@
+package auctioneer
+
+import (
+	"reflect"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+)
+
+const (
+	// DefaultWatchWaitTime is how long a consul blocking query waits for the
+	// lock to change before it is reissued.
+	DefaultWatchWaitTime = 10 * time.Second
+
+	// DefaultWatchPollInterval is how often locket is polled for the lock.
+	DefaultWatchPollInterval = time.Second
+
+	// DefaultWatchRetryInterval is how long the watch waits before trying
+	// again when the lock cannot be read or is not held.
+	DefaultWatchRetryInterval = time.Second
+
+	// DefaultWatchDebounce is how long a new presence must be seen before it
+	// is sent, so that a lock changing hands several times in quick succession
+	// results in a single change.
+	DefaultWatchDebounce = 500 * time.Millisecond
+)
+
+// fetchPresence reads the current presence. It is expected to block until
+// the presence may have changed when pollInterval is zero.
+type fetchPresence func(logger lager.Logger) (Presence, error)
+
+type presenceWatcher struct {
+	clock         clock.Clock
+	fetch         fetchPresence
+	pollInterval  time.Duration
+	retryInterval time.Duration
+	debounce      time.Duration
+}
+
+// watch returns a channel that receives the presence every time a different
+// auctioneer, or the same auctioneer with different metadata, holds the lock.
+// Failures to read the lock are logged and retried. The channel is closed
+// once stop is closed.
+func (w presenceWatcher) watch(logger lager.Logger, stop <-chan struct{}) <-chan Presence {
+	observations := make(chan Presence)
+	presences := make(chan Presence)
+
+	go w.observe(logger, observations, stop)
+	go w.emit(observations, presences, stop)
+
+	return presences
+}
+
+func (w presenceWatcher) observe(logger lager.Logger, observations chan<- Presence, stop <-chan struct{}) {
+	for {
+		wait := w.pollInterval
+
+		presence, err := w.fetch(logger)
+		if err != nil {
+			logger.Debug("failed-to-fetch-presence", lager.Data{"error": err.Error()})
+			wait = w.retryInterval
+		} else {
+			select {
+			case observations <- presence:
+			case <-stop:
+				return
+			}
+		}
+
+		if wait == 0 {
+			select {
+			case <-stop:
+				return
+			default:
+				continue
+			}
+		}
+
+		timer := w.clock.NewTimer(wait)
+		select {
+		case <-timer.C():
+		case <-stop:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+func (w presenceWatcher) emit(observations <-chan Presence, presences chan<- Presence, stop <-chan struct{}) {
+	defer close(presences)
+
+	var current, pending Presence
+	var timer clock.Timer
+	var debounced <-chan time.Time
+
+	for {
+		select {
+		case presence := <-observations:
+			if reflect.DeepEqual(presence, pending) && debounced != nil {
+				continue
+			}
+
+			if timer != nil {
+				timer.Stop()
+				timer, debounced = nil, nil
+			}
+
+			if reflect.DeepEqual(presence, current) {
+				continue
+			}
+
+			pending = presence
+			timer = w.clock.NewTimer(w.debounce)
+			debounced = timer.C()
+
+		case <-debounced:
+			timer, debounced = nil, nil
+			current = pending
+
+			select {
+			case presences <- current:
+			case <-stop:
+				return
+			}
+
+		case <-stop:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package worktracker
+
+import (
+	"os"
+
+	"code.cloudfoundry.org/auctioneer/metrics"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+)
+
+// QueueDepth is the number of submitted work items waiting for an auction
+// result.
+const QueueDepth = "AuctioneerQueueDepth"
+
+// DepthNotifier periodically emits the number of work items the tracker holds.
+type DepthNotifier struct {
+	logger  lager.Logger
+	ticker  clock.Ticker
+	tracker *Tracker
+	sink    metrics.Sink
+}
+
+func NewDepthNotifier(logger lager.Logger, ticker clock.Ticker, tracker *Tracker, sink metrics.Sink) *DepthNotifier {
+	return &DepthNotifier{
+		logger:  logger.Session("queue-depth-notifier"),
+		ticker:  ticker,
+		tracker: tracker,
+		sink:    sink,
+	}
+}
+
+func (n *DepthNotifier) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	close(ready)
+
+	for {
+		select {
+		case <-n.ticker.C():
+			err := n.sink.SendGauge(QueueDepth, float64(n.tracker.Pending()), metrics.MetricUnit, nil)
+			if err != nil {
+				n.logger.Error("failed-to-send-queue-depth", err)
+			}
+
+		case <-signals:
+			n.ticker.Stop()
+			return nil
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package worktracker_test
+
+import (
+	"context"
+	"time"
+
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/auctioneer/metrics/metricsfakes"
+	"code.cloudfoundry.org/auctioneer/worktracker"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DepthNotifier", func() {
+	var (
+		fakeClock *fakeclock.FakeClock
+		fakeSink  *metricsfakes.FakeSink
+		tracker   *worktracker.Tracker
+		process   ifrit.Process
+	)
+
+	BeforeEach(func() {
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		fakeSink = new(metricsfakes.FakeSink)
+		logger := lagertest.NewTestLogger("test")
+		tracker = worktracker.New(logger, fakeClock, fakeSink, time.Hour)
+
+		notifier := worktracker.NewDepthNotifier(logger, fakeClock.NewTicker(time.Minute), tracker, fakeSink)
+		process = ginkgomon.Invoke(notifier)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+	})
+
+	It("emits the number of pending work items on every tick", func() {
+		resource := rep.NewResource(10, 10, 10)
+		pc := rep.NewPlacementConstraint("preloaded:linux", nil, nil)
+		tracker.LRPsSubmitted(context.Background(), []auctioneer.LRPStartRequest{
+			auctioneer.NewLRPStartRequest("process-guid", "domain", []int{0, 1, 2}, resource, pc),
+		})
+
+		fakeClock.WaitForWatcherAndIncrement(time.Minute)
+
+		Eventually(fakeSink.SendGaugeCallCount).Should(Equal(1))
+		name, value, _, _ := fakeSink.SendGaugeArgsForCall(0)
+		Expect(name).To(Equal(worktracker.QueueDepth))
+		Expect(value).To(Equal(3.0))
+	})
+})
@@ -0,0 +1 @@
+package worktracker // import "code.cloudfoundry.org/auctioneer/worktracker"
@@ -0,0 +1,254 @@
+package worktracker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/auctioneer/auctionrunnerdelegate"
+	"code.cloudfoundry.org/auctioneer/metrics"
+	"code.cloudfoundry.org/auctioneer/pendingwork"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	TaskPlacementDuration        = "AuctioneerTaskPlacementDuration"
+	TaskPlacementFailureDuration = "AuctioneerTaskPlacementFailureDuration"
+	LRPPlacementDuration         = "AuctioneerLRPPlacementDuration"
+	LRPPlacementFailureDuration  = "AuctioneerLRPPlacementFailureDuration"
+
+	// DefaultMaxAge is how long a submitted work item is tracked without an
+	// auction result before it is forgotten.
+	DefaultMaxAge = time.Hour
+)
+
+// Tracker records when each task and LRP instance is submitted to the
+// auctioneer and, once an auction completes, emits the time from submission
+// to placement or to failure.
+type Tracker struct {
+	logger lager.Logger
+	clock  clock.Clock
+	sink   metrics.Sink
+	maxAge time.Duration
+
+	lock        sync.Mutex
+	submissions map[string]submission
+}
+
+// submission holds the request for a single work item, so that the pending
+// work can be checkpointed. Exactly one of task and lrp is set, and lrp holds
+// only the one instance index.
+type submission struct {
+	submittedAt time.Time
+	spanContext trace.SpanContext
+	task        *auctioneer.TaskStartRequest
+	lrp         *auctioneer.LRPStartRequest
+}
+
+func New(logger lager.Logger, clock clock.Clock, sink metrics.Sink, maxAge time.Duration) *Tracker {
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+
+	return &Tracker{
+		logger:      logger.Session("work-tracker"),
+		clock:       clock,
+		sink:        sink,
+		maxAge:      maxAge,
+		submissions: map[string]submission{},
+	}
+}
+
+// TasksSubmitted records the arrival of the tasks and the trace context of the
+// request that submitted them. A task that is already being tracked keeps its
+// original submission.
+func (t *Tracker) TasksSubmitted(ctx context.Context, tasks []auctioneer.TaskStartRequest) {
+	submittedAt := t.clock.Now()
+	spanContext := trace.SpanContextFromContext(ctx)
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for i := range tasks {
+		task := tasks[i]
+		t.submit(task.TaskGuid, submission{submittedAt: submittedAt, spanContext: spanContext, task: &task})
+	}
+}
+
+// LRPsSubmitted records the arrival of every requested LRP instance.
+func (t *Tracker) LRPsSubmitted(ctx context.Context, lrps []auctioneer.LRPStartRequest) {
+	submittedAt := t.clock.Now()
+	spanContext := trace.SpanContextFromContext(ctx)
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for i := range lrps {
+		for _, index := range lrps[i].Indices {
+			lrp := lrps[i]
+			lrp.Indices = []int{index}
+			t.submit(LRPIdentifier(lrp.ProcessGuid, index), submission{submittedAt: submittedAt, spanContext: spanContext, lrp: &lrp})
+		}
+	}
+}
+
+// SpanContext returns the trace context the work item was submitted with, so
+// that the work done to place it can be attached to the same trace. The
+// returned span context is invalid for untracked work.
+func (t *Tracker) SpanContext(identifier string) trace.SpanContext {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.submissions[identifier].spanContext
+}
+
+// Pending returns the number of submitted work items that have not yet been
+// through an auction.
+func (t *Tracker) Pending() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return len(t.submissions)
+}
+
+// Snapshot returns the requests for the pending work, oldest first.
+func (t *Tracker) Snapshot() pendingwork.Snapshot {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	snapshot := pendingwork.Snapshot{}
+	for _, s := range t.submissions {
+		switch {
+		case s.task != nil:
+			snapshot.Tasks = append(snapshot.Tasks, pendingwork.Task{Request: *s.task, SubmittedAt: s.submittedAt})
+		case s.lrp != nil:
+			snapshot.LRPs = append(snapshot.LRPs, pendingwork.LRP{Request: *s.lrp, SubmittedAt: s.submittedAt})
+		}
+	}
+
+	sort.SliceStable(snapshot.Tasks, func(i, j int) bool {
+		return snapshot.Tasks[i].SubmittedAt.Before(snapshot.Tasks[j].SubmittedAt)
+	})
+	sort.SliceStable(snapshot.LRPs, func(i, j int) bool {
+		return snapshot.LRPs[i].SubmittedAt.Before(snapshot.LRPs[j].SubmittedAt)
+	})
+
+	return snapshot
+}
+
+// Restore tracks work restored from a snapshot with its original submission
+// time. It returns the work that was not already being tracked, one entry per
+// LRP instance.
+func (t *Tracker) Restore(snapshot pendingwork.Snapshot) pendingwork.Snapshot {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	restored := pendingwork.Snapshot{SavedAt: snapshot.SavedAt}
+
+	for _, task := range snapshot.Tasks {
+		request := task.Request
+		if t.submit(request.TaskGuid, submission{submittedAt: task.SubmittedAt, task: &request}) {
+			restored.Tasks = append(restored.Tasks, task)
+		}
+	}
+
+	for _, lrp := range snapshot.LRPs {
+		for _, index := range lrp.Request.Indices {
+			request := lrp.Request
+			request.Indices = []int{index}
+			if t.submit(LRPIdentifier(request.ProcessGuid, index), submission{submittedAt: lrp.SubmittedAt, lrp: &request}) {
+				restored.LRPs = append(restored.LRPs, pendingwork.LRP{Request: request, SubmittedAt: lrp.SubmittedAt})
+			}
+		}
+	}
+
+	return restored
+}
+
+func (t *Tracker) AuctionCompleted(auction auctionrunnerdelegate.CompletedAuction) {
+	results := auction.Results
+	completedAt := auction.CompletedAt
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for i := range results.SuccessfulTasks {
+		t.complete(results.SuccessfulTasks[i].TaskGuid, TaskPlacementDuration, completedAt)
+	}
+	for i := range results.FailedTasks {
+		t.complete(results.FailedTasks[i].TaskGuid, TaskPlacementFailureDuration, completedAt)
+	}
+	for i := range results.SuccessfulLRPs {
+		t.complete(results.SuccessfulLRPs[i].Identifier(), LRPPlacementDuration, completedAt)
+	}
+	for i := range results.FailedLRPs {
+		t.complete(results.FailedLRPs[i].Identifier(), LRPPlacementFailureDuration, completedAt)
+	}
+
+	t.expire(completedAt)
+}
+
+// WorkRefused stops tracking work that was refused before it reached an
+// auction and emits the time from its submission to the refusal.
+func (t *Tracker) WorkRefused(results auctiontypes.AuctionResults) {
+	refusedAt := t.clock.Now()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for i := range results.FailedTasks {
+		t.complete(results.FailedTasks[i].TaskGuid, TaskPlacementFailureDuration, refusedAt)
+	}
+	for i := range results.FailedLRPs {
+		t.complete(results.FailedLRPs[i].Identifier(), LRPPlacementFailureDuration, refusedAt)
+	}
+}
+
+// submit tracks the work item unless it is already tracked, and reports
+// whether it was added.
+func (t *Tracker) submit(identifier string, s submission) bool {
+	if _, ok := t.submissions[identifier]; ok {
+		return false
+	}
+	t.submissions[identifier] = s
+	return true
+}
+
+func (t *Tracker) complete(identifier, metric string, completedAt time.Time) {
+	s, ok := t.submissions[identifier]
+	if !ok {
+		return
+	}
+	delete(t.submissions, identifier)
+
+	err := t.sink.SendDuration(metric, completedAt.Sub(s.submittedAt), nil)
+	if err != nil {
+		t.logger.Error("failed-to-send-duration", err, lager.Data{"metric": metric})
+	}
+}
+
+func (t *Tracker) expire(now time.Time) {
+	expired := 0
+	for identifier, s := range t.submissions {
+		if now.Sub(s.submittedAt) > t.maxAge {
+			delete(t.submissions, identifier)
+			expired++
+		}
+	}
+
+	if expired > 0 {
+		t.logger.Info("expired-work", lager.Data{"count": expired, "max-age": t.maxAge.String()})
+	}
+}
+
+// LRPIdentifier returns the identifier of an LRP instance, matching
+// rep.LRP.Identifier.
+func LRPIdentifier(processGuid string, index int) string {
+	return fmt.Sprintf("%s.%d", processGuid, index)
+}
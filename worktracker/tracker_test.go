@@ -0,0 +1,216 @@
+package worktracker_test
+
+import (
+	"context"
+	"time"
+
+	"code.cloudfoundry.org/auction/auctiontypes"
+	"code.cloudfoundry.org/auctioneer"
+	"code.cloudfoundry.org/auctioneer/auctionrunnerdelegate"
+	"code.cloudfoundry.org/auctioneer/metrics/metricsfakes"
+	"code.cloudfoundry.org/auctioneer/pendingwork"
+	"code.cloudfoundry.org/auctioneer/worktracker"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/lagertest"
+	"code.cloudfoundry.org/rep"
+	"go.opentelemetry.io/otel/trace"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tracker", func() {
+	var (
+		fakeClock *fakeclock.FakeClock
+		fakeSink  *metricsfakes.FakeSink
+		tracker   *worktracker.Tracker
+		resource  rep.Resource
+		pc        rep.PlacementConstraint
+	)
+
+	BeforeEach(func() {
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		fakeSink = new(metricsfakes.FakeSink)
+		tracker = worktracker.New(lagertest.NewTestLogger("test"), fakeClock, fakeSink, time.Minute)
+		resource = rep.NewResource(10, 10, 10)
+		pc = rep.NewPlacementConstraint("preloaded:linux", nil, nil)
+	})
+
+	sentDurations := func() map[string]time.Duration {
+		durations := map[string]time.Duration{}
+		for i := 0; i < fakeSink.SendDurationCallCount(); i++ {
+			name, value, _ := fakeSink.SendDurationArgsForCall(i)
+			durations[name] = value
+		}
+		return durations
+	}
+
+	It("emits the time from submission to placement or failure", func() {
+		tracker.TasksSubmitted(context.Background(), []auctioneer.TaskStartRequest{
+			auctioneer.NewTaskStartRequest(rep.NewTask("placed-task", "domain", resource, pc)),
+			auctioneer.NewTaskStartRequest(rep.NewTask("failed-task", "domain", resource, pc)),
+		})
+		fakeClock.Increment(time.Second)
+		tracker.LRPsSubmitted(context.Background(), []auctioneer.LRPStartRequest{
+			auctioneer.NewLRPStartRequest("process-guid", "domain", []int{0, 1}, resource, pc),
+		})
+		Expect(tracker.Pending()).To(Equal(4))
+
+		fakeClock.Increment(2 * time.Second)
+		tracker.AuctionCompleted(auctionrunnerdelegate.CompletedAuction{
+			CompletedAt: fakeClock.Now(),
+			Results: auctiontypes.AuctionResults{
+				SuccessfulTasks: []auctiontypes.TaskAuction{{Task: rep.NewTask("placed-task", "domain", resource, pc)}},
+				FailedTasks:     []auctiontypes.TaskAuction{{Task: rep.NewTask("failed-task", "domain", resource, pc)}},
+				SuccessfulLRPs:  []auctiontypes.LRPAuction{{LRP: rep.NewLRP("", models.NewActualLRPKey("process-guid", 0, "domain"), resource, pc)}},
+				FailedLRPs:      []auctiontypes.LRPAuction{{LRP: rep.NewLRP("", models.NewActualLRPKey("process-guid", 1, "domain"), resource, pc)}},
+			},
+		})
+
+		Expect(sentDurations()).To(Equal(map[string]time.Duration{
+			"AuctioneerTaskPlacementDuration":        3 * time.Second,
+			"AuctioneerTaskPlacementFailureDuration": 3 * time.Second,
+			"AuctioneerLRPPlacementDuration":         2 * time.Second,
+			"AuctioneerLRPPlacementFailureDuration":  2 * time.Second,
+		}))
+		Expect(tracker.Pending()).To(Equal(0))
+	})
+
+	It("stops tracking refused work and emits the time to the refusal", func() {
+		tracker.TasksSubmitted(context.Background(), []auctioneer.TaskStartRequest{
+			auctioneer.NewTaskStartRequest(rep.NewTask("refused-task", "domain", resource, pc)),
+		})
+		tracker.LRPsSubmitted(context.Background(), []auctioneer.LRPStartRequest{
+			auctioneer.NewLRPStartRequest("process-guid", "domain", []int{0, 1}, resource, pc),
+		})
+
+		fakeClock.Increment(time.Second)
+		tracker.WorkRefused(auctiontypes.AuctionResults{
+			FailedTasks: []auctiontypes.TaskAuction{{Task: rep.NewTask("refused-task", "domain", resource, pc)}},
+			FailedLRPs:  []auctiontypes.LRPAuction{{LRP: rep.NewLRP("", models.NewActualLRPKey("process-guid", 1, "domain"), resource, pc)}},
+		})
+
+		Expect(sentDurations()).To(Equal(map[string]time.Duration{
+			"AuctioneerTaskPlacementFailureDuration": time.Second,
+			"AuctioneerLRPPlacementFailureDuration":  time.Second,
+		}))
+		Expect(tracker.Pending()).To(Equal(1))
+	})
+
+	It("keeps the original submission time when work is resubmitted", func() {
+		task := auctioneer.NewTaskStartRequest(rep.NewTask("task-guid", "domain", resource, pc))
+		tracker.TasksSubmitted(context.Background(), []auctioneer.TaskStartRequest{task})
+		fakeClock.Increment(time.Second)
+		tracker.TasksSubmitted(context.Background(), []auctioneer.TaskStartRequest{task})
+		fakeClock.Increment(time.Second)
+
+		tracker.AuctionCompleted(auctionrunnerdelegate.CompletedAuction{
+			CompletedAt: fakeClock.Now(),
+			Results: auctiontypes.AuctionResults{
+				SuccessfulTasks: []auctiontypes.TaskAuction{{Task: task.Task}},
+			},
+		})
+
+		Expect(sentDurations()).To(HaveKeyWithValue("AuctioneerTaskPlacementDuration", 2*time.Second))
+	})
+
+	It("remembers the trace context work was submitted with", func() {
+		traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+		Expect(err).NotTo(HaveOccurred())
+		spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+		Expect(err).NotTo(HaveOccurred())
+		spanContext := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID})
+		ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+
+		tracker.LRPsSubmitted(ctx, []auctioneer.LRPStartRequest{
+			auctioneer.NewLRPStartRequest("process-guid", "domain", []int{1}, resource, pc),
+		})
+
+		Expect(tracker.SpanContext("process-guid.1")).To(Equal(spanContext))
+		Expect(tracker.SpanContext("process-guid.0").IsValid()).To(BeFalse())
+	})
+
+	It("ignores results for work it did not see submitted", func() {
+		tracker.AuctionCompleted(auctionrunnerdelegate.CompletedAuction{
+			CompletedAt: fakeClock.Now(),
+			Results: auctiontypes.AuctionResults{
+				SuccessfulTasks: []auctiontypes.TaskAuction{{Task: rep.NewTask("unknown", "domain", resource, pc)}},
+			},
+		})
+
+		Expect(fakeSink.SendDurationCallCount()).To(Equal(0))
+	})
+
+	It("forgets work that has been pending for longer than the max age", func() {
+		tracker.TasksSubmitted(context.Background(), []auctioneer.TaskStartRequest{
+			auctioneer.NewTaskStartRequest(rep.NewTask("lost-task", "domain", resource, pc)),
+		})
+		fakeClock.Increment(2 * time.Minute)
+
+		tracker.AuctionCompleted(auctionrunnerdelegate.CompletedAuction{CompletedAt: fakeClock.Now()})
+
+		Expect(tracker.Pending()).To(Equal(0))
+	})
+
+	Describe("Snapshot", func() {
+		It("returns the pending requests with one entry per LRP instance", func() {
+			task := auctioneer.NewTaskStartRequest(rep.NewTask("task-guid", "domain", resource, pc))
+			tracker.TasksSubmitted(context.Background(), []auctioneer.TaskStartRequest{task})
+			submittedAt := fakeClock.Now()
+			fakeClock.Increment(time.Second)
+			tracker.LRPsSubmitted(context.Background(), []auctioneer.LRPStartRequest{
+				auctioneer.NewLRPStartRequest("process-guid", "domain", []int{0, 1}, resource, pc),
+			})
+
+			snapshot := tracker.Snapshot()
+			Expect(snapshot.Tasks).To(Equal([]pendingwork.Task{{Request: task, SubmittedAt: submittedAt}}))
+			Expect(snapshot.LRPs).To(ConsistOf(
+				pendingwork.LRP{
+					Request:     auctioneer.NewLRPStartRequest("process-guid", "domain", []int{0}, resource, pc),
+					SubmittedAt: fakeClock.Now(),
+				},
+				pendingwork.LRP{
+					Request:     auctioneer.NewLRPStartRequest("process-guid", "domain", []int{1}, resource, pc),
+					SubmittedAt: fakeClock.Now(),
+				},
+			))
+		})
+	})
+
+	Describe("Restore", func() {
+		It("tracks the work it was not already tracking and returns it", func() {
+			tracker.TasksSubmitted(context.Background(), []auctioneer.TaskStartRequest{
+				auctioneer.NewTaskStartRequest(rep.NewTask("tracked-task", "domain", resource, pc)),
+			})
+
+			savedAt := fakeClock.Now().Add(-time.Second)
+			restoredTask := pendingwork.Task{
+				Request:     auctioneer.NewTaskStartRequest(rep.NewTask("restored-task", "domain", resource, pc)),
+				SubmittedAt: savedAt,
+			}
+			restored := tracker.Restore(pendingwork.Snapshot{
+				Tasks: []pendingwork.Task{
+					{Request: auctioneer.NewTaskStartRequest(rep.NewTask("tracked-task", "domain", resource, pc)), SubmittedAt: savedAt},
+					restoredTask,
+				},
+				LRPs: []pendingwork.LRP{{
+					Request:     auctioneer.NewLRPStartRequest("process-guid", "domain", []int{0, 1}, resource, pc),
+					SubmittedAt: savedAt,
+				}},
+			})
+
+			Expect(restored.Tasks).To(Equal([]pendingwork.Task{restoredTask}))
+			Expect(restored.LRPs).To(HaveLen(2))
+			Expect(tracker.Pending()).To(Equal(4))
+
+			tracker.AuctionCompleted(auctionrunnerdelegate.CompletedAuction{
+				CompletedAt: fakeClock.Now(),
+				Results: auctiontypes.AuctionResults{
+					SuccessfulTasks: []auctiontypes.TaskAuction{{Task: restoredTask.Request.Task}},
+				},
+			})
+			Expect(sentDurations()).To(HaveKeyWithValue("AuctioneerTaskPlacementDuration", time.Second))
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package worktracker_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestWorktracker(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Work Tracker Suite")
+}